@@ -18,28 +18,550 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
 	ModelFinalizer = "sivchari.io/model"
 )
 
+const (
+	// ModelNameLabel is the label set on the resources created for a Model. The value is the name of the Model.
+	ModelNameLabel = "ollama.sivchari.io/model"
+
+	// PodTemplateHashLabel is the label which identifies the revision of the pod created for a Model.
+	PodTemplateHashLabel = "ollama.sivchari.io/pod-template-hash"
+
+	// PullStateLabel is the label set on the pods pulling images while the pull scheduler of the operator is enabled.
+	// The pods wait for their pulls until the pull scheduler changes it from Queued to Permitted.
+	PullStateLabel = "ollama.sivchari.io/pull-state"
+)
+
+const (
+	// PullStateQueued indicates that the pod waits for the pull scheduler to permit its pulls.
+	PullStateQueued = "Queued"
+
+	// PullStatePermitted indicates that the pull scheduler has permitted the pulls of the pod.
+	PullStatePermitted = "Permitted"
+)
+
+const (
+	// LastRequestTimeAnnotation is the annotation set on a Model by the activator when it receives a request
+	// for the Model. The value is formatted in RFC 3339. It wakes up the Model which is scaled to zero.
+	LastRequestTimeAnnotation = "ollama.sivchari.io/last-request-time"
+)
+
 // ModelSpec defines the desired state of Model.
 type ModelSpec struct {
 	// images is a list of images to be used for the ollama. At least one image is required.
+	// Each part of the names consists of the letters, digits, '.', '_' and '-', starts with a letter or a digit,
+	// and does not contain "..".
 	// +required
 	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:Pattern=`^([A-Za-z0-9](\.?[A-Za-z0-9_-])*\.?(:[0-9]+)?/)?([A-Za-z0-9](\.?[A-Za-z0-9_-])*\.?/)*[A-Za-z0-9](\.?[A-Za-z0-9_-])*\.?(:[A-Za-z0-9](\.?[A-Za-z0-9_-])*\.?)?$`
 	Images []string `json:"images,omitempty"`
 
+	// replicas is the number of the serving pods. Defaults to 1.
+	// If autoscaling is set, it is managed by the autoscaler of the operator.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+
 	// paused indicates whether the Model will be provisioned or not.
-	// If paused is true, the ollama will not be provisioned.
+	// If paused is true, the ollama will not be provisioned and the serving pods are deleted,
+	// while the storage and the status of the Model are kept. When the Model is resumed,
+	// the images which are already cached in the storage are not pulled again.
 	// +optional
 	Paused *bool `json:"paused,omitempty"`
 
+	// className is the name of the ModelClass which holds the defaults of the Model.
+	// If it is not set, the ModelClass annotated as the default class is used, if any.
+	// +optional
+	ClassName *string `json:"className,omitempty"`
+
 	// template is the template used to create the ollama server.
 	// +optional
 	Template *ModelTemplate `json:"template"`
+
+	// runtime is the ollama server runtime used by the Model.
+	// If it is not set, the runtime configured for the operator is used.
+	// +optional
+	Runtime *ModelRuntime `json:"runtime,omitempty"`
+
+	// registry configures the registries the images are pulled from.
+	// It is merged with the registry configured for the operator, and the hosts set by the Model take precedence.
+	// If any registry is configured, the images are pulled by the agent in an init container instead of the ollama server.
+	// +optional
+	Registry *ModelRegistry `json:"registry,omitempty"`
+
+	// sources are the sources the images are imported from instead of the registries, e.g. in a cluster without
+	// the access to any registry. A registry in the cluster can also stand in for the public one with spec.registry.
+	// The images are imported or fetched by the agent in the init containers, and the sources of the images
+	// which are not in spec.images are ignored.
+	// +optional
+	// +listType=map
+	// +listMapKey=image
+	Sources []ModelSource `json:"sources,omitempty"`
+
+	// backup backs up the pulled images to an S3-compatible object storage, and restores them from it before
+	// they are pulled from the registries, e.g. when the storage or the node holding them is lost.
+	// If backup is set, the images are pulled by the agent in an init container. The failures of the backup
+	// do not fail the pulls, and are reported in the BackedUp condition. The images created from the weights
+	// are not backed up. The objects larger than 64 MiB are uploaded in parts.
+	// +optional
+	Backup *ModelBackup `json:"backup,omitempty"`
+
+	// pullPolicy is the policy of the retries and the timeouts of the pulls of spec.images.
+	// If it is set, the images are pulled by the agent in an init container, which reports the attempts
+	// and the last errors of the pulls in status.pulls. If it is not set, each image is pulled once without a timeout.
+	// +optional
+	PullPolicy *ModelPullPolicy `json:"pullPolicy,omitempty"`
+
+	// peerSeeding, if true, copies the blobs of the images to a new serving pod from the ready pods of the Model,
+	// e.g. during a rollout or a scale up, and pulls only the blobs they do not have from the registries.
+	// Each pod serves its blobs to the other pods of the Model, and the images are pulled by the agent in an init container.
+	// The copied blobs are verified against their digests. It is ignored if the Model reads the models from a ModelCache.
+	// +optional
+	PeerSeeding *bool `json:"peerSeeding,omitempty"`
+
+	// idle is the policy to scale the serving pods to zero while the Model is idle.
+	// The Model is woken up by the activator when it receives a request on the Model's Service address.
+	// The clients have to address the Service by <name>.<namespace>.svc, optionally followed by the cluster domain,
+	// since the activator resolves the Model from the Host header of the requests.
+	// +optional
+	Idle *ModelIdlePolicy `json:"idle,omitempty"`
+
+	// autoscaling is the policy to adjust replicas from the inference load.
+	// The load is measured by a proxy which is injected in front of the ollama server.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas must be less than or equal to maxReplicas"
+	Autoscaling *ModelAutoscaling `json:"autoscaling,omitempty"`
+
+	// disruption is the policy to limit the voluntary disruptions of the serving pods, e.g. node drains.
+	// The operator manages a PodDisruptionBudget which has the same name as the Model.
+	// If it is not set, at most one serving pod is disrupted at once when the Model has multiple replicas,
+	// and a single replica is not protected so that it does not block the node drains.
+	// +optional
+	Disruption *ModelDisruptionPolicy `json:"disruption,omitempty"`
+
+	// network is the policy to restrict the traffic of the serving pods.
+	// If it is set, the operator manages a NetworkPolicy which has the same name as the Model.
+	// The traffic from the controller manager of the operator is always allowed.
+	// +optional
+	Network *ModelNetwork `json:"network,omitempty"`
+
+	// expose is the policy to expose the Model outside the cluster.
+	// If it is set, the operator manages an Ingress or a Gateway API HTTPRoute which has the same name as the Model
+	// and routes the requests to the Model's Service.
+	// +optional
+	Expose *ModelExpose `json:"expose,omitempty"`
+
+	// auth is the policy to authenticate the requests to the Model.
+	// If it is set, a proxy which validates the API keys or the JWTs is injected in front of the ollama server,
+	// and only the admin callers can use the management endpoints, e.g. pull, create and delete.
+	// +optional
+	Auth *ModelAuth `json:"auth,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="has(self.apiKeys) || has(self.jwt)",message="either apiKeys or jwt must be set"
+type ModelAuth struct {
+	// apiKeys are the Secrets holding the API keys in the namespace of the Model. Each value of a Secret is an API key.
+	// The keys are sent as the bearer token in the Authorization header.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	APIKeys []ModelAPIKeySecret `json:"apiKeys,omitempty"`
+
+	// jwt authenticates the JWTs issued by an OpenID Connect issuer, which are sent as the bearer token.
+	// +optional
+	JWT *ModelJWT `json:"jwt,omitempty"`
+}
+
+type ModelAPIKeySecret struct {
+	// name is the name of the Secret.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// admin grants the management endpoints to the API keys of the Secret.
+	// +optional
+	Admin bool `json:"admin,omitempty"`
+}
+
+type ModelJWT struct {
+	// issuer is the URL of the OpenID Connect issuer. The signing keys are discovered from it.
+	// +required
+	// +kubebuilder:validation:Pattern=`^https://`
+	Issuer string `json:"issuer"`
+
+	// audiences are the accepted audiences of the JWTs. A JWT is accepted only if one of its audiences is listed,
+	// so the JWTs the issuer signs for the other services are rejected.
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:MinLength=1
+	Audiences []string `json:"audiences"`
+
+	// adminClaim is the claim which grants the management endpoints to the caller.
+	// +optional
+	AdminClaim *ModelJWTClaim `json:"adminClaim,omitempty"`
+}
+
+type ModelJWTClaim struct {
+	// name is the name of the claim, e.g. groups.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// value is the value the claim has to be, or contain if the claim is a list.
+	// +required
+	Value string `json:"value"`
+}
+
+// +kubebuilder:validation:XValidation:rule="!(has(self.ingress) && has(self.httpRoute))",message="ingress and httpRoute are mutually exclusive"
+type ModelExpose struct {
+	// host is the host name the Model is exposed on.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+
+	// path is the path prefix the Model is exposed on. Defaults to "/".
+	// +optional
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path,omitempty"`
+
+	// tls enables HTTPS for the exposed Model.
+	// +optional
+	TLS *ModelExposeTLS `json:"tls,omitempty"`
+
+	// ingress exposes the Model with an Ingress. It is the default if httpRoute is not set.
+	// +optional
+	Ingress *ModelExposeIngress `json:"ingress,omitempty"`
+
+	// httpRoute exposes the Model with a Gateway API HTTPRoute.
+	// +optional
+	HTTPRoute *ModelExposeHTTPRoute `json:"httpRoute,omitempty"`
+}
+
+type ModelExposeTLS struct {
+	// secretName is the name of the Secret which holds the TLS certificate of the host.
+	// It is used by the Ingress. The HTTPRoute relies on the TLS configured on the parent Gateways.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+type ModelExposeIngress struct {
+	// className is the name of the IngressClass.
+	// +optional
+	ClassName *string `json:"className,omitempty"`
+
+	// annotations are set on the Ingress, e.g. to configure the timeouts of the streamed responses.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ModelExposeHTTPRoute struct {
+	// parentRefs are the Gateways the HTTPRoute is attached to.
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	ParentRefs []ModelGatewayReference `json:"parentRefs"`
+}
+
+type ModelGatewayReference struct {
+	// name is the name of the Gateway.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// namespace is the namespace of the Gateway. Defaults to the namespace of the Model.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// sectionName is the name of the listener of the Gateway.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+type ModelNetwork struct {
+	// ingress is the list of the clients allowed to send requests to the serving pods.
+	// If it is empty, only the controller manager of the operator can reach the serving pods.
+	// The activator and the gateway of the operator forward the requests of any client, so they have to be listed
+	// here to serve the Model through them.
+	// +optional
+	Ingress []networkingv1.NetworkPolicyPeer `json:"ingress,omitempty"`
+
+	// egress is the list of the destinations the serving pods can reach, e.g. the model registry.
+	// The DNS traffic is always allowed. If it is empty, the serving pods can reach any address on port 443
+	// to pull the images from the registry.
+	// +optional
+	Egress []networkingv1.NetworkPolicyEgressRule `json:"egress,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="!(has(self.minAvailable) && has(self.maxUnavailable))",message="minAvailable and maxUnavailable are mutually exclusive"
+type ModelDisruptionPolicy struct {
+	// minAvailable is the number or the percentage of the serving pods which must be available during the disruptions.
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// maxUnavailable is the number or the percentage of the serving pods which can be unavailable during the disruptions.
+	// +optional
+	// +kubebuilder:validation:XIntOrString
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+type ModelAutoscaling struct {
+	// minReplicas is the lower bound of the replicas. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// maxReplicas is the upper bound of the replicas.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// targetRequestsPerReplica is the average number of in-flight and queued requests per replica
+	// which the autoscaler maintains.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	TargetRequestsPerReplica int32 `json:"targetRequestsPerReplica"`
+
+	// concurrency is the maximum number of requests processed by a replica at once.
+	// Additional requests are queued by the proxy in front of the ollama server and reported as the queue depth.
+	// If it is not set, the requests are not queued by the proxy.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Concurrency *int32 `json:"concurrency,omitempty"`
+
+	// scaleDownDelay is the duration the load has to stay low before the replicas are decreased. Defaults to 5m.
+	// +optional
+	ScaleDownDelay *metav1.Duration `json:"scaleDownDelay,omitempty"`
+}
+
+type ModelIdlePolicy struct {
+	// timeout is the duration without requests after which the serving pods are scaled to zero.
+	// The Model is considered to be active while the ollama server has a model loaded in memory,
+	// so the keep alive duration of the ollama server (5m by default) is added to the timeout.
+	// +required
+	Timeout metav1.Duration `json:"timeout"`
+}
+
+type ModelRuntime struct {
+	// image is the container image of the ollama server.
+	// If it is empty, the image configured for the operator is used.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// imagePullPolicy is the pull policy of the ollama server image.
+	// +optional
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// version is a semantic version range which the running ollama server must satisfy, e.g. ">=0.6.0 <0.7.0".
+	// A pod which does not satisfy the range is never promoted during a rollout.
+	// +optional
+	Version string `json:"version,omitempty"`
+}
+
+type ModelRegistry struct {
+	// mirrors rewrite the registry hosts of the images to the hosts they are pulled from.
+	// The pulled models keep the names in spec.images.
+	// +optional
+	// +listType=map
+	// +listMapKey=host
+	Mirrors []RegistryMirror `json:"mirrors,omitempty"`
+
+	// hosts configure the access to the registry hosts the images are pulled from, after the mirrors are applied.
+	// +optional
+	// +listType=map
+	// +listMapKey=host
+	Hosts []RegistryHost `json:"hosts,omitempty"`
+}
+
+type RegistryMirror struct {
+	// host is the registry host in the images, e.g. "registry.ollama.ai" for "llama3".
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+
+	// mirror is the host the images of host are pulled from, e.g. "mirror.example.com:5000".
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Mirror string `json:"mirror"`
+}
+
+type RegistryHost struct {
+	// host is the registry host, e.g. "mirror.example.com:5000".
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+
+	// authSecretName is the name of the Secret in the namespace of the Model holding the credentials of the registry,
+	// either the keys username and password, or the key token of a bearer token.
+	// The basic credentials are also exchanged for a bearer token on the challenge of the registry.
+	// +optional
+	AuthSecretName *string `json:"authSecretName,omitempty"`
+
+	// insecure, if true, accesses the registry with plain HTTP and without the verification of the TLS certificate.
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="has(self.oci) != has(self.weights)",message="exactly one of oci or weights must be set"
+type ModelSource struct {
+	// image is the image in spec.images imported from the source.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// oci imports the image from an OCI image layout in a PersistentVolumeClaim, e.g. copied by
+	// "oras copy --to-oci-layout". The manifest and the blobs are verified against their digests,
+	// and the image is available only after all of them are imported.
+	// +optional
+	OCI *OCISource `json:"oci,omitempty"`
+
+	// weights creates the image from the weights of a model in the GGUF or the safetensors format with a generated
+	// Modelfile, e.g. for a model which is not in any registry. The image is created by the ollama server once
+	// the weights are fetched, and is tracked in the status like the pulled images.
+	// It is not supported with the storage of a ModelCache, which is read-only.
+	// +optional
+	Weights *WeightsSource `json:"weights,omitempty"`
+}
+
+type OCISource struct {
+	// claimName is the name of the PersistentVolumeClaim in the namespace of the Model holding the layout.
+	// It is mounted read-only.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+
+	// path is the path of the directory or the tarball of the layout in the volume.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// reference selects the manifest in the layout by the annotation org.opencontainers.image.ref.name, e.g. "8b".
+	// It is required if the layout has more than one manifest.
+	// +optional
+	Reference string `json:"reference,omitempty"`
+}
+
+// +kubebuilder:validation:XValidation:rule="has(self.files) != has(self.claimName)",message="exactly one of files or claimName must be set"
+type WeightsSource struct {
+	// files are the files of the weights fetched over HTTP(S), either a GGUF file, or the safetensors files
+	// with the config and the tokenizer of the model. Each file is verified against its checksum.
+	// +optional
+	// +listType=map
+	// +listMapKey=url
+	Files []WeightsFile `json:"files,omitempty"`
+
+	// bearerTokenSecretName is the name of the Secret in the namespace of the Model whose key token is sent
+	// as the bearer token fetching the files.
+	// +optional
+	BearerTokenSecretName *string `json:"bearerTokenSecretName,omitempty"`
+
+	// claimName is the name of the PersistentVolumeClaim in the namespace of the Model holding the weights
+	// instead of the files. It is mounted read-only.
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+
+	// path is the path of the GGUF file or the directory of the safetensors files in the volume of claimName.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// modelfile is appended to the generated Modelfile, e.g. the TEMPLATE, PARAMETER and SYSTEM instructions.
+	// +optional
+	Modelfile string `json:"modelfile,omitempty"`
+}
+
+type WeightsFile struct {
+	// url is the HTTP(S) URL of the file.
+	// +required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// sha256 is the hex encoded SHA-256 checksum of the file.
+	// +required
+	// +kubebuilder:validation:Pattern=`^[a-fA-F0-9]{64}$`
+	SHA256 string `json:"sha256"`
+
+	// name is the name of the file, which the ollama server detects the kind of the file by,
+	// e.g. "model.safetensors". It defaults to the last segment of the path of url.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[^/=.][^/=]*$`
+	Name string `json:"name,omitempty"`
+}
+
+type ModelBackup struct {
+	// endpoint is the URL of the object storage, e.g. "https://s3.us-east-1.amazonaws.com" or
+	// "http://minio.minio:9000". The bucket is addressed in the path style.
+	// +required
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Endpoint string `json:"endpoint"`
+
+	// bucket is the name of the bucket the images are backed up to.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+
+	// prefix is the prefix of the keys of the blobs and the manifests in the bucket, which are stored
+	// in the layout of the models directory of the ollama server. The blobs are shared by the Models
+	// backed up with the same prefix.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// region is the region the requests are signed for. If it is not set, "us-east-1" is used.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// credentialsSecretName is the name of the Secret in the namespace of the Model holding the keys
+	// accessKeyID and secretAccessKey of the object storage.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecretName string `json:"credentialsSecretName"`
+
+	// insecure, if true, accesses the object storage without the verification of the TLS certificate.
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// ModelPullErrorPolicy is the behavior of the pulls after an image has failed all its attempts.
+// +kubebuilder:validation:Enum=FailFast;Continue
+type ModelPullErrorPolicy string
+
+const (
+	// ModelPullFailFast fails the pod without pulling the remaining images, and the pulls are restarted with the pod.
+	ModelPullFailFast ModelPullErrorPolicy = "FailFast"
+	// ModelPullContinue pulls the remaining images, and starts the ollama server without the failed images.
+	ModelPullContinue ModelPullErrorPolicy = "Continue"
+)
+
+type ModelPullPolicy struct {
+	// maxAttempts is the number of the attempts to pull each image.
+	// +optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+
+	// backoff is the wait before the second attempt, which is doubled after each failed attempt. Defaults to 10s.
+	// +optional
+	Backoff *metav1.Duration `json:"backoff,omitempty"`
+
+	// maxBackoff is the maximum wait between the attempts. Defaults to 5m.
+	// +optional
+	MaxBackoff *metav1.Duration `json:"maxBackoff,omitempty"`
+
+	// timeout is the timeout of each attempt. If it is not set, the attempts do not time out.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// onError is the behavior after an image has failed all its attempts.
+	// +optional
+	// +kubebuilder:default=FailFast
+	OnError ModelPullErrorPolicy `json:"onError,omitempty"`
 }
 
 type ModelTemplate struct {
@@ -50,6 +572,17 @@ type ModelTemplate struct {
 	// spec is the spec used to create the ollama server.
 	// +optional
 	Spec *ModelTemplateSpec `json:"spec"`
+
+	// podOverride is a partial Pod manifest which is merged onto the generated pod using strategic merge patch semantics.
+	// It allows setting any pod field which is not exposed by spec, e.g. securityContext, serviceAccountName,
+	// priorityClassName, imagePullSecrets, runtimeClassName, hostAliases, extra initContainers or sidecars.
+	// Fields owned by the operator (the pod identity and the image, command, args, ports, lifecycle and
+	// environment variables set by the operator on the ollama-server container) cannot be overridden.
+	// +optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	PodOverride *runtime.RawExtension `json:"podOverride,omitempty"`
 }
 
 type ModelTemplateSpec struct {
@@ -83,6 +616,36 @@ type ModelTemplateSpec struct {
 	// +listMapKey=topologyKey
 	// +listMapKey=whenUnsatisfiable
 	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// resources are the compute resources of the ollama server.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// storage is the volume the models are stored in. If it is not set, the models are stored in the container.
+	// +optional
+	Storage *ModelStorage `json:"storage,omitempty"`
+}
+
+type ModelStorage struct {
+	// storageClassName is the StorageClass of the volume. If it is not set, the default StorageClass is used.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// size is the size of the volume.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// nodeCache, if true, stores the models in the cache on the node instead of a volume of each pod.
+	// The cache is shared by the pods of all the Models on the node, so a model is downloaded once per node.
+	// The pods prefer the nodes which already hold the images of the Model. The cache requires the node cache
+	// agent, which reports the cached models and removes the models no Model uses. storageClassName and size are ignored.
+	// +optional
+	NodeCache *bool `json:"nodeCache,omitempty"`
+
+	// cacheName is the ModelCache in the namespace of the Model whose volume is mounted read-only instead of pulling
+	// the images on start. The pods are created once all images are in the cache. The other fields are ignored.
+	// +optional
+	CacheName *string `json:"cacheName,omitempty"`
 }
 
 const (
@@ -96,6 +659,151 @@ const (
 	ModelConditionFailed = "Failed"
 )
 
+const (
+	// ModelConditionRuntimeCompatible indicates whether the running ollama server satisfies spec.runtime.version.
+	ModelConditionRuntimeCompatible = "RuntimeCompatible"
+)
+
+const (
+	// ModelConditionPaused indicates whether the Model is paused.
+	ModelConditionPaused = "Paused"
+)
+
+const (
+	// ModelConditionIdle indicates whether the serving pods are scaled to zero because the Model is idle.
+	ModelConditionIdle = "Idle"
+)
+
+const (
+	// ModelConditionApproved indicates whether the images of the Model are approved by the ModelCatalogs.
+	ModelConditionApproved = "Approved"
+)
+
+const (
+	// ImagesApproved indicates that all images of the Model are approved by the ModelCatalogs.
+	ImagesApproved = "ImagesApproved"
+
+	// ImagesBlocked indicates that some images of the Model are not approved by the ModelCatalogs and are not pulled.
+	ImagesBlocked = "ImagesBlocked"
+)
+
+const (
+	// ModelConditionQuotaExceeded indicates whether the serving pods are scaled to zero because the Model exceeds a ModelQuota.
+	ModelConditionQuotaExceeded = "QuotaExceeded"
+)
+
+const (
+	// QuotaExceeded indicates that the Model exceeds a ModelQuota.
+	QuotaExceeded = "QuotaExceeded"
+
+	// WithinQuota indicates that the Model is within the ModelQuotas.
+	WithinQuota = "WithinQuota"
+)
+
+const (
+	// ModelConditionClassResolved indicates whether the ModelClass of spec.className exists.
+	// It is set only while spec.className is set.
+	ModelConditionClassResolved = "ModelClassResolved"
+)
+
+const (
+	// ModelClassFound indicates that the ModelClass exists and its defaults are applied to the Model.
+	ModelClassFound = "ModelClassFound"
+
+	// ModelClassNotFound indicates that the ModelClass does not exist, so no pod is created.
+	ModelClassNotFound = "ModelClassNotFound"
+)
+
+const (
+	// ModelConditionCacheReady indicates whether the ModelCache of spec.template.spec.storage.cacheName holds all images.
+	ModelConditionCacheReady = "CacheReady"
+)
+
+const (
+	// ModelConditionPullScheduled indicates whether the pull scheduler of the operator has permitted the pulls of
+	// all the pods of the Model. It is set only while the pull scheduler is enabled.
+	ModelConditionPullScheduled = "PullScheduled"
+)
+
+const (
+	// PullsQueued indicates that some pods of the Model wait for the pull scheduler.
+	PullsQueued = "PullsQueued"
+
+	// PullsPermitted indicates that the pull scheduler has permitted the pulls of all the pods of the Model.
+	PullsPermitted = "PullsPermitted"
+)
+
+const (
+	// ModelConditionStorageSufficient indicates whether the storage of the serving pods can hold the images of the Model.
+	// It is set only while the storage preflight of the operator is enabled and the pods pull the images into
+	// their own storage, that is the container filesystem or the volume of spec.template.spec.storage.
+	ModelConditionStorageSufficient = "StorageSufficient"
+)
+
+const (
+	// ModelConditionBackedUp indicates whether the serving pods have backed up the images to spec.backup.
+	// It is set only while spec.backup is set. The failures of the backup do not fail the pulls of the images.
+	ModelConditionBackedUp = "BackedUp"
+)
+
+const (
+	// BackupSucceeded indicates that the serving pods have backed up the pulled images or restored them from the backup.
+	BackupSucceeded = "BackupSucceeded"
+
+	// BackupFailed indicates that some serving pods have failed to back up the images or to restore them from the backup.
+	BackupFailed = "BackupFailed"
+)
+
+const (
+	// StorageFits indicates that the download size of the images is within the storage of the serving pods.
+	StorageFits = "StorageFits"
+
+	// InsufficientStorage indicates that the images do not fit the storage of the serving pods, so no pod is created.
+	InsufficientStorage = "InsufficientStorage"
+
+	// DownloadSizeUnknown indicates that the download size of the images could not be resolved from the registries.
+	// The pods are created without the preflight.
+	DownloadSizeUnknown = "DownloadSizeUnknown"
+)
+
+const (
+	// ImagesCached indicates that all images of the Model are in the ModelCache.
+	ImagesCached = "ImagesCached"
+
+	// ImagesNotCached indicates that some images of the Model are not in the ModelCache yet, so no pod is created.
+	ImagesNotCached = "ImagesNotCached"
+
+	// ModelCacheNotFound indicates that the ModelCache does not exist.
+	ModelCacheNotFound = "ModelCacheNotFound"
+)
+
+const (
+	// ModelScaledToZero indicates that the serving pods are scaled to zero because the Model is idle.
+	ModelScaledToZero = "ScaledToZero"
+
+	// ModelActive indicates that the Model has served a request within spec.idle.timeout.
+	ModelActive = "Active"
+)
+
+const (
+	// ModelPaused indicates that the serving pods are scaled to zero because spec.paused is true.
+	ModelPaused = "Paused"
+
+	// ModelNotPaused indicates that the Model is provisioned.
+	ModelNotPaused = "NotPaused"
+)
+
+const (
+	// RuntimeVersionSatisfied indicates that the running ollama server satisfies the version range.
+	RuntimeVersionSatisfied = "VersionSatisfied"
+
+	// RuntimeVersionUnsatisfied indicates that the running ollama server does not satisfy the version range.
+	RuntimeVersionUnsatisfied = "VersionUnsatisfied"
+
+	// RuntimeVersionInvalid indicates that the version range cannot be parsed.
+	RuntimeVersionInvalid = "VersionInvalid"
+)
+
 const (
 	// PodCreated indicates that the pod has been created.
 	PodCreated = "PodCreated"
@@ -112,10 +820,116 @@ type ModelStatus struct {
 	// podRef represents a reference to the pod where the model is running.
 	// +optional
 	PodRef *corev1.ObjectReference `json:"podRef,omitempty"`
+
+	// runtime represents the ollama server runtime of the pod referenced by podRef.
+	// +optional
+	Runtime *ModelRuntimeStatus `json:"runtime,omitempty"`
+
+	// images are the models pulled by the serving pod referenced by podRef.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Images []ModelImageStatus `json:"images,omitempty"`
+
+	// pulls are the attempts and the last errors of the pulls of the updated serving pods.
+	// They are reported only if spec.pullPolicy is set.
+	// +optional
+	// +listType=map
+	// +listMapKey=image
+	Pulls []ModelPullStatus `json:"pulls,omitempty"`
+
+	// blockedImages are the images in spec.images which are not approved by the ModelCatalogs.
+	// They are not pulled by the serving pods.
+	// +optional
+	// +listType=atomic
+	BlockedImages []string `json:"blockedImages,omitempty"`
+
+	// downloadSize is the total size of the blobs of spec.images pulled from the registries, which is resolved
+	// from their manifests before the pods are created. The blobs shared by the images are counted once.
+	// It is reported only while the storage preflight of the operator is enabled.
+	// +optional
+	DownloadSize *resource.Quantity `json:"downloadSize,omitempty"`
+
+	// replicas is the number of the serving pods.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// readyReplicas is the number of the serving pods which are ready.
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// updatedReplicas is the number of the serving pods which are up-to-date with the Model.
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// selector is the label selector of the serving pods, used by the scale subresource.
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
+	// load is the inference load reported by the serving pods. It is reported only if autoscaling is set.
+	// +optional
+	Load *ModelLoad `json:"load,omitempty"`
+
+	// lastScaleTime is the last time the autoscaler changed the replicas.
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+
+	// url is the external URL of the Model. It is reported only if expose is set.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// lastActiveTime is the last time the Model was observed serving requests.
+	// +optional
+	LastActiveTime *metav1.Time `json:"lastActiveTime,omitempty"`
+
+	// conditions represents the observations of the Model's current state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+type ModelImageStatus struct {
+	// name is the image in spec.images.
+	Name string `json:"name"`
+
+	// size is the size of the model.
+	Size resource.Quantity `json:"size"`
+}
+
+type ModelPullStatus struct {
+	// image is the image in spec.images.
+	Image string `json:"image"`
+
+	// attempts is the largest number of the attempts the serving pods made to pull the image.
+	Attempts int32 `json:"attempts"`
+
+	// lastError is the error of the last failed attempt. It is empty if the image is pulled.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+type ModelLoad struct {
+	// inFlightRequests is the number of the requests processed by the serving pods.
+	InFlightRequests int32 `json:"inFlightRequests"`
+
+	// queuedRequests is the number of the requests waiting in front of the serving pods.
+	QueuedRequests int32 `json:"queuedRequests"`
+}
+
+type ModelRuntimeStatus struct {
+	// image is the container image of the running ollama server.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// version is the version reported by the running ollama server.
+	// +optional
+	Version string `json:"version,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 
 // Model is the Schema for the models API.
 type Model struct {
@@ -0,0 +1,110 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModelBindingNameLabel is the label set on the Services and the Secrets created for a ModelBinding.
+const ModelBindingNameLabel = "ollama.sivchari.io/model-binding"
+
+// ModelBindingSpec defines the desired state of ModelBinding.
+type ModelBindingSpec struct {
+	// model is the bound Model in another namespace.
+	// +required
+	Model ModelReference `json:"model"`
+}
+
+type ModelReference struct {
+	// name is the name of the Model.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// namespace is the namespace of the Model.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+}
+
+const (
+	// ModelBindingConditionBound indicates whether the Model is bound, that is the Service and the Secret are created.
+	ModelBindingConditionBound = "Bound"
+)
+
+const (
+	// ModelBindingGranted indicates that the Model is shared with the namespace of the ModelBinding.
+	ModelBindingGranted = "Granted"
+
+	// ModelBindingNotGranted indicates that no ModelGrant shares the Model with the namespace of the ModelBinding.
+	ModelBindingNotGranted = "NotGranted"
+
+	// ModelBindingModelNotFound indicates that the bound Model does not exist.
+	ModelBindingModelNotFound = "ModelNotFound"
+)
+
+// ModelBindingStatus defines the observed state of ModelBinding.
+type ModelBindingStatus struct {
+	// serviceName is the name of the Service in the namespace of the ModelBinding, which is an alias of the Model's Service.
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+
+	// secretName is the name of the Secret in the namespace of the ModelBinding, which holds the connection details.
+	// It has the keys host, port, url and OLLAMA_HOST.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// url is the URL of the bound Model.
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// conditions represent the current state of the ModelBinding.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Model",type=string,JSONPath=`.spec.model.name`
+// +kubebuilder:printcolumn:name="Namespace",type=string,JSONPath=`.spec.model.namespace`
+// +kubebuilder:printcolumn:name="Bound",type=string,JSONPath=`.status.conditions[?(@.type=="Bound")].status`
+
+// ModelBinding is the Schema for the modelbindings API.
+// It binds a Model shared by a ModelGrant in another namespace, so it can be used in the namespace of the ModelBinding.
+type ModelBinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ModelBindingSpec   `json:"spec,omitempty"`
+	Status ModelBindingStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelBindingList contains a list of ModelBinding.
+type ModelBindingList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelBinding `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelBinding{}, &ModelBindingList{})
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModelCacheNameLabel is the label set on the PersistentVolumeClaims and the Jobs created for a ModelCache.
+const ModelCacheNameLabel = "ollama.sivchari.io/model-cache"
+
+// ModelCacheSpec defines the desired state of ModelCache.
+type ModelCacheSpec struct {
+	// images are the models pulled into the cache, e.g. "llama3:8b".
+	// Each part of the names consists of the letters, digits, '.', '_' and '-', starts with a letter or a digit,
+	// and does not contain "..".
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:Pattern=`^([A-Za-z0-9](\.?[A-Za-z0-9_-])*\.?(:[0-9]+)?/)?([A-Za-z0-9](\.?[A-Za-z0-9_-])*\.?/)*[A-Za-z0-9](\.?[A-Za-z0-9_-])*\.?(:[A-Za-z0-9](\.?[A-Za-z0-9_-])*\.?)?$`
+	// +listType=set
+	Images []string `json:"images"`
+
+	// runtime is the ollama server runtime which pulls the images.
+	// +optional
+	Runtime *ModelRuntime `json:"runtime,omitempty"`
+
+	// registry configures the registries the images are pulled from, like spec.registry of Model.
+	// +optional
+	Registry *ModelRegistry `json:"registry,omitempty"`
+
+	// sources are the sources the images are imported from instead of the registries, like spec.sources of Model.
+	// The weights sources are not supported, since the images are not created by the ollama server.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self.all(s, !has(s.weights))",message="weights sources are not supported"
+	// +listType=map
+	// +listMapKey=image
+	Sources []ModelSource `json:"sources,omitempty"`
+
+	// storage is the volume the images are pulled into.
+	// +optional
+	Storage ModelCacheStorage `json:"storage,omitempty"`
+}
+
+type ModelCacheStorage struct {
+	// storageClassName is the StorageClass of the volume. If it is not set, the default StorageClass is used.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// size is the size of the volume.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// accessModes are the access modes of the volume. They must allow the pull Jobs to write the volume and
+	// the pods of the Models on different nodes to read it. The default is ReadWriteMany.
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+}
+
+// ModelCacheImagePhase is the phase of an image in a ModelCache.
+// +kubebuilder:validation:Enum=Pending;Queued;Pulling;Ready;Failed
+type ModelCacheImagePhase string
+
+const (
+	// ModelCacheImagePending indicates that the image waits for the pulls of the preceding images.
+	ModelCacheImagePending ModelCacheImagePhase = "Pending"
+
+	// ModelCacheImageQueued indicates that the pull Job of the image waits for the pull scheduler of the operator.
+	ModelCacheImageQueued ModelCacheImagePhase = "Queued"
+
+	// ModelCacheImagePulling indicates that the pull Job of the image is running.
+	ModelCacheImagePulling ModelCacheImagePhase = "Pulling"
+
+	// ModelCacheImageReady indicates that the image is in the cache.
+	ModelCacheImageReady ModelCacheImagePhase = "Ready"
+
+	// ModelCacheImageFailed indicates that the pull Job of the image has failed. The pull is retried when the Job is deleted.
+	ModelCacheImageFailed ModelCacheImagePhase = "Failed"
+)
+
+const (
+	// ModelCacheConditionReady indicates whether all the images are in the cache.
+	ModelCacheConditionReady = "Ready"
+)
+
+const (
+	// ModelCacheCached indicates that all the images are in the cache.
+	ModelCacheCached = "Cached"
+
+	// ModelCachePulling indicates that some images are not pulled yet.
+	ModelCachePulling = "Pulling"
+
+	// ModelCachePullFailed indicates that the pulls of some images have failed.
+	ModelCachePullFailed = "PullFailed"
+)
+
+// ModelCacheStatus defines the observed state of ModelCache.
+type ModelCacheStatus struct {
+	// claimName is the name of the PersistentVolumeClaim the images are pulled into.
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+
+	// images are the pull states of spec.images.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Images []ModelCacheImageStatus `json:"images,omitempty"`
+
+	// conditions represent the current state of the ModelCache.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+type ModelCacheImageStatus struct {
+	// name is the image in spec.images.
+	Name string `json:"name"`
+
+	// phase is the pull state of the image.
+	Phase ModelCacheImagePhase `json:"phase"`
+
+	// jobName is the name of the Job pulling the image.
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Claim",type=string,JSONPath=`.status.claimName`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+
+// ModelCache is the Schema for the modelcaches API.
+// It pulls the images once into a PersistentVolumeClaim, which the Models in the same namespace mount read-only
+// with spec.template.spec.storage.cacheName instead of pulling the images on start.
+type ModelCache struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ModelCacheSpec   `json:"spec,omitempty"`
+	Status ModelCacheStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelCacheList contains a list of ModelCache.
+type ModelCacheList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelCache `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelCache{}, &ModelCacheList{})
+}
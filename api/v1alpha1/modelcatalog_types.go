@@ -0,0 +1,71 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModelCatalogSpec defines the models approved by the ModelCatalog.
+type ModelCatalogSpec struct {
+	// entries are the approved models.
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	Entries []ModelCatalogEntry `json:"entries"`
+}
+
+type ModelCatalogEntry struct {
+	// name is the approved model without the tag, e.g. "llama3" or "hf.co/bartowski/*".
+	// The default registry and namespace can be omitted as in spec.images of the Models.
+	// It is a shell pattern where "*" matches any sequence of the characters except "/".
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^[^:]+$`
+	Name string `json:"name"`
+
+	// tags are the approved tags of the model, e.g. "8b" or "8b-*". If it is empty, all tags are approved.
+	// They are shell patterns as name.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// ModelCatalog is the Schema for the modelcatalogs API.
+// If any ModelCatalog exists, the Models can only use the images approved by one of them.
+// The Models using an unapproved image are rejected on admission, and the Models which already exist
+// do not pull the unapproved images and report them in the Approved condition.
+type ModelCatalog struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ModelCatalogSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelCatalogList contains a list of ModelCatalog.
+type ModelCatalogList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelCatalog `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelCatalog{}, &ModelCatalogList{})
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultModelClassAnnotation is the annotation which marks a ModelClass as the default class.
+// The default class is used by the Models which do not set spec.className.
+const DefaultModelClassAnnotation = "ollama.sivchari.io/is-default-class"
+
+// ModelClassSpec defines the defaults of the Models which use the ModelClass.
+// The fields set by a Model take precedence over the defaults of its class.
+type ModelClassSpec struct {
+	// runtime is the default ollama server runtime of the Models.
+	// +optional
+	Runtime *ModelRuntime `json:"runtime,omitempty"`
+
+	// template is the default template used to create the ollama server of the Models.
+	// The labels, the annotations and the nodeSelector are merged by key, the volumes by name and
+	// the volumeMounts by mountPath. The other fields are used only if the Model does not set them.
+	// +optional
+	Template *ModelTemplate `json:"template,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Default",type=string,JSONPath=`.metadata.annotations.ollama\.sivchari\.io/is-default-class`
+
+// ModelClass is the Schema for the modelclasses API.
+// It holds the defaults shared by the Models, e.g. the runtime image, the scheduling constraints,
+// the resources and the storage, like a StorageClass does for the PersistentVolumeClaims.
+type ModelClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ModelClassSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelClassList contains a list of ModelClass.
+type ModelClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelClass{}, &ModelClassList{})
+}
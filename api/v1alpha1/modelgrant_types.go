@@ -0,0 +1,66 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModelGrantSpec defines the namespaces the Models are shared with.
+type ModelGrantSpec struct {
+	// models are the names of the shared Models in the namespace of the ModelGrant.
+	// If it is empty, all Models in the namespace are shared.
+	// +optional
+	// +listType=set
+	Models []string `json:"models,omitempty"`
+
+	// to are the namespaces which can bind the shared Models with ModelBindings.
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	To []ModelGrantTo `json:"to"`
+}
+
+type ModelGrantTo struct {
+	// namespace is the namespace which can bind the shared Models.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelGrant is the Schema for the modelgrants API.
+// It shares the Models in its namespace with the ModelBindings in other namespaces.
+type ModelGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ModelGrantSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelGrantList contains a list of ModelGrant.
+type ModelGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelGrant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelGrant{}, &ModelGrantList{})
+}
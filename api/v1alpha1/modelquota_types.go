@@ -0,0 +1,90 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ModelQuotaSpec defines the desired state of ModelQuota.
+type ModelQuotaSpec struct {
+	// hard is the limits of the Models in the namespace.
+	// +required
+	Hard ModelQuotaLimits `json:"hard"`
+}
+
+// +kubebuilder:validation:XValidation:rule="has(self.models) || has(self.size)",message="either models or size must be set"
+type ModelQuotaLimits struct {
+	// models is the maximum number of the models in spec.images of all Models in the namespace.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Models *int32 `json:"models,omitempty"`
+
+	// size is the maximum total size of the models. The size of a model is known once it is pulled by any Model,
+	// so a model whose size is not known yet is counted as zero until it is pulled.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+}
+
+// ModelQuotaStatus defines the observed state of ModelQuota.
+type ModelQuotaStatus struct {
+	// hard is the enforced limits.
+	// +optional
+	Hard ModelQuotaLimits `json:"hard,omitempty"`
+
+	// used is the usage of the Models within the limits.
+	// +optional
+	Used ModelQuotaUsage `json:"used,omitempty"`
+}
+
+type ModelQuotaUsage struct {
+	// models is the number of the models.
+	Models int32 `json:"models"`
+
+	// size is the total size of the models.
+	Size resource.Quantity `json:"size"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Models",type=integer,JSONPath=`.status.used.models`
+// +kubebuilder:printcolumn:name="Size",type=string,JSONPath=`.status.used.size`
+
+// ModelQuota is the Schema for the modelquotas API.
+// It limits the number and the total size of the models in a namespace. The Models are admitted in the order
+// of creation, and a Model exceeding the limits is rejected on admission or scaled to zero.
+type ModelQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ModelQuotaSpec   `json:"spec,omitempty"`
+	Status ModelQuotaStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelQuotaList contains a list of ModelQuota.
+type ModelQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelQuota{}, &ModelQuotaList{})
+}
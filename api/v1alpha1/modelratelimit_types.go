@@ -0,0 +1,89 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RateLimitKey is what the limits of a ModelRateLimit are counted per.
+// +kubebuilder:validation:Enum=APIKey;Namespace
+type RateLimitKey string
+
+const (
+	// RateLimitKeyAPIKey counts the limits per API key of spec.auth.apiKeys of the Model, which the gateway verifies
+	// the bearer token of the requests against. The requests without such an API key are counted per the namespace
+	// of the client pods, or per the client address if the client is not a pod.
+	RateLimitKeyAPIKey RateLimitKey = "APIKey"
+	// RateLimitKeyNamespace counts the limits per namespace of the client pods, or per the client address if the
+	// client is not a pod.
+	RateLimitKeyNamespace RateLimitKey = "Namespace"
+)
+
+// ModelRateLimitSpec defines the desired state of ModelRateLimit.
+// +kubebuilder:validation:XValidation:rule="has(self.requestsPerSecond) || has(self.tokensPerMinute)",message="either requestsPerSecond or tokensPerMinute must be set"
+type ModelRateLimitSpec struct {
+	// modelSelector selects the Models in the namespace of the ModelRateLimit the limits apply to.
+	// If it is not set, the limits apply to all Models in the namespace.
+	// +optional
+	ModelSelector *metav1.LabelSelector `json:"modelSelector,omitempty"`
+
+	// key is what the limits are counted per. Defaults to APIKey.
+	// +optional
+	// +kubebuilder:default=APIKey
+	Key RateLimitKey `json:"key,omitempty"`
+
+	// requestsPerSecond is the number of the requests allowed per second.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	RequestsPerSecond *int32 `json:"requestsPerSecond,omitempty"`
+
+	// burst is the number of the requests allowed at once above requestsPerSecond. Defaults to requestsPerSecond.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Burst *int32 `json:"burst,omitempty"`
+
+	// tokensPerMinute is the number of the prompt and the generated tokens allowed per minute.
+	// The tokens are counted from the usage reported in the responses of the ollama server.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TokensPerMinute *int64 `json:"tokensPerMinute,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelRateLimit is the Schema for the modelratelimits API.
+// The limits are enforced by the gateway for the requests routed to the selected Models.
+type ModelRateLimit struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ModelRateLimitSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ModelRateLimitList contains a list of ModelRateLimit.
+type ModelRateLimitList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelRateLimit `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ModelRateLimit{}, &ModelRateLimitList{})
+}
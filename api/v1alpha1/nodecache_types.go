@@ -0,0 +1,88 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeCacheStatus defines the models held in the cache of a node.
+type NodeCacheStatus struct {
+	// models are the models whose manifest and blobs are in the cache.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Models []NodeCacheModel `json:"models,omitempty"`
+
+	// blobs is the number of the blobs in the cache.
+	// +optional
+	Blobs int32 `json:"blobs,omitempty"`
+
+	// size is the total size of the blobs in the cache.
+	// +optional
+	Size resource.Quantity `json:"size,omitempty"`
+
+	// reclaimedSize is the total size of the unreferenced blobs removed from the cache by the last garbage collection.
+	// +optional
+	ReclaimedSize resource.Quantity `json:"reclaimedSize,omitempty"`
+
+	// lastScanTime is the last time the cache was scanned.
+	// +optional
+	LastScanTime *metav1.Time `json:"lastScanTime,omitempty"`
+}
+
+// NodeCacheModel is a model held in the cache of a node.
+type NodeCacheModel struct {
+	// name is the normalized name of the model, e.g. "llama3:8b".
+	Name string `json:"name"`
+
+	// size is the total size of the blobs of the model.
+	// +optional
+	Size resource.Quantity `json:"size,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Blobs",type=integer,JSONPath=`.status.blobs`
+// +kubebuilder:printcolumn:name="Size",type=string,JSONPath=`.status.size`
+// +kubebuilder:printcolumn:name="Last Scan",type=date,JSONPath=`.status.lastScanTime`
+
+// NodeCache is the Schema for the nodecaches API.
+// It reports the models held in the cache of the node of the same name, which is shared by the pods of
+// the Models storing their models in the node cache. It is written by the node cache agent running on the node,
+// and the operator prefers the nodes which already hold the images of a Model when scheduling its pods.
+type NodeCache struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status NodeCacheStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NodeCacheList contains a list of NodeCache.
+type NodeCacheList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeCache `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeCache{}, &NodeCacheList{})
+}
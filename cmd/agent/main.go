@@ -0,0 +1,416 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command agent runs the helpers which the operator injects into the Model pods.
+//
+// Usage:
+//
+//	agent proxy [flags]
+//	agent node-cache [flags]
+//	agent pull [flags] IMAGE...
+//	agent fetch [flags] NAME=SHA256=URL...
+//	agent wait-permit [flags]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/nodecache"
+	"github.com/sivchari/ollama-operator/internal/proxy"
+	"github.com/sivchari/ollama-operator/internal/pull"
+	"github.com/sivchari/ollama-operator/internal/registry"
+	"github.com/sivchari/ollama-operator/internal/s3"
+)
+
+var setupLog = klog.Background().WithName("setup")
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: agent proxy|node-cache|pull|fetch|wait-permit|seed [flags]")
+		os.Exit(2)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch cmd := os.Args[1]; cmd {
+	case "proxy":
+		err = runProxy(ctx, os.Args[2:])
+	case "node-cache":
+		err = runNodeCache(ctx, os.Args[2:])
+	case "pull":
+		err = runPull(ctx, os.Args[2:])
+	case "fetch":
+		err = runFetch(ctx, os.Args[2:])
+	case "wait-permit":
+		err = runWaitPermit(ctx, os.Args[2:])
+	case "seed":
+		err = runSeed(ctx, os.Args[2:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", cmd)
+		os.Exit(2)
+	}
+	if err != nil {
+		setupLog.Error(err, "problem running agent", "command", os.Args[1])
+		os.Exit(1)
+	}
+}
+
+func runProxy(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("proxy", flag.ExitOnError)
+	bindAddr := fs.String("bind-address", ":11434", "The address the proxy binds to.")
+	metricsAddr := fs.String("metrics-bind-address", fmt.Sprintf(":%d", proxy.MetricsPort), "The address the metrics endpoint binds to.")
+	upstream := fs.String("upstream", "http://127.0.0.1:11435", "The URL of the ollama server.")
+	concurrency := fs.Int("max-concurrency", 0, "The maximum number of requests forwarded at once. 0 means unlimited.")
+	auth := fs.Bool("auth", false, "If set, the requests are authenticated with the API keys or the JWTs.")
+	apiKeysDir := fs.String("api-keys-dir", "", "The directory the Secrets of the API keys are mounted in.")
+	adminKeysDir := fs.String("admin-keys-dir", "", "The directory the Secrets of the admin API keys are mounted in.")
+	jwtIssuer := fs.String("jwt-issuer", "", "The URL of the OpenID Connect issuer of the JWTs. If empty, the JWTs are not accepted.")
+	jwtAudiences := fs.String("jwt-audiences", "", "The comma separated audiences accepted in the JWTs. Required with --jwt-issuer.")
+	jwtAdminClaim := fs.String("jwt-admin-claim", "", "The claim which grants the management endpoints, in the form of name=value.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	upstreamURL, err := url.Parse(*upstream)
+	if err != nil {
+		return err
+	}
+	registry := prometheus.NewRegistry()
+	p, err := proxy.New(upstreamURL, *concurrency, registry)
+	if err != nil {
+		return err
+	}
+	var handler http.Handler = p
+	if *auth {
+		authenticator, err := newAuthenticator(ctx, *apiKeysDir, *adminKeysDir, *jwtIssuer, *jwtAudiences, *jwtAdminClaim)
+		if err != nil {
+			return err
+		}
+		handler = authenticator.Wrap(p)
+	}
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	g, ctx := errgroup.WithContext(ctx)
+	for _, srv := range []*http.Server{
+		{Addr: *bindAddr, Handler: handler, ReadHeaderTimeout: 30 * time.Second},
+		{Addr: *metricsAddr, Handler: metricsMux, ReadHeaderTimeout: 30 * time.Second},
+	} {
+		g.Go(func() error {
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		})
+		g.Go(func() error {
+			<-ctx.Done()
+			return srv.Shutdown(context.Background())
+		})
+	}
+	setupLog.Info("starting proxy", "upstream", upstreamURL.String())
+	return g.Wait()
+}
+
+func runNodeCache(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("node-cache", flag.ExitOnError)
+	dir := fs.String("dir", "/var/lib/ollama-operator/models", "The directory of the node cache.")
+	nodeName := fs.String("node-name", os.Getenv("NODE_NAME"), "The name of the node the agent runs on.")
+	interval := fs.Duration("interval", time.Minute, "The interval to scan the node cache.")
+	gc := fs.Bool("gc", true, "If set, the models which no Model uses and the unreferenced blobs are removed from the node cache.")
+	gcGracePeriod := fs.Duration("gc-grace-period", time.Hour,
+		"The minimum age of the files removed from the node cache, which protects the pulls in progress.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *nodeName == "" {
+		return errors.New("--node-name or NODE_NAME is required")
+	}
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(ollamav1alpha1.AddToScheme(scheme))
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	agent := &nodecache.Agent{
+		Client:        c,
+		Dir:           *dir,
+		NodeName:      *nodeName,
+		GC:            *gc,
+		GCGracePeriod: *gcGracePeriod,
+	}
+	ctx = ctrl.LoggerInto(ctx, klog.Background().WithName("node-cache"))
+	setupLog.Info("starting node cache agent", "dir", *dir, "node", *nodeName)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		if err := agent.Sync(ctx); err != nil {
+			setupLog.Error(err, "unable to sync the node cache")
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// stringsFlag is a flag which can be repeated.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func runPull(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("pull", flag.ExitOnError)
+	modelsDir := fs.String("models-dir", "/root/.ollama/models", "The models directory of the ollama server.")
+	var mirrors, insecureRegistries, registryAuths, ociLayouts, ociReferences stringsFlag
+	fs.Var(&mirrors, "mirror", "The mirror of a registry host in the form of host=mirror. Can be repeated.")
+	fs.Var(&insecureRegistries, "insecure-registry", "The registry host accessed with plain HTTP and without the TLS verification. Can be repeated.")
+	fs.Var(&registryAuths, "registry-auth", "The directory of the credentials of a registry host in the form of host=dir. Can be repeated.")
+	fs.Var(&ociLayouts, "oci-layout", "The OCI image layout, a directory or a tarball, an image is imported from in the form of image=path. Can be repeated.")
+	fs.Var(&ociReferences, "oci-reference", "The reference of the manifest in the OCI image layout of an image in the form of image=reference. Can be repeated.")
+	var policy pull.Policy
+	fs.IntVar(&policy.MaxAttempts, "max-attempts", 1, "The number of the attempts to pull an image.")
+	fs.DurationVar(&policy.Backoff, "backoff", 10*time.Second, "The wait before the second attempt, which is doubled after each failed attempt.")
+	fs.DurationVar(&policy.MaxBackoff, "max-backoff", 5*time.Minute, "The maximum wait between the attempts.")
+	fs.DurationVar(&policy.Timeout, "timeout", 0, "The timeout of each attempt. 0 means no timeout.")
+	fs.BoolVar(&policy.ContinueOnError, "continue-on-error", false,
+		"If set, the remaining images are pulled after an image has failed all its attempts, and the command succeeds.")
+	resultsFile := fs.String("results-file", "/dev/termination-log", "The file the results of the pulls are written to as JSON. If empty, they are not written.")
+	peers := fs.String("peers", "", "The DNS name of the headless Service of the ready pods of the same Model, "+
+		"whose seeders the blobs are copied from before they are pulled from the registries. If empty, the blobs are not copied.")
+	peerPort := fs.Int("peer-port", pull.SeedPort, "The port of the seeders of the peers.")
+	backupEndpoint := fs.String("backup-endpoint", "", "The URL of the S3-compatible object storage the models are backed up to. If empty, the models are not backed up.")
+	backupBucket := fs.String("backup-bucket", "", "The bucket the models are backed up to.")
+	backupPrefix := fs.String("backup-prefix", "", "The prefix of the keys of the models in the bucket.")
+	backupRegion := fs.String("backup-region", s3.DefaultRegion, "The region the requests to the object storage are signed for.")
+	backupInsecure := fs.Bool("backup-insecure", false, "If set, the TLS certificate of the object storage is not verified.")
+	backupCredentialsDir := fs.String("backup-credentials-dir", "", "The directory of the keys accessKeyID and secretAccessKey of the object storage.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	config := registry.Config{Mirrors: map[string]string{}, Hosts: map[string]registry.Host{}}
+	for _, mirror := range mirrors {
+		host, to, ok := strings.Cut(mirror, "=")
+		if !ok {
+			return fmt.Errorf("invalid --mirror %q, must be host=mirror", mirror)
+		}
+		config.Mirrors[host] = to
+	}
+	for _, host := range insecureRegistries {
+		h := config.Hosts[host]
+		h.Insecure = true
+		config.Hosts[host] = h
+	}
+	for _, auth := range registryAuths {
+		host, dir, ok := strings.Cut(auth, "=")
+		if !ok {
+			return fmt.Errorf("invalid --registry-auth %q, must be host=dir", auth)
+		}
+		h := config.Hosts[host]
+		if err := registry.LoadCredentials(dir, &h); err != nil {
+			return err
+		}
+		config.Hosts[host] = h
+	}
+	layouts := map[string]pull.Layout{}
+	for _, layout := range ociLayouts {
+		image, path, ok := strings.Cut(layout, "=")
+		if !ok {
+			return fmt.Errorf("invalid --oci-layout %q, must be image=path", layout)
+		}
+		layouts[image] = pull.Layout{Path: path}
+	}
+	for _, reference := range ociReferences {
+		image, ref, ok := strings.Cut(reference, "=")
+		layout, found := layouts[image]
+		if !ok || !found {
+			return fmt.Errorf("invalid --oci-reference %q, must be image=reference of an image in --oci-layout", reference)
+		}
+		layout.Reference = ref
+		layouts[image] = layout
+	}
+	puller := &pull.Puller{Registry: registry.NewClient(config), Dir: *modelsDir, Layouts: layouts}
+	if *peers != "" {
+		puller.Peers = &pull.Peers{Host: *peers, Port: *peerPort}
+	}
+	if *backupEndpoint != "" {
+		backupConfig := s3.Config{Endpoint: *backupEndpoint, Bucket: *backupBucket, Region: *backupRegion, Insecure: *backupInsecure}
+		if *backupCredentialsDir != "" {
+			if err := s3.LoadCredentials(*backupCredentialsDir, &backupConfig); err != nil {
+				return err
+			}
+		}
+		backup, err := s3.NewClient(backupConfig)
+		if err != nil {
+			return err
+		}
+		puller.Backup = backup
+		puller.BackupPrefix = *backupPrefix
+	}
+	ctx = ctrl.LoggerInto(ctx, klog.Background().WithName("pull"))
+	setupLog.Info("pulling models", "images", fs.Args())
+	results, err := puller.PullAll(ctx, fs.Args(), policy)
+	if *resultsFile != "" {
+		if err := pull.WriteResults(*resultsFile, results); err != nil {
+			setupLog.Error(err, "unable to write the results of the pulls", "path", *resultsFile)
+		}
+	}
+	if err != nil && policy.ContinueOnError {
+		// The failed images are reported in the results, and the ollama server starts with the pulled images.
+		setupLog.Error(err, "some images are not pulled")
+		return nil
+	}
+	return err
+}
+
+// apiKeysReloadInterval is the interval to reload the API keys, which are updated by the kubelet when the Secrets change.
+const apiKeysReloadInterval = 30 * time.Second
+
+func newAuthenticator(ctx context.Context, apiKeysDir, adminKeysDir, jwtIssuer, jwtAudiences, jwtAdminClaim string) (*proxy.Authenticator, error) {
+	var jwt *proxy.JWTConfig
+	if jwtIssuer != "" {
+		jwt = &proxy.JWTConfig{Issuer: jwtIssuer}
+		if jwtAudiences != "" {
+			jwt.Audiences = strings.Split(jwtAudiences, ",")
+		}
+		if jwtAdminClaim != "" {
+			name, value, ok := strings.Cut(jwtAdminClaim, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid --jwt-admin-claim %q, must be name=value", jwtAdminClaim)
+			}
+			jwt.AdminClaim, jwt.AdminValue = name, value
+		}
+	}
+	authenticator, err := proxy.NewAuthenticator(ctx, jwt)
+	if err != nil {
+		return nil, err
+	}
+	if err := authenticator.LoadAPIKeys(apiKeysDir, adminKeysDir); err != nil {
+		return nil, err
+	}
+	go func() {
+		ticker := time.NewTicker(apiKeysReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := authenticator.LoadAPIKeys(apiKeysDir, adminKeysDir); err != nil {
+					setupLog.Error(err, "unable to reload the API keys")
+				}
+			}
+		}
+	}()
+	return authenticator, nil
+}
+
+func runFetch(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("fetch", flag.ExitOnError)
+	dir := fs.String("dir", "", "The directory the files and the Modelfile are written into.")
+	bearerTokenFile := fs.String("bearer-token-file", "", "The file of the bearer token sent fetching the files.")
+	modelfile := fs.String("modelfile", "", "The Modelfile the model is created from.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		return errors.New("--dir is required")
+	}
+	fetcher := &pull.Fetcher{}
+	if *bearerTokenFile != "" {
+		token, err := os.ReadFile(*bearerTokenFile)
+		if err != nil {
+			return err
+		}
+		fetcher.Token = strings.TrimSpace(string(token))
+	}
+	files := make([]pull.File, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		name, rest, ok1 := strings.Cut(arg, "=")
+		sum, u, ok2 := strings.Cut(rest, "=")
+		if !ok1 || !ok2 {
+			return fmt.Errorf("invalid file %q, must be name=sha256=url", arg)
+		}
+		files = append(files, pull.File{Name: name, SHA256: sum, URL: u})
+	}
+	setupLog.Info("fetching files", "dir", *dir, "files", len(files))
+	if err := fetcher.Fetch(ctx, *dir, files); err != nil {
+		return err
+	}
+	// The Modelfile is written the last, so the model is created only from the verified files.
+	return pull.WriteModelfile(*dir, *modelfile)
+}
+
+func runWaitPermit(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("wait-permit", flag.ExitOnError)
+	labelsFile := fs.String("labels-file", "/etc/ollama-pull-permit/labels", "The file the labels of the pod are projected into by the downward API.")
+	interval := fs.Duration("interval", time.Second, "The interval to check the labels.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	setupLog.Info("waiting for the pull scheduler to permit the pulls")
+	return pull.WaitPermit(ctx, *labelsFile, *interval)
+}
+
+func runSeed(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	modelsDir := fs.String("models-dir", "/root/.ollama/models", "The models directory of the ollama server.")
+	bindAddr := fs.String("bind-address", fmt.Sprintf(":%d", pull.SeedPort), "The address the seeder binds to.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	srv := &http.Server{Addr: *bindAddr, Handler: pull.NewSeedHandler(*modelsDir), ReadHeaderTimeout: 30 * time.Second}
+	g, ctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+	g.Go(func() error {
+		<-ctx.Done()
+		return srv.Shutdown(context.Background())
+	})
+	setupLog.Info("serving the blobs to the peers", "address", *bindAddr)
+	return g.Wait()
+}
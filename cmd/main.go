@@ -20,19 +20,28 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"path/filepath"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/yaml"
 
 	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
 	"github.com/sivchari/ollama-operator/internal/controller"
+	webhookv1alpha1 "github.com/sivchari/ollama-operator/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -51,11 +60,17 @@ func init() {
 // nolint:gocyclo
 func main() {
 	var metricsAddr string
+	var webhookCertPath, webhookCertName, webhookCertKey string
 	var enableLeaderElection bool
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var ollamaContainerImage string
+	var activatorHost string
+	var agentImage string
+	var registryConfig string
+	var maxConcurrentPulls, maxConcurrentPullsPerNode int
+	var storagePreflight bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -64,10 +79,31 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", true,
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
+	flag.StringVar(&webhookCertPath, "webhook-cert-path", "", "The directory that contains the webhook certificate.")
+	flag.StringVar(&webhookCertName, "webhook-cert-name", "tls.crt", "The name of the webhook certificate file.")
+	flag.StringVar(&webhookCertKey, "webhook-cert-key", "tls.key", "The name of the webhook key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.StringVar(&ollamaContainerImage, "ollama-container-image", "ollama/ollama:latest",
-		"The container image to use for the Ollama server. This is used to create the Ollama server.")
+		"The container image to use for the Ollama server. This is used by the Models which do not set spec.runtime.image.")
+	flag.StringVar(&activatorHost, "activator-host", "",
+		"The DNS name of the activator Service. The Service of an idle Model points to it, "+
+			"so a request wakes up the Model. If empty, idle Models are only woken up by the last-request-time annotation.")
+	flag.StringVar(&agentImage, "agent-image", os.Getenv("AGENT_IMAGE"),
+		"The container image of the agent injected in the Model pods, e.g. as the proxy measuring the load for autoscaling. "+
+			"Defaults to the AGENT_IMAGE environment variable.")
+	flag.StringVar(&registryConfig, "registry-config", "",
+		"The path of the YAML file of the registry configuration applied to the pulls of all Models and ModelCaches, "+
+			"in the form of spec.registry of Model. The Secrets of the credentials are looked up in the namespace of each Model.")
+	flag.IntVar(&maxConcurrentPulls, "max-concurrent-pulls", 0,
+		"The maximum number of the pods of the Models and the ModelCaches pulling images at once in the cluster. "+
+			"The other pods are queued. 0 means unlimited.")
+	flag.IntVar(&maxConcurrentPullsPerNode, "max-concurrent-pulls-per-node", 0,
+		"The maximum number of the pods of the Models and the ModelCaches pulling images at once on a node. "+
+			"The other pods are queued. 0 means unlimited.")
+	flag.BoolVar(&storagePreflight, "storage-preflight", true,
+		"If set, the download size of the images of the Models is resolved from the registries before the pods are created. "+
+			"It is requested as the ephemeral storage of the pods, and no pod is created if it exceeds the storage of the Model.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -86,6 +122,35 @@ func main() {
 		tlsOpts = append(tlsOpts, disableHTTP2)
 	}
 
+	// Create a watcher for the webhook certificates
+	var webhookCertWatcher *certwatcher.CertWatcher
+
+	// Initial webhook TLS options
+	webhookTLSOpts := tlsOpts
+
+	if len(webhookCertPath) > 0 {
+		setupLog.Info("Initializing webhook certificate watcher using provided certificates",
+			"webhook-cert-path", webhookCertPath, "webhook-cert-name", webhookCertName, "webhook-cert-key", webhookCertKey)
+
+		var err error
+		webhookCertWatcher, err = certwatcher.New(
+			filepath.Join(webhookCertPath, webhookCertName),
+			filepath.Join(webhookCertPath, webhookCertKey),
+		)
+		if err != nil {
+			setupLog.Error(err, "Failed to initialize webhook certificate watcher")
+			os.Exit(1)
+		}
+
+		webhookTLSOpts = append(webhookTLSOpts, func(config *tls.Config) {
+			config.GetCertificate = webhookCertWatcher.GetCertificate
+		})
+	}
+
+	webhookServer := webhook.NewServer(webhook.Options{
+		TLSOpts: webhookTLSOpts,
+	})
+
 	metricsServerOptions := metricsserver.Options{
 		BindAddress:   metricsAddr,
 		SecureServing: secureMetrics,
@@ -96,9 +161,24 @@ func main() {
 		metricsServerOptions.FilterProvider = filters.WithAuthenticationAndAuthorization
 	}
 
+	modelBindingSecrets, err := labels.Parse(ollamav1alpha1.ModelBindingNameLabel)
+	if err != nil {
+		setupLog.Error(err, "unable to parse the label selector of the Secrets")
+		os.Exit(1)
+	}
+	cacheOptions := cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			// Only the Secrets created for the ModelBindings are cached. The registry credentials of the storage
+			// preflight are read by the API reader.
+			&corev1.Secret{}: {Label: modelBindingSecrets},
+		},
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
+		WebhookServer:          webhookServer,
+		Cache:                  cacheOptions,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "dba941a9.sivchari.io",
@@ -108,16 +188,81 @@ func main() {
 		os.Exit(1)
 	}
 
+	defaultRegistry, err := loadRegistryConfig(registryConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to load the registry configuration", "path", registryConfig)
+		os.Exit(1)
+	}
+	// The pulls are queued only if any limit is set, since the queued pods wait for the pull scheduler.
+	pullScheduling := maxConcurrentPulls > 0 || maxConcurrentPullsPerNode > 0
+
 	if err = (&controller.ModelReconciler{
 		Client:               mgr.GetClient(),
 		Scheme:               mgr.GetScheme(),
 		OllamaContainerImage: ollamaContainerImage,
+		ActivatorHost:        activatorHost,
+		AgentImage:           agentImage,
+		OperatorNamespace:    os.Getenv("POD_NAMESPACE"),
+		DefaultRegistry:      defaultRegistry,
+		PullScheduling:       pullScheduling,
+		StoragePreflight:     storagePreflight,
+		APIReader:            mgr.GetAPIReader(),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Model")
 		os.Exit(1)
 	}
+	if err = (&controller.ModelQuotaReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ModelQuota")
+		os.Exit(1)
+	}
+	if err = (&controller.ModelCacheReconciler{
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		OllamaContainerImage: ollamaContainerImage,
+		AgentImage:           agentImage,
+		DefaultRegistry:      defaultRegistry,
+		PullScheduling:       pullScheduling,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ModelCache")
+		os.Exit(1)
+	}
+	if pullScheduling {
+		if err = (&controller.PullSchedulerReconciler{
+			Client:                    mgr.GetClient(),
+			MaxConcurrentPulls:        maxConcurrentPulls,
+			MaxConcurrentPullsPerNode: maxConcurrentPullsPerNode,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "PullScheduler")
+			os.Exit(1)
+		}
+	}
+	if err = (&controller.ModelBindingReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ModelBinding")
+		os.Exit(1)
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookv1alpha1.SetupModelWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Model")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
+	if webhookCertWatcher != nil {
+		setupLog.Info("Adding webhook certificate watcher to manager")
+		if err := mgr.Add(webhookCertWatcher); err != nil {
+			setupLog.Error(err, "unable to add webhook certificate watcher to manager")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -133,3 +278,19 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// loadRegistryConfig reads the registry configuration at path. It returns nil if path is empty.
+func loadRegistryConfig(path string) (*ollamav1alpha1.ModelRegistry, error) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	registry := &ollamav1alpha1.ModelRegistry{}
+	if err := yaml.UnmarshalStrict(b, registry); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package activator implements the activator, which receives the requests sent to the Models scaled to zero,
+// wakes them up and forwards the requests once they are ready.
+package activator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+	"github.com/sivchari/ollama-operator/internal/podutil"
+)
+
+const (
+	defaultTimeout      = 5 * time.Minute
+	defaultPollInterval = 500 * time.Millisecond
+	// wakeInterval is the minimum interval to update the last request time of a Model.
+	wakeInterval = 10 * time.Second
+)
+
+var errNotFound = errors.New("model not found")
+
+// Activator is a http.Handler which resolves the idle Model from the Host header of a request,
+// wakes it up, waits for a ready pod and forwards the request to it.
+type Activator struct {
+	client.Client
+	// Timeout is the maximum duration a request waits for the Model to become ready.
+	Timeout time.Duration
+	// PollInterval is the interval to check whether the Model is ready.
+	PollInterval time.Duration
+	// Port is the port the ollama server listens on.
+	Port int
+}
+
+func (a *Activator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := ctrl.LoggerFrom(ctx).WithValues("host", r.Host)
+
+	model, err := a.resolve(ctx, r.Host)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Error(err, "unable to resolve the Model")
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if model.Spec.Paused != nil && *model.Spec.Paused {
+		http.Error(w, "model is paused", http.StatusServiceUnavailable)
+		return
+	}
+	if err := a.wake(ctx, model); err != nil {
+		log.Error(err, "unable to wake up the Model", "model", client.ObjectKeyFromObject(model))
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	pod, err := a.waitForPod(ctx, model)
+	if err != nil {
+		http.Error(w, "model is not ready: "+err.Error(), http.StatusGatewayTimeout)
+		return
+	}
+
+	target := &url.URL{
+		Scheme: "http",
+		Host:   net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(a.port())),
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.FlushInterval = -1
+	proxy.ServeHTTP(w, r)
+}
+
+// resolve returns the Model from host, which is the DNS name of the Model's Service in the form of
+// name.namespace.svc, optionally followed by the cluster domain. The Model is resolved only if it is scaled to zero
+// while idle, and its Service is routed through the activator, so a client cannot wake up or reach the other Models
+// by naming them in the Host header. The Model which has just been woken up is still resolved, since the clients
+// may hold the DNS record of the Service pointing at the activator for a while.
+func (a *Activator) resolve(ctx context.Context, host string) (*ollamav1alpha1.Model, error) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) < 3 || labels[2] != "svc" {
+		return nil, fmt.Errorf("%w, use name.namespace.svc as the host", errNotFound)
+	}
+	key := client.ObjectKey{Namespace: labels[1], Name: labels[0]}
+	model := &ollamav1alpha1.Model{}
+	if err := a.Get(ctx, key, model); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+	svc := &corev1.Service{}
+	if err := a.Get(ctx, key, svc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errNotFound
+		}
+		return nil, err
+	}
+	// The operator turns the Service of an idle Model into an ExternalName Service for the activator.
+	if !metav1.IsControlledBy(svc, model) || (svc.Spec.Type != corev1.ServiceTypeExternalName && model.Spec.Idle == nil) {
+		return nil, errNotFound
+	}
+	return model, nil
+}
+
+// wake records the last request time on the Model, which makes the operator scale it up.
+func (a *Activator) wake(ctx context.Context, model *ollamav1alpha1.Model) error {
+	now := time.Now()
+	last, err := time.Parse(time.RFC3339Nano, model.Annotations[ollamav1alpha1.LastRequestTimeAnnotation])
+	if err == nil && now.Sub(last) < wakeInterval {
+		return nil
+	}
+	patch := client.MergeFrom(model.DeepCopy())
+	if model.Annotations == nil {
+		model.Annotations = map[string]string{}
+	}
+	model.Annotations[ollamav1alpha1.LastRequestTimeAnnotation] = now.UTC().Format(time.RFC3339Nano)
+	return a.Patch(ctx, model, patch)
+}
+
+// waitForPod waits until the Model has a ready pod.
+func (a *Activator) waitForPod(ctx context.Context, model *ollamav1alpha1.Model) (*corev1.Pod, error) {
+	timeout := a.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	interval := a.PollInterval
+	if interval == 0 {
+		interval = defaultPollInterval
+	}
+	var ready *corev1.Pod
+	err := wait.PollUntilContextTimeout(ctx, interval, timeout, true, func(ctx context.Context) (bool, error) {
+		pods := &corev1.PodList{}
+		if err := a.List(ctx, pods, client.InNamespace(model.Namespace), client.MatchingLabels{ollamav1alpha1.ModelNameLabel: model.Name}); err != nil {
+			return false, err
+		}
+		for i := range pods.Items {
+			if podutil.IsReady(&pods.Items[i]) {
+				ready = &pods.Items[i]
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	return ready, err
+}
+
+func (a *Activator) port() int {
+	if a.Port == 0 {
+		return ollama.DefaultPort
+	}
+	return a.Port
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package activator
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := ollamav1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func newModel(namespace, name string) *ollamav1alpha1.Model {
+	return &ollamav1alpha1.Model{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: types.UID(namespace + "-" + name)},
+		Spec:       ollamav1alpha1.ModelSpec{Images: []string{"llama3"}},
+	}
+}
+
+func newReadyPod(model *ollamav1alpha1.Model, ip string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: model.Namespace,
+			Name:      model.Name + "-abcde",
+			Labels:    map[string]string{ollamav1alpha1.ModelNameLabel: model.Name},
+		},
+		Status: corev1.PodStatus{
+			PodIP:      ip,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+// newIdleService returns the Service of model the operator points at the activator while model is idle.
+func newIdleService(model *ollamav1alpha1.Model) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: model.Namespace,
+			Name:      model.Name,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: ollamav1alpha1.GroupVersion.String(),
+				Kind:       "Model",
+				Name:       model.Name,
+				UID:        model.UID,
+				Controller: ptr.To(true),
+			}},
+		},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: "ollama-operator-activator.ollama-operator-system.svc.cluster.local",
+		},
+	}
+}
+
+func TestActivator(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "served "+r.URL.Path)
+	}))
+	t.Cleanup(backend.Close)
+	backendURL, err := url.Parse(backend.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, portStr, err := net.SplitHostPort(backendURL.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Should wake up the Model and forward the request once it is ready", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel("default", "llama")
+		c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(model, newIdleService(model)).Build()
+		a := &Activator{Client: c, Port: port, PollInterval: 10 * time.Millisecond, Timeout: 5 * time.Second}
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			_ = c.Create(context.Background(), newReadyPod(model, host))
+		}()
+
+		req := httptest.NewRequest(http.MethodPost, "http://llama.default.svc.cluster.local:11434/api/chat", nil)
+		rec := httptest.NewRecorder()
+		a.ServeHTTP(rec, req)
+		g.Expect(rec.Code).To(Equal(http.StatusOK))
+		g.Expect(rec.Body.String()).To(Equal("served /api/chat"))
+
+		woken := &ollamav1alpha1.Model{}
+		g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(model), woken)).To(Succeed())
+		g.Expect(woken.Annotations).To(HaveKey(ollamav1alpha1.LastRequestTimeAnnotation))
+	})
+
+	t.Run("Should resolve the Model by the name of the Service without the cluster domain", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel("team-a", "mistral")
+		c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(model, newIdleService(model), newReadyPod(model, host)).Build()
+		a := &Activator{Client: c, Port: port}
+
+		req := httptest.NewRequest(http.MethodGet, "http://mistral.team-a.svc:11434/api/tags", nil)
+		rec := httptest.NewRecorder()
+		a.ServeHTTP(rec, req)
+		g.Expect(rec.Code).To(Equal(http.StatusOK))
+		g.Expect(rec.Body.String()).To(Equal("served /api/tags"))
+	})
+
+	t.Run("Should reject the host which does not contain the namespace", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel("team-a", "mistral")
+		c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(model, newIdleService(model), newReadyPod(model, host)).Build()
+		a := &Activator{Client: c, Port: port}
+
+		for _, h := range []string{"mistral:11434", "mistral.team-a:11434"} {
+			req := httptest.NewRequest(http.MethodGet, "http://"+h+"/api/tags", nil)
+			rec := httptest.NewRecorder()
+			a.ServeHTTP(rec, req)
+			g.Expect(rec.Code).To(Equal(http.StatusNotFound), h)
+		}
+		g.Expect(c.Get(context.Background(), client.ObjectKeyFromObject(model), model)).To(Succeed())
+		g.Expect(model.Annotations).NotTo(HaveKey(ollamav1alpha1.LastRequestTimeAnnotation))
+	})
+
+	t.Run("Should forward the request for the Model which has just been woken up", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel("team-a", "woken")
+		model.Spec.Idle = &ollamav1alpha1.ModelIdlePolicy{Timeout: metav1.Duration{Duration: time.Minute}}
+		svc := newIdleService(model)
+		svc.Spec = corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}
+		c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(model, svc, newReadyPod(model, host)).Build()
+		a := &Activator{Client: c, Port: port}
+
+		req := httptest.NewRequest(http.MethodGet, "http://woken.team-a.svc:11434/api/tags", nil)
+		rec := httptest.NewRecorder()
+		a.ServeHTTP(rec, req)
+		g.Expect(rec.Code).To(Equal(http.StatusOK))
+	})
+
+	t.Run("Should reject the request for a Model whose Service does not point at the activator", func(t *testing.T) {
+		g := NewWithT(t)
+		awake := newModel("team-b", "awake")
+		svc := newIdleService(awake)
+		svc.Spec = corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}
+		foreign := newModel("team-b", "foreign")
+		foreignSvc := newIdleService(foreign)
+		foreignSvc.OwnerReferences = nil
+		c := fake.NewClientBuilder().WithScheme(newScheme(t)).
+			WithObjects(awake, svc, newReadyPod(awake, host), foreign, foreignSvc, newReadyPod(foreign, host)).Build()
+		a := &Activator{Client: c, Port: port}
+
+		for _, h := range []string{"awake.team-b.svc.cluster.local", "foreign.team-b.svc.cluster.local"} {
+			req := httptest.NewRequest(http.MethodGet, "http://"+h+":11434/api/tags", nil)
+			rec := httptest.NewRecorder()
+			a.ServeHTTP(rec, req)
+			g.Expect(rec.Code).To(Equal(http.StatusNotFound), h)
+		}
+	})
+
+	t.Run("Should reject the request for an unknown Model", func(t *testing.T) {
+		g := NewWithT(t)
+		c := fake.NewClientBuilder().WithScheme(newScheme(t)).Build()
+		a := &Activator{Client: c, Port: port}
+
+		req := httptest.NewRequest(http.MethodGet, "http://unknown.default.svc.cluster.local:11434/api/tags", nil)
+		rec := httptest.NewRecorder()
+		a.ServeHTTP(rec, req)
+		g.Expect(rec.Code).To(Equal(http.StatusNotFound))
+	})
+
+	t.Run("Should time out when the Model does not become ready", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel("default", "slow")
+		c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(model, newIdleService(model)).Build()
+		a := &Activator{Client: c, Port: port, PollInterval: 10 * time.Millisecond, Timeout: 100 * time.Millisecond}
+
+		req := httptest.NewRequest(http.MethodGet, "http://slow.default.svc:11434/api/tags", nil)
+		rec := httptest.NewRecorder()
+		a.ServeHTTP(rec, req)
+		g.Expect(rec.Code).To(Equal(http.StatusGatewayTimeout))
+	})
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package catalog matches the images of the Models against the ModelCatalogs.
+package catalog
+
+import (
+	"path"
+	"strings"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+)
+
+// Blocked returns the images which are not approved by any of catalogs.
+// If there is no catalog, all images are approved.
+func Blocked(catalogs []ollamav1alpha1.ModelCatalog, images []string) []string {
+	if len(catalogs) == 0 {
+		return nil
+	}
+	var blocked []string
+	for _, image := range images {
+		if !approved(catalogs, image) {
+			blocked = append(blocked, image)
+		}
+	}
+	return blocked
+}
+
+func approved(catalogs []ollamav1alpha1.ModelCatalog, image string) bool {
+	name, tag := splitTag(ollama.NormalizeName(image))
+	for _, catalog := range catalogs {
+		for _, entry := range catalog.Spec.Entries {
+			if matchEntry(entry, name, tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchEntry(entry ollamav1alpha1.ModelCatalogEntry, name, tag string) bool {
+	pattern, _ := splitTag(ollama.NormalizeName(entry.Name))
+	if ok, err := path.Match(pattern, name); err != nil || !ok {
+		return false
+	}
+	if len(entry.Tags) == 0 {
+		return true
+	}
+	for _, t := range entry.Tags {
+		if ok, err := path.Match(t, tag); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTag splits the normalized name into the name and the tag.
+func splitTag(name string) (string, string) {
+	i := strings.LastIndex(name, ":")
+	if i < strings.LastIndex(name, "/") {
+		return name, ""
+	}
+	return name[:i], name[i+1:]
+}
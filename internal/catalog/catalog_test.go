@@ -0,0 +1,77 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestBlocked(t *testing.T) {
+	catalogs := []ollamav1alpha1.ModelCatalog{
+		{
+			Spec: ollamav1alpha1.ModelCatalogSpec{
+				Entries: []ollamav1alpha1.ModelCatalogEntry{
+					{Name: "llama3", Tags: []string{"8b", "8b-instruct-*"}},
+					{Name: "registry.ollama.ai/library/mistral"},
+				},
+			},
+		},
+		{
+			Spec: ollamav1alpha1.ModelCatalogSpec{
+				Entries: []ollamav1alpha1.ModelCatalogEntry{
+					{Name: "hf.co/bartowski/*", Tags: []string{"Q4_K_M"}},
+				},
+			},
+		},
+	}
+	for _, tt := range []struct {
+		name     string
+		catalogs []ollamav1alpha1.ModelCatalog
+		images   []string
+		want     []string
+	}{
+		{
+			name:   "Should approve all images without the catalogs",
+			images: []string{"llama3:70b"},
+		},
+		{
+			name:     "Should approve the images with the approved tags",
+			catalogs: catalogs,
+			images:   []string{"llama3:8b", "registry.ollama.ai/library/llama3:8b-instruct-q4_0", "mistral", "library/mistral:7b"},
+		},
+		{
+			name:     "Should approve the images matching the patterns",
+			catalogs: catalogs,
+			images:   []string{"hf.co/bartowski/Llama-3.2-1B-Instruct-GGUF:Q4_K_M"},
+		},
+		{
+			name:     "Should block the images which are not approved",
+			catalogs: catalogs,
+			images:   []string{"llama3", "llama3:70b", "phi3", "hf.co/bartowski/Llama-3.2-1B-Instruct-GGUF", "hf.co/other/model:Q4_K_M"},
+			want:     []string{"llama3", "llama3:70b", "phi3", "hf.co/bartowski/Llama-3.2-1B-Instruct-GGUF", "hf.co/other/model:Q4_K_M"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(Blocked(tt.catalogs, tt.images)).To(Equal(tt.want))
+		})
+	}
+}
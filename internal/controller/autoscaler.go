@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/podutil"
+	"github.com/sivchari/ollama-operator/internal/proxy"
+)
+
+const (
+	// autoscalingInterval is the interval to measure the load of the Model.
+	autoscalingInterval   = 15 * time.Second
+	defaultScaleDownDelay = 5 * time.Minute
+)
+
+// reconcileAutoscaling adjusts spec.replicas of the Model from the load reported by the proxies of the serving pods.
+// It returns the duration after which the load has to be measured again.
+func (r *ModelReconciler) reconcileAutoscaling(ctx context.Context, model *ollamav1alpha1.Model) (time.Duration, error) {
+	policy := model.Spec.Autoscaling
+	if policy == nil {
+		model.Status.Load = nil
+		model.Status.LastScaleTime = nil
+		modelDesiredReplicas.DeleteLabelValues(model.Namespace, model.Name)
+		return 0, nil
+	}
+	pods, err := r.listPods(ctx, model)
+	if err != nil {
+		return 0, err
+	}
+	load := &ollamav1alpha1.ModelLoad{}
+	measured := true
+	for _, pod := range pods {
+		if !podutil.IsReady(pod) {
+			continue
+		}
+		l, err := proxy.ReadLoad(ctx, proxyMetricsURL(pod))
+		if err != nil {
+			ctrl.LoggerFrom(ctx).V(1).Error(err, "unable to read the load", "pod", pod.Name)
+			measured = false
+			continue
+		}
+		load.InFlightRequests += l.InFlightRequests
+		load.QueuedRequests += l.QueuedRequests
+	}
+	model.Status.Load = load
+
+	current := ptr.Deref(model.Spec.Replicas, 1)
+	desired := desiredReplicas(policy, load)
+	modelDesiredReplicas.WithLabelValues(model.Namespace, model.Name).Set(float64(desired))
+
+	now := time.Now()
+	switch {
+	case desired > current:
+	case desired < current:
+		// The replicas are decreased only when the load of all pods is known and has stayed low for the delay.
+		delay := defaultScaleDownDelay
+		if policy.ScaleDownDelay != nil {
+			delay = policy.ScaleDownDelay.Duration
+		}
+		minReplicas := ptr.Deref(policy.MinReplicas, 1)
+		if current <= policy.MaxReplicas && current >= minReplicas {
+			if !measured {
+				return autoscalingInterval, nil
+			}
+			if model.Status.LastScaleTime != nil && now.Sub(model.Status.LastScaleTime.Time) < delay {
+				return min(autoscalingInterval, delay-now.Sub(model.Status.LastScaleTime.Time)), nil
+			}
+		}
+	default:
+		return autoscalingInterval, nil
+	}
+	ctrl.LoggerFrom(ctx).Info("scaling the Model", "from", current, "to", desired, "inFlightRequests", load.InFlightRequests, "queuedRequests", load.QueuedRequests)
+	// The replicas are patched on a copy, since the response would reset the status of the Model which is not patched yet.
+	scaled := model.DeepCopy()
+	patch := client.MergeFrom(scaled.DeepCopy())
+	scaled.Spec.Replicas = ptr.To(desired)
+	if err := r.Patch(ctx, scaled, patch); err != nil {
+		return 0, fmt.Errorf("unable to update the replicas of the Model: %w", err)
+	}
+	model.Spec.Replicas = ptr.To(desired)
+	model.Status.LastScaleTime = &metav1.Time{Time: now}
+	return autoscalingInterval, nil
+}
+
+// desiredReplicas returns the replicas which keep the load per replica under the target within the bounds.
+func desiredReplicas(policy *ollamav1alpha1.ModelAutoscaling, load *ollamav1alpha1.ModelLoad) int32 {
+	requests := load.InFlightRequests + load.QueuedRequests
+	desired := (requests + policy.TargetRequestsPerReplica - 1) / policy.TargetRequestsPerReplica
+	return min(max(desired, ptr.Deref(policy.MinReplicas, 1)), policy.MaxReplicas)
+}
+
+func proxyMetricsURL(pod *corev1.Pod) string {
+	return "http://" + net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(proxy.MetricsPort)) + "/metrics"
+}
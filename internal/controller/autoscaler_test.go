@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestDesiredReplicas(t *testing.T) {
+	policy := &ollamav1alpha1.ModelAutoscaling{
+		MinReplicas:              ptr.To[int32](2),
+		MaxReplicas:              5,
+		TargetRequestsPerReplica: 4,
+	}
+	for _, tt := range []struct {
+		name string
+		load ollamav1alpha1.ModelLoad
+		want int32
+	}{
+		{name: "Should keep the min replicas without load", want: 2},
+		{name: "Should round up the replicas", load: ollamav1alpha1.ModelLoad{InFlightRequests: 9}, want: 3},
+		{name: "Should count the queued requests", load: ollamav1alpha1.ModelLoad{InFlightRequests: 8, QueuedRequests: 8}, want: 4},
+		{name: "Should not exceed the max replicas", load: ollamav1alpha1.ModelLoad{InFlightRequests: 100}, want: 5},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(desiredReplicas(policy, &tt.load)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestReconcileAutoscaling(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	NewWithT(t).Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	NewWithT(t).Expect(ollamav1alpha1.AddToScheme(scheme)).To(Succeed())
+	newModel := func(replicas int32) *ollamav1alpha1.Model {
+		return &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{Name: "model", Namespace: "default"},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images:      []string{"llama3"},
+				Replicas:    ptr.To(replicas),
+				Autoscaling: &ollamav1alpha1.ModelAutoscaling{MaxReplicas: 5, TargetRequestsPerReplica: 4},
+			},
+		}
+	}
+	newReconciler := func(model *ollamav1alpha1.Model, patchErr error) (*ModelReconciler, *int) {
+		patches := 0
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(model).WithStatusSubresource(model).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					patches++
+					if patchErr != nil {
+						return patchErr
+					}
+					return c.Patch(ctx, obj, patch, opts...)
+				},
+			}).Build()
+		return &ModelReconciler{Client: c, Scheme: scheme}, &patches
+	}
+
+	t.Run("Should patch the replicas scaled by the autoscaler", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel(3)
+		r, patches := newReconciler(model.DeepCopy(), nil)
+		_, err := r.reconcileAutoscaling(ctx, model)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(*patches).To(Equal(1))
+		g.Expect(model.Spec.Replicas).To(Equal(ptr.To[int32](1)))
+		g.Expect(model.Status.LastScaleTime).NotTo(BeNil())
+		g.Expect(model.Status.Load).NotTo(BeNil())
+
+		stored := &ollamav1alpha1.Model{}
+		g.Expect(r.Get(ctx, client.ObjectKeyFromObject(model), stored)).To(Succeed())
+		g.Expect(stored.Spec.Replicas).To(Equal(ptr.To[int32](1)))
+	})
+
+	t.Run("Should not patch the Model when the replicas are kept", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel(1)
+		r, patches := newReconciler(model.DeepCopy(), nil)
+		_, err := r.reconcileAutoscaling(ctx, model)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(*patches).To(BeZero())
+	})
+
+	t.Run("Should return the error of the patch of the replicas", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel(3)
+		r, _ := newReconciler(model.DeepCopy(), errors.New("conflict"))
+		_, err := r.reconcileAutoscaling(ctx, model)
+		g.Expect(err).To(MatchError(ContainSubstring("unable to update the replicas of the Model: conflict")))
+		g.Expect(model.Spec.Replicas).To(Equal(ptr.To[int32](3)))
+		g.Expect(model.Status.LastScaleTime).To(BeNil())
+	})
+}
+
+func TestModelToPodWithAutoscaling(t *testing.T) {
+	r := &ModelReconciler{OllamaContainerImage: "ollama/ollama:latest", AgentImage: "ollama-operator:latest"}
+
+	t.Run("Should put the proxy in front of the ollama server", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{Name: "model", Namespace: "default"},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+				Autoscaling: &ollamav1alpha1.ModelAutoscaling{
+					MaxReplicas:              3,
+					TargetRequestsPerReplica: 4,
+					Concurrency:              ptr.To[int32](2),
+				},
+			},
+		}
+		pod := &corev1.Pod{}
+		g.Expect(r.modelToPod(model, pod)).To(Succeed())
+		g.Expect(pod.Spec.Containers).To(HaveLen(2))
+
+		server := pod.Spec.Containers[0]
+		g.Expect(server.Name).To(Equal(ollamaServerContainerName))
+		g.Expect(server.Ports).To(BeEmpty())
+		g.Expect(server.Env).To(ContainElement(corev1.EnvVar{Name: "OLLAMA_HOST", Value: "127.0.0.1:11435"}))
+
+		proxy := pod.Spec.Containers[1]
+		g.Expect(proxy.Name).To(Equal(ollamaProxyContainerName))
+		g.Expect(proxy.Image).To(Equal("ollama-operator:latest"))
+		g.Expect(proxy.Args).To(ContainElements("proxy", "--upstream=http://127.0.0.1:11435", "--max-concurrency=2"))
+		g.Expect(proxy.Ports).To(ContainElement(HaveField("ContainerPort", int32(11434))))
+	})
+
+	t.Run("Should not put the proxy without autoscaling", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{Name: "model", Namespace: "default"},
+			Spec:       ollamav1alpha1.ModelSpec{Images: []string{"llama3"}},
+		}
+		pod := &corev1.Pod{}
+		g.Expect(r.modelToPod(model, pod)).To(Succeed())
+		g.Expect(pod.Spec.Containers).To(HaveLen(1))
+		g.Expect(pod.Spec.Containers[0].Name).To(Equal(ollamaServerContainerName))
+	})
+}
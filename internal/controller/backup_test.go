@@ -0,0 +1,64 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestModelToPodWithBackup(t *testing.T) {
+	g := NewWithT(t)
+	r := &ModelReconciler{AgentImage: "agent:latest"}
+	model := &ollamav1alpha1.Model{
+		Spec: ollamav1alpha1.ModelSpec{
+			Images: []string{"llama3"},
+			Backup: &ollamav1alpha1.ModelBackup{
+				Endpoint:              "http://minio.minio:9000",
+				Bucket:                "models",
+				Prefix:                "team-a",
+				CredentialsSecretName: "minio",
+				Insecure:              true,
+			},
+		},
+	}
+	pod := &corev1.Pod{}
+	g.Expect(r.modelToPod(model, pod)).To(Succeed())
+	g.Expect(pod.Spec.InitContainers).To(HaveLen(1))
+	init := pod.Spec.InitContainers[0]
+	g.Expect(init.Name).To(Equal(ollamaPullContainerName))
+	g.Expect(init.Args).To(Equal([]string{
+		"pull",
+		"--models-dir=/root/.ollama/models",
+		"--backup-endpoint=http://minio.minio:9000",
+		"--backup-bucket=models",
+		"--backup-credentials-dir=/etc/ollama-backup",
+		"--backup-prefix=team-a",
+		"--backup-insecure",
+		"--",
+		"llama3",
+	}))
+	g.Expect(init.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: backupCredentialsVolumeName, MountPath: "/etc/ollama-backup", ReadOnly: true}))
+	g.Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
+		Name:         backupCredentialsVolumeName,
+		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "minio"}},
+	}))
+}
@@ -0,0 +1,237 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+	"github.com/sivchari/ollama-operator/internal/registry"
+)
+
+const (
+	// manifestTTL is how long a resolved manifest is reused for the download size, since the tags rarely move.
+	manifestTTL = time.Hour
+	// manifestRetryInterval is how long a failure to resolve a manifest is reused before it is retried.
+	manifestRetryInterval = time.Minute
+)
+
+// manifestFetcher fetches the manifests of the models, which is implemented by registry.Client.
+type manifestFetcher interface {
+	Manifest(ctx context.Context, name ollama.Name) (*registry.Manifest, []byte, error)
+}
+
+// manifestCache holds the blobs of the manifests resolved by the normalized names of the images.
+type manifestCache struct {
+	mu      sync.Mutex
+	entries map[string]manifestEntry
+}
+
+type manifestEntry struct {
+	blobs   []registry.Layer
+	err     error
+	expires time.Time
+}
+
+// reconcileStorage resolves the download size of the images of the Model, and reports whether the serving pods
+// can be created because the images fit their storage. The pods are created if the size cannot be resolved,
+// e.g. the operator has no access to the registries.
+func (r *ModelReconciler) reconcileStorage(ctx context.Context, model *ollamav1alpha1.Model) bool {
+	if !r.StoragePreflight || usesNodeCache(model) || modelCacheName(model) != "" {
+		model.Status.DownloadSize = nil
+		meta.RemoveStatusCondition(&model.Status.Conditions, ollamav1alpha1.ModelConditionStorageSufficient)
+		return true
+	}
+	size, err := r.downloadSize(ctx, model)
+	if err != nil {
+		// The previous size is kept, so the ephemeral storage of the pods does not change while the registries are unreachable.
+		ctrl.LoggerFrom(ctx).V(1).Error(err, "unable to resolve the download size of the images")
+		meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+			Type:               ollamav1alpha1.ModelConditionStorageSufficient,
+			Status:             metav1.ConditionUnknown,
+			Reason:             ollamav1alpha1.DownloadSizeUnknown,
+			Message:            err.Error(),
+			ObservedGeneration: model.Generation,
+		})
+		return true
+	}
+	model.Status.DownloadSize = resource.NewQuantity(size, resource.BinarySI)
+	if capacity, name, ok := storageCapacity(model); ok && model.Status.DownloadSize.Cmp(capacity) > 0 {
+		meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+			Type:   ollamav1alpha1.ModelConditionStorageSufficient,
+			Status: metav1.ConditionFalse,
+			Reason: ollamav1alpha1.InsufficientStorage,
+			Message: fmt.Sprintf("no pod is created because the images need %s but the %s is %s",
+				model.Status.DownloadSize.String(), name, capacity.String()),
+			ObservedGeneration: model.Generation,
+		})
+		return false
+	}
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:               ollamav1alpha1.ModelConditionStorageSufficient,
+		Status:             metav1.ConditionTrue,
+		Reason:             ollamav1alpha1.StorageFits,
+		Message:            fmt.Sprintf("the images need %s", model.Status.DownloadSize.String()),
+		ObservedGeneration: model.Generation,
+	})
+	return true
+}
+
+// storageCapacity returns the capacity of the storage the serving pods pull the images into and its description.
+// The container filesystem is limited only by the ephemeral-storage limit of spec.template.spec.resources.
+func storageCapacity(model *ollamav1alpha1.Model) (resource.Quantity, string, bool) {
+	if storage := modelStorage(model); storage != nil {
+		if storage.Size != nil {
+			return *storage.Size, "size of the storage volume", true
+		}
+		return defaultStorageSize, "default size of the storage volume", true
+	}
+	if model.Spec.Template != nil && model.Spec.Template.Spec != nil && model.Spec.Template.Spec.Resources != nil {
+		if limit, ok := model.Spec.Template.Spec.Resources.Limits[corev1.ResourceEphemeralStorage]; ok {
+			return limit, "ephemeral-storage limit", true
+		}
+	}
+	return resource.Quantity{}, "", false
+}
+
+// requestEphemeralStorage requests the download size of the images as the ephemeral storage of the ollama server
+// if it stores the models in the container filesystem, so the pod is scheduled to a node with the space for them.
+// A larger request of spec.template.spec.resources is kept.
+func requestEphemeralStorage(model *ollamav1alpha1.Model, pod *corev1.Pod) {
+	if model.Status.DownloadSize == nil || modelStorage(model) != nil {
+		return
+	}
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		if c.Name != ollamaServerContainerName {
+			continue
+		}
+		if request, ok := c.Resources.Requests[corev1.ResourceEphemeralStorage]; ok && request.Cmp(*model.Status.DownloadSize) >= 0 {
+			return
+		}
+		requests := corev1.ResourceList{}
+		for name, quantity := range c.Resources.Requests {
+			requests[name] = quantity
+		}
+		requests[corev1.ResourceEphemeralStorage] = *model.Status.DownloadSize
+		c.Resources.Requests = requests
+	}
+}
+
+// downloadSize returns the total size of the blobs of the images pulled from the registries.
+// The images imported from the OCI sources and created from the weights are not counted.
+func (r *ModelReconciler) downloadSize(ctx context.Context, model *ollamav1alpha1.Model) (int64, error) {
+	images := pulledImages(model)
+	sources := ociSources(model.Spec.Sources, images)
+	var fetcher manifestFetcher
+	seen := map[string]bool{}
+	var size int64
+	for _, image := range images {
+		if slices.ContainsFunc(sources, func(s ollamav1alpha1.ModelSource) bool { return s.Image == image }) {
+			continue
+		}
+		name, err := ollama.ParseName(image)
+		if err != nil {
+			return 0, err
+		}
+		blobs, err := r.manifests.get(name, time.Now(), func() ([]registry.Layer, error) {
+			if fetcher == nil {
+				config, err := r.registryConfig(ctx, model.Namespace, mergeRegistry(r.DefaultRegistry, model.Spec.Registry))
+				if err != nil {
+					return nil, err
+				}
+				fetcher = r.manifestFetcher(config)
+			}
+			manifest, _, err := fetcher.Manifest(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			return manifest.Blobs(), nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to resolve the manifest of %s: %w", image, err)
+		}
+		for _, blob := range blobs {
+			if !seen[blob.Digest] {
+				seen[blob.Digest] = true
+				size += blob.Size
+			}
+		}
+	}
+	return size, nil
+}
+
+// registryConfig returns the configuration of the registry client of the operator, reading the credentials
+// from the Secrets in namespace as the agent of the pods does. The Secrets are read by APIReader.
+func (r *ModelReconciler) registryConfig(ctx context.Context, namespace string, reg *ollamav1alpha1.ModelRegistry) (registry.Config, error) {
+	config := registry.Config{Mirrors: map[string]string{}, Hosts: map[string]registry.Host{}}
+	if reg == nil {
+		return config, nil
+	}
+	for _, mirror := range reg.Mirrors {
+		config.Mirrors[mirror.Host] = mirror.Mirror
+	}
+	for _, h := range reg.Hosts {
+		host := registry.Host{Insecure: h.Insecure}
+		if h.AuthSecretName != nil {
+			secret := &corev1.Secret{}
+			if err := r.secretReader().Get(ctx, types.NamespacedName{Namespace: namespace, Name: *h.AuthSecretName}, secret); err != nil {
+				return config, err
+			}
+			host.Username = strings.TrimSpace(string(secret.Data["username"]))
+			host.Password = strings.TrimSpace(string(secret.Data["password"]))
+			host.Token = strings.TrimSpace(string(secret.Data["token"]))
+		}
+		config.Hosts[h.Host] = host
+	}
+	return config, nil
+}
+
+func (r *ModelReconciler) secretReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
+	}
+	return r.Client
+}
+
+func (r *ModelReconciler) manifestFetcher(config registry.Config) manifestFetcher {
+	if r.newManifestFetcher != nil {
+		return r.newManifestFetcher(config)
+	}
+	return registry.NewClient(config)
+}
+
+// get returns the blobs of the manifest of name, resolving it by resolve unless a result is cached.
+func (c *manifestCache) get(name ollama.Name, now time.Time, resolve func() ([]registry.Layer, error)) ([]registry.Layer, error) {
+	key := name.String()
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.blobs, entry.err
+	}
+	blobs, err := resolve()
+	entry = manifestEntry{blobs: blobs, err: err, expires: now.Add(manifestTTL)}
+	if err != nil {
+		entry.expires = now.Add(manifestRetryInterval)
+	}
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = map[string]manifestEntry{}
+	}
+	c.entries[key] = entry
+	c.mu.Unlock()
+	return blobs, err
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+	"github.com/sivchari/ollama-operator/internal/registry"
+)
+
+type fakeManifests struct {
+	config    registry.Config
+	manifests map[string]*registry.Manifest
+	calls     int
+}
+
+func (f *fakeManifests) Manifest(_ context.Context, name ollama.Name) (*registry.Manifest, []byte, error) {
+	f.calls++
+	manifest, ok := f.manifests[name.String()]
+	if !ok {
+		return nil, nil, &registry.StatusError{URL: name.String(), StatusCode: 404}
+	}
+	return manifest, nil, nil
+}
+
+func TestReconcileStorage(t *testing.T) {
+	const gi = 1 << 30
+	newReconciler := func(manifests *fakeManifests, objs ...corev1.Secret) *ModelReconciler {
+		builder := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme)
+		for i := range objs {
+			builder = builder.WithObjects(&objs[i])
+		}
+		apiReader := builder.Build()
+		// The cache of the manager holds only the Secrets created for the ModelBindings.
+		cached := builder.WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if err := c.Get(ctx, key, obj, opts...); err != nil {
+					return err
+				}
+				if _, ok := obj.(*corev1.Secret); ok {
+					if _, ok := obj.GetLabels()[ollamav1alpha1.ModelBindingNameLabel]; !ok {
+						return apierrors.NewNotFound(corev1.Resource("secrets"), key.Name)
+					}
+				}
+				return nil
+			},
+		}).Build()
+		return &ModelReconciler{
+			Client:           cached,
+			APIReader:        apiReader,
+			StoragePreflight: true,
+			newManifestFetcher: func(config registry.Config) manifestFetcher {
+				manifests.config = config
+				return manifests
+			},
+		}
+	}
+	manifests := func() *fakeManifests {
+		shared := registry.Layer{Digest: "sha256:license", Size: gi}
+		return &fakeManifests{manifests: map[string]*registry.Manifest{
+			"registry.ollama.ai/library/llama3:latest": {
+				Config: registry.Layer{Digest: "sha256:llama3-config", Size: 1},
+				Layers: []registry.Layer{{Digest: "sha256:llama3", Size: 40 * gi}, shared},
+			},
+			"registry.ollama.ai/library/phi3:latest": {
+				Config: registry.Layer{Digest: "sha256:phi3-config", Size: 1},
+				Layers: []registry.Layer{{Digest: "sha256:phi3", Size: 8 * gi}, shared},
+			},
+		}}
+	}
+
+	t.Run("Should request the download size as the ephemeral storage", func(t *testing.T) {
+		g := NewWithT(t)
+		r := newReconciler(manifests())
+		model := &ollamav1alpha1.Model{Spec: ollamav1alpha1.ModelSpec{Images: []string{"llama3", "phi3"}}}
+		g.Expect(r.reconcileStorage(context.Background(), model)).To(BeTrue())
+		g.Expect(model.Status.DownloadSize.Value()).To(Equal(int64(49*gi + 2)))
+		g.Expect(meta.IsStatusConditionTrue(model.Status.Conditions, ollamav1alpha1.ModelConditionStorageSufficient)).To(BeTrue())
+
+		pod := &corev1.Pod{}
+		g.Expect(r.modelToPod(model, pod)).To(Succeed())
+		request := pod.Spec.Containers[0].Resources.Requests[corev1.ResourceEphemeralStorage]
+		g.Expect(request.Value()).To(Equal(int64(49*gi + 2)))
+	})
+
+	t.Run("Should not create pods if the images exceed the storage volume", func(t *testing.T) {
+		g := NewWithT(t)
+		r := newReconciler(manifests())
+		model := &ollamav1alpha1.Model{Spec: ollamav1alpha1.ModelSpec{
+			Images: []string{"llama3", "phi3"},
+			Template: &ollamav1alpha1.ModelTemplate{Spec: &ollamav1alpha1.ModelTemplateSpec{
+				Storage: &ollamav1alpha1.ModelStorage{Size: ptr.To(resource.MustParse("40Gi"))},
+			}},
+		}}
+		g.Expect(r.reconcileStorage(context.Background(), model)).To(BeFalse())
+		condition := meta.FindStatusCondition(model.Status.Conditions, ollamav1alpha1.ModelConditionStorageSufficient)
+		g.Expect(condition).NotTo(BeNil())
+		g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		g.Expect(condition.Reason).To(Equal(ollamav1alpha1.InsufficientStorage))
+		g.Expect(condition.Message).To(ContainSubstring("the size of the storage volume is 40Gi"))
+	})
+
+	t.Run("Should not create pods if the images exceed the ephemeral-storage limit", func(t *testing.T) {
+		g := NewWithT(t)
+		r := newReconciler(manifests())
+		model := &ollamav1alpha1.Model{Spec: ollamav1alpha1.ModelSpec{
+			Images: []string{"phi3"},
+			Template: &ollamav1alpha1.ModelTemplate{Spec: &ollamav1alpha1.ModelTemplateSpec{
+				Resources: &corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("8Gi")},
+				},
+			}},
+		}}
+		g.Expect(r.reconcileStorage(context.Background(), model)).To(BeFalse())
+		g.Expect(meta.IsStatusConditionFalse(model.Status.Conditions, ollamav1alpha1.ModelConditionStorageSufficient)).To(BeTrue())
+	})
+
+	t.Run("Should create pods if the download size is unknown", func(t *testing.T) {
+		g := NewWithT(t)
+		r := newReconciler(manifests())
+		model := &ollamav1alpha1.Model{Spec: ollamav1alpha1.ModelSpec{Images: []string{"unknown"}}}
+		g.Expect(r.reconcileStorage(context.Background(), model)).To(BeTrue())
+		condition := meta.FindStatusCondition(model.Status.Conditions, ollamav1alpha1.ModelConditionStorageSufficient)
+		g.Expect(condition).NotTo(BeNil())
+		g.Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+		g.Expect(condition.Reason).To(Equal(ollamav1alpha1.DownloadSizeUnknown))
+	})
+
+	t.Run("Should resolve the manifests with the credentials which are not cached once", func(t *testing.T) {
+		g := NewWithT(t)
+		fetcher := manifests()
+		r := newReconciler(fetcher, corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "default"},
+			Data:       map[string][]byte{"username": []byte("user"), "password": []byte("pass\n")},
+		})
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default"},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+				Registry: &ollamav1alpha1.ModelRegistry{
+					Hosts: []ollamav1alpha1.RegistryHost{{Host: "registry.ollama.ai", AuthSecretName: ptr.To("auth")}},
+				},
+			},
+		}
+		g.Expect(r.reconcileStorage(context.Background(), model)).To(BeTrue())
+		g.Expect(r.reconcileStorage(context.Background(), model)).To(BeTrue())
+		g.Expect(fetcher.calls).To(Equal(1))
+		g.Expect(fetcher.config.Hosts["registry.ollama.ai"]).To(Equal(registry.Host{Username: "user", Password: "pass"}))
+	})
+
+	t.Run("Should skip the preflight for the ModelCache", func(t *testing.T) {
+		g := NewWithT(t)
+		r := newReconciler(manifests())
+		model := &ollamav1alpha1.Model{Spec: ollamav1alpha1.ModelSpec{
+			Images: []string{"llama3"},
+			Template: &ollamav1alpha1.ModelTemplate{Spec: &ollamav1alpha1.ModelTemplateSpec{
+				Storage: &ollamav1alpha1.ModelStorage{CacheName: ptr.To("cache")},
+			}},
+		}}
+		model.Status.DownloadSize = resource.NewQuantity(gi, resource.BinarySI)
+		g.Expect(r.reconcileStorage(context.Background(), model)).To(BeTrue())
+		g.Expect(model.Status.DownloadSize).To(BeNil())
+		g.Expect(meta.FindStatusCondition(model.Status.Conditions, ollamav1alpha1.ModelConditionStorageSufficient)).To(BeNil())
+	})
+}
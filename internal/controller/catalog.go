@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/catalog"
+)
+
+// reconcileCatalog records the images of the Model which are not approved by the ModelCatalogs and returns them.
+// The Models created before a ModelCatalog are not rejected on admission, so the blocked images are not pulled instead.
+func (r *ModelReconciler) reconcileCatalog(ctx context.Context, model *ollamav1alpha1.Model) ([]string, error) {
+	catalogs := &ollamav1alpha1.ModelCatalogList{}
+	if err := r.List(ctx, catalogs); err != nil {
+		return nil, err
+	}
+	blocked := catalog.Blocked(catalogs.Items, model.Spec.Images)
+	model.Status.BlockedImages = blocked
+	if len(catalogs.Items) == 0 {
+		meta.RemoveStatusCondition(&model.Status.Conditions, ollamav1alpha1.ModelConditionApproved)
+		return nil, nil
+	}
+	if len(blocked) > 0 {
+		meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+			Type:               ollamav1alpha1.ModelConditionApproved,
+			Status:             metav1.ConditionFalse,
+			Reason:             ollamav1alpha1.ImagesBlocked,
+			Message:            fmt.Sprintf("images are not approved by any ModelCatalog and are not pulled: %s", strings.Join(blocked, ", ")),
+			ObservedGeneration: model.Generation,
+		})
+		return blocked, nil
+	}
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:               ollamav1alpha1.ModelConditionApproved,
+		Status:             metav1.ConditionTrue,
+		Reason:             ollamav1alpha1.ImagesApproved,
+		ObservedGeneration: model.Generation,
+	})
+	return nil, nil
+}
+
+// modelCatalogToModels maps a ModelCatalog to all Models, since any Model may be approved or blocked by it.
+func (r *ModelReconciler) modelCatalogToModels(ctx context.Context, obj client.Object) []ctrl.Request {
+	models := &ollamav1alpha1.ModelList{}
+	if err := r.List(ctx, models); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "unable to list Models for the ModelCatalog", "modelCatalog", obj.GetName())
+		return nil
+	}
+	requests := make([]ctrl.Request, 0, len(models.Items))
+	for _, model := range models.Items {
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&model)})
+	}
+	return requests
+}
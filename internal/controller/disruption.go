@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+// reconcilePodDisruptionBudget ensures the PodDisruptionBudget which has the same name as the Model
+// and guards its serving pods against the voluntary disruptions.
+// replicas is the number of the serving pods the Model is running with.
+func (r *ModelReconciler) reconcilePodDisruptionBudget(ctx context.Context, model *ollamav1alpha1.Model, replicas int32) error {
+	pdb := &policyv1.PodDisruptionBudget{}
+	pdb.Namespace = model.Namespace
+	pdb.Name = model.Name
+
+	minAvailable, maxUnavailable := disruptionBudget(model, replicas)
+	if minAvailable == nil && maxUnavailable == nil {
+		return r.deleteOwned(ctx, model, pdb)
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, pdb, func() error {
+		if pdb.Labels == nil {
+			pdb.Labels = map[string]string{}
+		}
+		pdb.Labels[ollamav1alpha1.ModelNameLabel] = model.Name
+		pdb.Spec.Selector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{ollamav1alpha1.ModelNameLabel: model.Name},
+		}
+		pdb.Spec.MinAvailable = minAvailable
+		pdb.Spec.MaxUnavailable = maxUnavailable
+		return controllerutil.SetControllerReference(model, pdb, r.Scheme)
+	})
+	return err
+}
+
+// disruptionBudget returns the budget of the PodDisruptionBudget of the Model.
+// It returns nil for both if the Model does not need a PodDisruptionBudget.
+func disruptionBudget(model *ollamav1alpha1.Model, replicas int32) (minAvailable, maxUnavailable *intstr.IntOrString) {
+	if replicas == 0 {
+		return nil, nil
+	}
+	if policy := model.Spec.Disruption; policy != nil && (policy.MinAvailable != nil || policy.MaxUnavailable != nil) {
+		return policy.MinAvailable, policy.MaxUnavailable
+	}
+	if replicas == 1 {
+		return nil, nil
+	}
+	return nil, &intstr.IntOrString{Type: intstr.Int, IntVal: 1}
+}
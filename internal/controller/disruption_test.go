@@ -0,0 +1,65 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestDisruptionBudget(t *testing.T) {
+	for _, tt := range []struct {
+		name               string
+		disruption         *ollamav1alpha1.ModelDisruptionPolicy
+		replicas           int32
+		wantMinAvailable   *intstr.IntOrString
+		wantMaxUnavailable *intstr.IntOrString
+	}{
+		{
+			name:     "Should not protect a single replica by default",
+			replicas: 1,
+		},
+		{
+			name:               "Should disrupt one replica at once by default",
+			replicas:           3,
+			wantMaxUnavailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1},
+		},
+		{
+			name:             "Should use the disruption policy",
+			disruption:       &ollamav1alpha1.ModelDisruptionPolicy{MinAvailable: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"}},
+			replicas:         1,
+			wantMinAvailable: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"},
+		},
+		{
+			name:       "Should not protect the Model which is scaled to zero",
+			disruption: &ollamav1alpha1.ModelDisruptionPolicy{MinAvailable: &intstr.IntOrString{Type: intstr.Int, IntVal: 1}},
+			replicas:   0,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			model := &ollamav1alpha1.Model{Spec: ollamav1alpha1.ModelSpec{Disruption: tt.disruption}}
+			minAvailable, maxUnavailable := disruptionBudget(model, tt.replicas)
+			g.Expect(minAvailable).To(Equal(tt.wantMinAvailable))
+			g.Expect(maxUnavailable).To(Equal(tt.wantMaxUnavailable))
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"maps"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+)
+
+// httpRouteGVK is the kind of the Gateway API HTTPRoute.
+// It is handled as unstructured, so the operator works on the clusters which do not install the Gateway API.
+var httpRouteGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1", Kind: "HTTPRoute"}
+
+// httpRouteInstalled returns whether the Gateway API HTTPRoute is served by the cluster.
+func httpRouteInstalled(mapper meta.RESTMapper) (bool, error) {
+	if _, err := mapper.RESTMapping(httpRouteGVK.GroupKind(), httpRouteGVK.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// reconcileExpose ensures the Ingress or the HTTPRoute which has the same name as the Model
+// and routes the requests from spec.expose to the Model's Service. The external URL is recorded in the status.
+func (r *ModelReconciler) reconcileExpose(ctx context.Context, model *ollamav1alpha1.Model) error {
+	expose := model.Spec.Expose
+	ing := &networkingv1.Ingress{}
+	ing.Namespace = model.Namespace
+	ing.Name = model.Name
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(httpRouteGVK)
+	route.SetNamespace(model.Namespace)
+	route.SetName(model.Name)
+
+	switch {
+	case expose == nil:
+		if err := r.deleteOwned(ctx, model, ing); err != nil {
+			return err
+		}
+		if err := r.deleteOwned(ctx, model, route); err != nil && !meta.IsNoMatchError(err) {
+			return err
+		}
+		model.Status.URL = ""
+		return nil
+	case expose.HTTPRoute != nil:
+		if err := r.deleteOwned(ctx, model, ing); err != nil {
+			return err
+		}
+		if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, route, func() error {
+			labels := route.GetLabels()
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			labels[ollamav1alpha1.ModelNameLabel] = model.Name
+			route.SetLabels(labels)
+			route.Object["spec"] = httpRouteSpec(model)
+			return controllerutil.SetControllerReference(model, route, r.Scheme)
+		}); err != nil {
+			return err
+		}
+	default:
+		if err := r.deleteOwned(ctx, model, route); err != nil && !meta.IsNoMatchError(err) {
+			return err
+		}
+		if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, ing, func() error {
+			if ing.Labels == nil {
+				ing.Labels = map[string]string{}
+			}
+			ing.Labels[ollamav1alpha1.ModelNameLabel] = model.Name
+			if expose.Ingress != nil {
+				ing.Annotations = maps.Clone(expose.Ingress.Annotations)
+			} else {
+				ing.Annotations = nil
+			}
+			ing.Spec = ingressSpec(model)
+			return controllerutil.SetControllerReference(model, ing, r.Scheme)
+		}); err != nil {
+			return err
+		}
+	}
+	model.Status.URL = exposedURL(expose)
+	return nil
+}
+
+func ingressSpec(model *ollamav1alpha1.Model) networkingv1.IngressSpec {
+	expose := model.Spec.Expose
+	pathType := networkingv1.PathTypePrefix
+	spec := networkingv1.IngressSpec{
+		Rules: []networkingv1.IngressRule{
+			{
+				Host: expose.Host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{
+							{
+								Path:     exposedPath(expose),
+								PathType: &pathType,
+								Backend: networkingv1.IngressBackend{
+									Service: &networkingv1.IngressServiceBackend{
+										Name: model.Name,
+										Port: networkingv1.ServiceBackendPort{Number: ollama.DefaultPort},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if expose.Ingress != nil {
+		spec.IngressClassName = expose.Ingress.ClassName
+	}
+	if expose.TLS != nil {
+		spec.TLS = []networkingv1.IngressTLS{
+			{Hosts: []string{expose.Host}, SecretName: expose.TLS.SecretName},
+		}
+	}
+	return spec
+}
+
+// httpRouteSpec returns the spec of the HTTPRoute of the Model.
+// The fields defaulted by the Gateway API are set explicitly, so the HTTPRoute is not updated on every reconciliation.
+func httpRouteSpec(model *ollamav1alpha1.Model) map[string]any {
+	expose := model.Spec.Expose
+	parentRefs := make([]any, 0, len(expose.HTTPRoute.ParentRefs))
+	for _, ref := range expose.HTTPRoute.ParentRefs {
+		parentRef := map[string]any{
+			"group": httpRouteGVK.Group,
+			"kind":  "Gateway",
+			"name":  ref.Name,
+		}
+		if ref.Namespace != "" {
+			parentRef["namespace"] = ref.Namespace
+		}
+		if ref.SectionName != "" {
+			parentRef["sectionName"] = ref.SectionName
+		}
+		parentRefs = append(parentRefs, parentRef)
+	}
+	return map[string]any{
+		"parentRefs": parentRefs,
+		"hostnames":  []any{expose.Host},
+		"rules": []any{
+			map[string]any{
+				"matches": []any{
+					map[string]any{
+						"path": map[string]any{
+							"type":  "PathPrefix",
+							"value": exposedPath(expose),
+						},
+					},
+				},
+				"backendRefs": []any{
+					map[string]any{
+						"group":  "",
+						"kind":   "Service",
+						"name":   model.Name,
+						"port":   int64(ollama.DefaultPort),
+						"weight": int64(1),
+					},
+				},
+			},
+		},
+	}
+}
+
+func exposedPath(expose *ollamav1alpha1.ModelExpose) string {
+	if expose.Path == "" {
+		return "/"
+	}
+	return expose.Path
+}
+
+func exposedURL(expose *ollamav1alpha1.ModelExpose) string {
+	scheme := "http"
+	if expose.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + expose.Host + exposedPath(expose)
+}
+
+// deleteOwned deletes obj if it exists and is owned by the Model.
+func (r *ModelReconciler) deleteOwned(ctx context.Context, model *ollamav1alpha1.Model, obj client.Object) error {
+	if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !isOwnedBy(obj, model) {
+		return nil
+	}
+	return client.IgnoreNotFound(r.Delete(ctx, obj))
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestExpose(t *testing.T) {
+	newModel := func(expose *ollamav1alpha1.ModelExpose) *ollamav1alpha1.Model {
+		return &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{Name: "model", Namespace: "default"},
+			Spec:       ollamav1alpha1.ModelSpec{Expose: expose},
+		}
+	}
+
+	t.Run("Should route the host and the path to the Service of the Model with the Ingress", func(t *testing.T) {
+		g := NewWithT(t)
+		expose := &ollamav1alpha1.ModelExpose{
+			Host:    "llama.example.com",
+			Path:    "/v1",
+			TLS:     &ollamav1alpha1.ModelExposeTLS{SecretName: "llama-tls"},
+			Ingress: &ollamav1alpha1.ModelExposeIngress{ClassName: ptr.To("nginx")},
+		}
+		spec := ingressSpec(newModel(expose))
+		g.Expect(spec.IngressClassName).To(Equal(ptr.To("nginx")))
+		g.Expect(spec.TLS).To(ConsistOf(networkingv1.IngressTLS{Hosts: []string{"llama.example.com"}, SecretName: "llama-tls"}))
+		g.Expect(spec.Rules).To(HaveLen(1))
+		g.Expect(spec.Rules[0].Host).To(Equal("llama.example.com"))
+		path := spec.Rules[0].HTTP.Paths[0]
+		g.Expect(path.Path).To(Equal("/v1"))
+		g.Expect(path.PathType).To(Equal(ptr.To(networkingv1.PathTypePrefix)))
+		g.Expect(path.Backend.Service.Name).To(Equal("model"))
+		g.Expect(path.Backend.Service.Port.Number).To(Equal(int32(11434)))
+		g.Expect(exposedURL(expose)).To(Equal("https://llama.example.com/v1"))
+	})
+
+	t.Run("Should route the host and the path to the Service of the Model with the HTTPRoute", func(t *testing.T) {
+		g := NewWithT(t)
+		expose := &ollamav1alpha1.ModelExpose{
+			Host: "llama.example.com",
+			HTTPRoute: &ollamav1alpha1.ModelExposeHTTPRoute{
+				ParentRefs: []ollamav1alpha1.ModelGatewayReference{{Name: "gateway", Namespace: "infra"}},
+			},
+		}
+		spec := httpRouteSpec(newModel(expose))
+		g.Expect(spec["hostnames"]).To(Equal([]any{"llama.example.com"}))
+		g.Expect(spec["parentRefs"]).To(ConsistOf(map[string]any{
+			"group":     "gateway.networking.k8s.io",
+			"kind":      "Gateway",
+			"name":      "gateway",
+			"namespace": "infra",
+		}))
+		rule := spec["rules"].([]any)[0].(map[string]any)
+		g.Expect(rule["matches"]).To(ConsistOf(map[string]any{
+			"path": map[string]any{"type": "PathPrefix", "value": "/"},
+		}))
+		g.Expect(rule["backendRefs"]).To(ConsistOf(HaveKeyWithValue("name", "model")))
+		g.Expect(exposedURL(expose)).To(Equal("http://llama.example.com/"))
+	})
+	t.Run("Should watch the HTTPRoutes only if the Gateway API is installed", func(t *testing.T) {
+		g := NewWithT(t)
+		mapper := meta.NewDefaultRESTMapper(nil)
+		installed, err := httpRouteInstalled(mapper)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(installed).To(BeFalse())
+
+		mapper.Add(httpRouteGVK, meta.RESTScopeNamespace)
+		installed, err = httpRouteInstalled(mapper)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(installed).To(BeTrue())
+	})
+}
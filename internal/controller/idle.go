@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+	"github.com/sivchari/ollama-operator/internal/podutil"
+)
+
+// idlePollInterval is the maximum interval to check whether the Model is idle.
+const idlePollInterval = 30 * time.Second
+
+// reconcileIdle reports whether the serving pods of the Model have to be scaled to zero because it is idle.
+// It also returns the duration after which the activity of the Model has to be checked again.
+func (r *ModelReconciler) reconcileIdle(ctx context.Context, model *ollamav1alpha1.Model) (bool, time.Duration) {
+	if model.Spec.Idle == nil {
+		meta.RemoveStatusCondition(&model.Status.Conditions, ollamav1alpha1.ModelConditionIdle)
+		return false, 0
+	}
+	now := time.Now()
+	timeout := model.Spec.Idle.Timeout.Duration
+	lastRequest := lastRequestTime(model)
+
+	if condition := meta.FindStatusCondition(model.Status.Conditions, ollamav1alpha1.ModelConditionIdle); condition != nil && condition.Status == metav1.ConditionTrue {
+		if lastRequest.IsZero() || lastRequest.Before(condition.LastTransitionTime.Time) {
+			return true, 0
+		}
+		model.Status.LastActiveTime = &metav1.Time{Time: now}
+		meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+			Type:               ollamav1alpha1.ModelConditionIdle,
+			Status:             metav1.ConditionFalse,
+			Reason:             ollamav1alpha1.ModelActive,
+			Message:            "woken up by a request",
+			ObservedGeneration: model.Generation,
+		})
+		return false, min(timeout, idlePollInterval)
+	}
+
+	// lastActiveTime is updated at most once per poll interval unless the Model is about to be scaled to zero,
+	// so the status does not change on every reconciliation.
+	if model.Status.LastActiveTime == nil {
+		model.Status.LastActiveTime = &metav1.Time{Time: now}
+	}
+	if elapsed := now.Sub(model.Status.LastActiveTime.Time); (elapsed >= idlePollInterval || elapsed >= timeout) && r.isActive(ctx, model) {
+		model.Status.LastActiveTime = &metav1.Time{Time: now}
+	}
+	if lastRequest.After(model.Status.LastActiveTime.Time) {
+		model.Status.LastActiveTime = &metav1.Time{Time: lastRequest}
+	}
+	idleFor := now.Sub(model.Status.LastActiveTime.Time)
+	if idleFor >= timeout {
+		meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+			Type:               ollamav1alpha1.ModelConditionIdle,
+			Status:             metav1.ConditionTrue,
+			Reason:             ollamav1alpha1.ModelScaledToZero,
+			Message:            fmt.Sprintf("serving pods are scaled to zero because no requests are served for %s", timeout),
+			ObservedGeneration: model.Generation,
+		})
+		return true, 0
+	}
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:               ollamav1alpha1.ModelConditionIdle,
+		Status:             metav1.ConditionFalse,
+		Reason:             ollamav1alpha1.ModelActive,
+		ObservedGeneration: model.Generation,
+	})
+	return false, min(timeout-idleFor, idlePollInterval)
+}
+
+// isActive reports whether the Model is serving requests, that is, an ollama server has a model loaded in memory.
+// The Model which is not ready to serve yet, e.g. is pulling the images, is considered to be active.
+func (r *ModelReconciler) isActive(ctx context.Context, model *ollamav1alpha1.Model) bool {
+	pods, err := r.listPods(ctx, model)
+	if err != nil {
+		return true
+	}
+	ready := slices.DeleteFunc(pods, func(pod *corev1.Pod) bool { return !podutil.IsReady(pod) })
+	if len(ready) == 0 {
+		return true
+	}
+	for _, pod := range ready {
+		running, err := ollama.NewClient(podURL(pod), nil).ListRunning(ctx)
+		if err != nil {
+			ctrl.LoggerFrom(ctx).V(1).Error(err, "unable to list the running models", "pod", pod.Name)
+			return true
+		}
+		if len(running) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func lastRequestTime(model *ollamav1alpha1.Model) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, model.Annotations[ollamav1alpha1.LastRequestTimeAnnotation])
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
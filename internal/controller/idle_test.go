@@ -0,0 +1,139 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestWakeUp(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	NewWithT(t).Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	NewWithT(t).Expect(ollamav1alpha1.AddToScheme(scheme)).To(Succeed())
+	const activatorHost = "ollama-operator-activator.ollama-operator-system.svc.cluster.local"
+	idleSince := time.Now().Add(-time.Hour)
+	newModel := func() *ollamav1alpha1.Model {
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "model",
+				Namespace:   "default",
+				UID:         "model-uid",
+				Annotations: map[string]string{ollamav1alpha1.LastRequestTimeAnnotation: time.Now().UTC().Format(time.RFC3339Nano)},
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+				Idle:   &ollamav1alpha1.ModelIdlePolicy{Timeout: metav1.Duration{Duration: 10 * time.Minute}},
+			},
+		}
+		model.Status.Conditions = []metav1.Condition{{
+			Type:               ollamav1alpha1.ModelConditionIdle,
+			Status:             metav1.ConditionTrue,
+			Reason:             ollamav1alpha1.ModelScaledToZero,
+			LastTransitionTime: metav1.NewTime(idleSince),
+		}}
+		return model
+	}
+	newPod := func(model *ollamav1alpha1.Model) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "model-abcde",
+				Namespace:       model.Namespace,
+				Labels:          map[string]string{ollamav1alpha1.ModelNameLabel: model.Name},
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: ollamav1alpha1.GroupVersion.String(), Kind: "Model", Name: model.Name, UID: model.UID}},
+			},
+			Status: corev1.PodStatus{PodIP: "10.0.0.1"},
+		}
+	}
+	serviceType := func(g Gomega, r *ModelReconciler, model *ollamav1alpha1.Model) corev1.ServiceType {
+		svc := &corev1.Service{}
+		g.Expect(r.Get(ctx, client.ObjectKeyFromObject(model), svc)).To(Succeed())
+		return svc.Spec.Type
+	}
+
+	t.Run("Should keep the Service pointing at the activator until a serving pod is ready", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel()
+		r := &ModelReconciler{
+			Client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(model.DeepCopy()).Build(),
+			Scheme:        scheme,
+			ActivatorHost: activatorHost,
+		}
+
+		// The Model scaled to zero routes the requests to the activator.
+		g.Expect(r.reconcileService(ctx, model, true)).To(Succeed())
+		g.Expect(serviceType(g, r, model)).To(Equal(corev1.ServiceTypeExternalName))
+
+		// A request wakes up the Model, but no serving pod is ready yet.
+		idle, _ := r.reconcileIdle(ctx, model)
+		g.Expect(idle).To(BeFalse())
+		g.Expect(meta.IsStatusConditionFalse(model.Status.Conditions, ollamav1alpha1.ModelConditionIdle)).To(BeTrue())
+		g.Expect(r.reconcileService(ctx, model, idle)).To(Succeed())
+		g.Expect(serviceType(g, r, model)).To(Equal(corev1.ServiceTypeExternalName))
+
+		pod := newPod(model)
+		g.Expect(r.Create(ctx, pod)).To(Succeed())
+		g.Expect(r.reconcileService(ctx, model, false)).To(Succeed())
+		g.Expect(serviceType(g, r, model)).To(Equal(corev1.ServiceTypeExternalName))
+
+		// The Service selects the serving pods once one is ready.
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		g.Expect(r.Status().Update(ctx, pod)).To(Succeed())
+		g.Expect(r.reconcileService(ctx, model, false)).To(Succeed())
+		svc := &corev1.Service{}
+		g.Expect(r.Get(ctx, client.ObjectKeyFromObject(model), svc)).To(Succeed())
+		g.Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+		g.Expect(svc.Spec.ExternalName).To(BeEmpty())
+		g.Expect(svc.Spec.Selector).To(Equal(map[string]string{ollamav1alpha1.ModelNameLabel: model.Name}))
+	})
+
+	t.Run("Should stay idle without a request after the Model is scaled to zero", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel()
+		model.Annotations[ollamav1alpha1.LastRequestTimeAnnotation] = idleSince.Add(-time.Minute).UTC().Format(time.RFC3339Nano)
+		r := &ModelReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(model.DeepCopy()).Build(), Scheme: scheme}
+		idle, _ := r.reconcileIdle(ctx, model)
+		g.Expect(idle).To(BeTrue())
+	})
+
+	t.Run("Should not point the Service of an active Model at the activator", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel()
+		model.Status.Conditions = nil
+		r := &ModelReconciler{
+			Client:        fake.NewClientBuilder().WithScheme(scheme).WithObjects(model.DeepCopy()).Build(),
+			Scheme:        scheme,
+			ActivatorHost: activatorHost,
+		}
+		// The Model which has not been scaled to zero starts without a ready pod.
+		g.Expect(r.reconcileService(ctx, model, false)).To(Succeed())
+		g.Expect(serviceType(g, r, model)).To(Equal(corev1.ServiceTypeClusterIP))
+	})
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+var (
+	modelReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ollama_model_replicas",
+		Help: "Number of the serving pods of the Model.",
+	}, []string{"namespace", "model"})
+	modelDesiredReplicas = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ollama_model_desired_replicas",
+		Help: "Number of the serving pods of the Model desired by the autoscaler.",
+	}, []string{"namespace", "model"})
+	modelInFlightRequests = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ollama_model_in_flight_requests",
+		Help: "Number of the requests processed by the serving pods of the Model.",
+	}, []string{"namespace", "model"})
+	modelQueuedRequests = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ollama_model_queued_requests",
+		Help: "Number of the requests waiting in front of the serving pods of the Model.",
+	}, []string{"namespace", "model"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(modelReplicas, modelDesiredReplicas, modelInFlightRequests, modelQueuedRequests)
+}
+
+// recordMetrics publishes the replicas and the load of the Model.
+func recordMetrics(model *ollamav1alpha1.Model) {
+	modelReplicas.WithLabelValues(model.Namespace, model.Name).Set(float64(model.Status.Replicas))
+	if model.Status.Load == nil {
+		modelInFlightRequests.DeleteLabelValues(model.Namespace, model.Name)
+		modelQueuedRequests.DeleteLabelValues(model.Namespace, model.Name)
+		return
+	}
+	modelInFlightRequests.WithLabelValues(model.Namespace, model.Name).Set(float64(model.Status.Load.InFlightRequests))
+	modelQueuedRequests.WithLabelValues(model.Namespace, model.Name).Set(float64(model.Status.Load.QueuedRequests))
+}
+
+// deleteMetrics removes the metrics of the deleted Model.
+func deleteMetrics(model *ollamav1alpha1.Model) {
+	for _, vec := range []*prometheus.GaugeVec{modelReplicas, modelDesiredReplicas, modelInFlightRequests, modelQueuedRequests} {
+		vec.DeleteLabelValues(model.Namespace, model.Name)
+	}
+}
@@ -18,16 +18,23 @@ package controller
 
 import (
 	"context"
+	"slices"
 
 	corev1 "k8s.io/api/core/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 
 	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/registry"
 )
 
 // ModelReconciler reconciles a Model object
@@ -35,11 +42,40 @@ type ModelReconciler struct {
 	client.Client
 	Scheme               *runtime.Scheme
 	OllamaContainerImage string
+	// ActivatorHost is the DNS name of the activator Service, which the Model's Service points to while the Model is idle.
+	ActivatorHost string
+	// AgentImage is the container image of the agent, which is injected in the Model pods, e.g. as the proxy measuring the load.
+	AgentImage string
+	// OperatorNamespace is the namespace the operator components run in. The NetworkPolicies of the Models allow the traffic from it.
+	// If it is empty, the traffic from the operator components in any namespace is allowed.
+	OperatorNamespace string
+	// DefaultRegistry is the registry configuration applied to the pulls of all Models, which spec.registry is merged with.
+	DefaultRegistry *ollamav1alpha1.ModelRegistry
+	// PullScheduling queues the pulls of the pods for the pull scheduler, which limits the pulls running at once.
+	PullScheduling bool
+	// StoragePreflight resolves the download size of the images from the registries before the pods are created,
+	// which is requested as the ephemeral storage of the pods or validated against the size of their volume.
+	StoragePreflight bool
+	// APIReader reads the registry credential Secrets of the storage preflight, which are not in the cache
+	// since it holds only the Secrets created for the ModelBindings. If it is nil, Client is used.
+	APIReader client.Reader
+
+	// newManifestFetcher returns the client resolving the manifests of the images. It defaults to registry.NewClient.
+	newManifestFetcher func(registry.Config) manifestFetcher
+	manifests          manifestCache
 }
 
 // +kubebuilder:rbac:groups=ollama.sivchari.io,resources=models,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ollama.sivchari.io,resources=models/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=ollama.sivchari.io,resources=models/finalizers,verbs=update
+// +kubebuilder:rbac:groups=ollama.sivchari.io,resources=modelclasses;modelcatalogs;modelquotas;modelcaches;nodecaches,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies;ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile runs the serving pods of the Model and exposes them through its Service.
 func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	model := &ollamav1alpha1.Model{}
 	if err := r.Get(ctx, req.NamespacedName, model); err != nil {
@@ -51,57 +87,182 @@ func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		if err := r.Status().Patch(ctx, newModel, patch); err != nil {
 			ctrl.LoggerFrom(ctx).V(1).Error(err, "unable to update Model status")
 		}
-
-		if err := r.Patch(ctx, newModel, patch); err != nil {
-			ctrl.LoggerFrom(ctx).V(1).Error(err, "unable to update Model status")
-		}
 	}()
 	if !model.DeletionTimestamp.IsZero() {
-		return ctrl.Result{}, r.reconcileDelete(ctx, model)
+		return ctrl.Result{}, r.reconcileDelete(ctx, newModel)
 	}
-	return ctrl.Result{}, r.reconcileNormal(ctx, newModel)
+	return r.reconcileNormal(ctx, newModel)
 }
 
 func (r *ModelReconciler) reconcileDelete(ctx context.Context, model *ollamav1alpha1.Model) error {
 	if !controllerutil.ContainsFinalizer(model, ollamav1alpha1.ModelFinalizer) {
 		return nil
 	}
-	pod := &corev1.Pod{}
-	if err := r.Get(ctx, client.ObjectKey{Namespace: model.Namespace, Name: model.Name}, pod); err != nil && !apierrors.IsNotFound(err) {
+	if err := r.deletePods(ctx, model); err != nil {
 		return err
 	}
-	if err := r.Delete(ctx, pod); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return err
-		}
-	}
+	deleteMetrics(model)
 	controllerutil.RemoveFinalizer(model, ollamav1alpha1.ModelFinalizer)
-	return nil
+	return r.Update(ctx, model)
 }
 
-func (r *ModelReconciler) reconcileNormal(ctx context.Context, model *ollamav1alpha1.Model) error {
+func (r *ModelReconciler) reconcileNormal(ctx context.Context, model *ollamav1alpha1.Model) (ctrl.Result, error) {
 	if !controllerutil.ContainsFinalizer(model, ollamav1alpha1.ModelFinalizer) {
 		controllerutil.AddFinalizer(model, ollamav1alpha1.ModelFinalizer)
 		if err := r.Update(ctx, model); err != nil {
-			return err
+			return ctrl.Result{}, err
 		}
 	}
-	if err := r.reconcilePod(ctx, model); err != nil {
+	if err := r.reconcileNetworkPolicy(ctx, model); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileExpose(ctx, model); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcilePeerService(ctx, model); err != nil {
+		return ctrl.Result{}, err
+	}
+	blocked, err := r.reconcileCatalog(ctx, model)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if ptr.Deref(model.Spec.Paused, false) {
+		if err := r.reconcileService(ctx, model, false); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.reconcilePodDisruptionBudget(ctx, model, 0); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.reconcilePaused(ctx, model)
+	}
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:               ollamav1alpha1.ModelConditionPaused,
+		Status:             metav1.ConditionFalse,
+		Reason:             ollamav1alpha1.ModelNotPaused,
+		ObservedGeneration: model.Generation,
+	})
+	exceeded, err := r.reconcileQuota(ctx, model)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if exceeded {
+		if err := r.reconcileService(ctx, model, false); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.reconcilePodDisruptionBudget(ctx, model, 0); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: quotaRecheckInterval}, r.scaleToZero(ctx, model)
+	}
+	idle, requeueAfter := r.reconcileIdle(ctx, model)
+	if err := r.reconcileService(ctx, model, idle); err != nil {
+		return ctrl.Result{}, err
+	}
+	if idle {
+		if err := r.reconcilePodDisruptionBudget(ctx, model, 0); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.scaleToZero(ctx, model)
+	}
+	scaleAfter, err := r.reconcileAutoscaling(ctx, model)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcilePodDisruptionBudget(ctx, model, ptr.Deref(model.Spec.Replicas, 1)); err != nil {
+		return ctrl.Result{}, err
+	}
+	class, resolved, err := r.reconcileModelClass(ctx, model)
+	if err != nil || !resolved {
+		return ctrl.Result{RequeueAfter: requeueAfter}, err
+	}
+	// The pods are generated from the Model merged with its ModelClass and without the images blocked by
+	// the ModelCatalogs, which is never written back to the Model.
+	desired := withModelClass(model, class)
+	desired.Spec.Images = slices.DeleteFunc(desired.Spec.Images, func(image string) bool {
+		return slices.Contains(blocked, image)
+	})
+	cached, err := r.reconcileModelCache(ctx, desired)
+	if err == nil && cached && r.reconcileStorage(ctx, desired) {
+		err = r.reconcilePod(ctx, desired)
+	}
+	model.Status = desired.Status
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	recordMetrics(model)
+	if scaleAfter > 0 && (requeueAfter == 0 || scaleAfter < requeueAfter) {
+		requeueAfter = scaleAfter
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// reconcilePaused scales the serving pods of the Model to zero.
+func (r *ModelReconciler) reconcilePaused(ctx context.Context, model *ollamav1alpha1.Model) error {
+	if err := r.scaleToZero(ctx, model); err != nil {
+		return err
+	}
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:               ollamav1alpha1.ModelConditionPaused,
+		Status:             metav1.ConditionTrue,
+		Reason:             ollamav1alpha1.ModelPaused,
+		Message:            "serving pods are scaled to zero",
+		ObservedGeneration: model.Generation,
+	})
+	return nil
+}
+
+// scaleToZero deletes the serving pods of the Model.
+// The volumes and the status except podRef and the replicas are kept, so the Model resumes from the cached images.
+func (r *ModelReconciler) scaleToZero(ctx context.Context, model *ollamav1alpha1.Model) error {
+	if err := r.deletePods(ctx, model); err != nil {
 		return err
 	}
+	model.Status.PodRef = nil
+	setReplicas(model, nil, nil)
+	recordMetrics(model)
 	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ModelReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&ollamav1alpha1.Model{}).
+		Owns(&corev1.Service{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Owns(&networkingv1.Ingress{}).
 		Watches(
 			&corev1.Pod{},
 			handler.EnqueueRequestsFromMapFunc(podToModel),
 		).
-		Named("model").
-		Complete(r)
+		Watches(
+			&ollamav1alpha1.ModelClass{},
+			handler.EnqueueRequestsFromMapFunc(r.modelClassToModels),
+		).
+		Watches(
+			&ollamav1alpha1.ModelCatalog{},
+			handler.EnqueueRequestsFromMapFunc(r.modelCatalogToModels),
+		).
+		Watches(
+			&ollamav1alpha1.ModelCache{},
+			handler.EnqueueRequestsFromMapFunc(r.modelCacheToModels),
+		).
+		Watches(
+			&ollamav1alpha1.ModelQuota{},
+			handler.EnqueueRequestsFromMapFunc(r.modelQuotaToModels),
+		).
+		Named("model")
+	// The HTTPRoutes are watched only if the Gateway API is installed when the operator starts.
+	installed, err := httpRouteInstalled(mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	}
+	if installed {
+		route := &unstructured.Unstructured{}
+		route.SetGroupVersionKind(httpRouteGVK)
+		b = b.Owns(route)
+	}
+	return b.Complete(r)
 }
 
 func podToModel(ctx context.Context, obj client.Object) []ctrl.Request {
@@ -17,12 +17,21 @@ limitations under the License.
 package controller
 
 import (
+	"slices"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
@@ -87,7 +96,7 @@ func TestModelReconciler(t *testing.T) {
 			g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: model.Status.PodRef.Name}, pod)).To(Succeed())
 			g.Expect(pod.Spec.Containers).To(HaveLen(1))
 			g.Expect(pod.Spec.Containers[0].Image).To(Equal("ollama/ollama:latest"))
-			g.Expect(pod.Spec.Containers[0].Lifecycle.PostStart.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "ollama pull llama3;"}))
+			g.Expect(pod.Spec.Containers[0].Lifecycle.PostStart.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "ollama show llama3 >/dev/null 2>&1 || ollama pull llama3;"}))
 		}).Should(Succeed())
 	})
 
@@ -133,7 +142,7 @@ func TestModelReconciler(t *testing.T) {
 			g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: model.Status.PodRef.Name}, pod)).To(Succeed())
 			g.Expect(pod.Spec.Containers).To(HaveLen(1))
 			g.Expect(pod.Spec.Containers[0].Image).To(Equal("ollama/ollama:latest"))
-			g.Expect(pod.Spec.Containers[0].Lifecycle.PostStart.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "ollama pull llama3;"}))
+			g.Expect(pod.Spec.Containers[0].Lifecycle.PostStart.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "ollama show llama3 >/dev/null 2>&1 || ollama pull llama3;"}))
 		}).Should(Succeed())
 
 		model.Spec.Images = append(model.Spec.Images, "hf.co/mlabonne/Meta-Llama-3.1-8B-Instruct-abliterated-GGUF")
@@ -146,7 +155,7 @@ func TestModelReconciler(t *testing.T) {
 			g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: model.Status.PodRef.Name}, pod)).To(Succeed())
 			g.Expect(pod.Spec.Containers).To(HaveLen(1))
 			g.Expect(pod.Spec.Containers[0].Image).To(Equal("ollama/ollama:latest"))
-			g.Expect(pod.Spec.Containers[0].Lifecycle.PostStart.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "ollama pull llama3;ollama pull hf.co/mlabonne/Meta-Llama-3.1-8B-Instruct-abliterated-GGUF;"}))
+			g.Expect(pod.Spec.Containers[0].Lifecycle.PostStart.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "ollama show llama3 >/dev/null 2>&1 || ollama pull llama3;ollama show hf.co/mlabonne/Meta-Llama-3.1-8B-Instruct-abliterated-GGUF >/dev/null 2>&1 || ollama pull hf.co/mlabonne/Meta-Llama-3.1-8B-Instruct-abliterated-GGUF;"}))
 		}).Should(Succeed())
 	})
 
@@ -192,7 +201,7 @@ func TestModelReconciler(t *testing.T) {
 			g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: model.Status.PodRef.Name}, pod)).To(Succeed())
 			g.Expect(pod.Spec.Containers).To(HaveLen(1))
 			g.Expect(pod.Spec.Containers[0].Image).To(Equal("ollama/ollama:latest"))
-			g.Expect(pod.Spec.Containers[0].Lifecycle.PostStart.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "ollama pull llama3;"}))
+			g.Expect(pod.Spec.Containers[0].Lifecycle.PostStart.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "ollama show llama3 >/dev/null 2>&1 || ollama pull llama3;"}))
 		}).Should(Succeed())
 
 		g.Eventually(func(g Gomega) {
@@ -210,7 +219,713 @@ func TestModelReconciler(t *testing.T) {
 			g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: model.Status.PodRef.Name}, pod)).To(Succeed())
 			g.Expect(pod.Spec.Containers).To(HaveLen(1))
 			g.Expect(pod.Spec.Containers[0].Image).To(Equal("ollama/ollama:latest"))
-			g.Expect(pod.Spec.Containers[0].Lifecycle.PostStart.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "ollama pull llama3;"}))
+			g.Expect(pod.Spec.Containers[0].Lifecycle.PostStart.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "ollama show llama3 >/dev/null 2>&1 || ollama pull llama3;"}))
+		}).Should(Succeed())
+	})
+
+	t.Run("Should use the runtime of the Model", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+				Namespace:    ns.Name,
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+				Runtime: &ollamav1alpha1.ModelRuntime{
+					Image:           "ollama/ollama:0.6.2",
+					ImagePullPolicy: corev1.PullIfNotPresent,
+				},
+			},
+		}
+		g.Expect(env.Create(ctx, model)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, model)).To(Succeed())
+		})
+
+		key := client.ObjectKey{
+			Name:      model.Name,
+			Namespace: ns.Name,
+		}
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			g.Expect(model.Status.PodRef).NotTo(BeNil())
+			pod := &corev1.Pod{}
+			g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: model.Status.PodRef.Name}, pod)).To(Succeed())
+			g.Expect(pod.Labels).To(HaveKeyWithValue(ollamav1alpha1.ModelNameLabel, model.Name))
+			g.Expect(pod.Labels).To(HaveKey(ollamav1alpha1.PodTemplateHashLabel))
+			g.Expect(pod.Spec.Containers).To(HaveLen(1))
+			g.Expect(pod.Spec.Containers[0].Image).To(Equal("ollama/ollama:0.6.2"))
+			g.Expect(pod.Spec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+			g.Expect(model.Status.Runtime).NotTo(BeNil())
+			g.Expect(model.Status.Runtime.Image).To(Equal("ollama/ollama:0.6.2"))
+		}).Should(Succeed())
+
+		model.Spec.Runtime.Image = "ollama/ollama:0.6.5"
+		g.Expect(updateModel(model)).To(Succeed())
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			pod := &corev1.Pod{}
+			g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: model.Status.PodRef.Name}, pod)).To(Succeed())
+			g.Expect(pod.Spec.Containers).To(HaveLen(1))
+			g.Expect(pod.Spec.Containers[0].Image).To(Equal("ollama/ollama:0.6.5"))
+			g.Expect(model.Status.Runtime.Image).To(Equal("ollama/ollama:0.6.5"))
+		}).Should(Succeed())
+	})
+
+	t.Run("Should delete the Pod while the Model is paused", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+				Namespace:    ns.Name,
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+			},
+		}
+		g.Expect(env.Create(ctx, model)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, model)).To(Succeed())
+		})
+
+		key := client.ObjectKey{
+			Name:      model.Name,
+			Namespace: ns.Name,
+		}
+
+		var podName string
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			g.Expect(model.Status.PodRef).NotTo(BeNil())
+			podName = model.Status.PodRef.Name
+			g.Expect(meta.IsStatusConditionFalse(model.Status.Conditions, ollamav1alpha1.ModelConditionPaused)).To(BeTrue())
+		}).Should(Succeed())
+
+		model.Spec.Paused = ptr.To(true)
+		g.Expect(updateModel(model)).To(Succeed())
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			g.Expect(model.Status.PodRef).To(BeNil())
+			g.Expect(meta.IsStatusConditionTrue(model.Status.Conditions, ollamav1alpha1.ModelConditionPaused)).To(BeTrue())
+			pods := &corev1.PodList{}
+			g.Expect(env.List(ctx, pods, client.InNamespace(ns.Name), client.MatchingLabels{ollamav1alpha1.ModelNameLabel: model.Name})).To(Succeed())
+			for _, pod := range pods.Items {
+				g.Expect(pod.DeletionTimestamp).NotTo(BeNil())
+			}
+		}).Should(Succeed())
+
+		model.Spec.Paused = ptr.To(false)
+		g.Expect(updateModel(model)).To(Succeed())
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			g.Expect(model.Status.PodRef).NotTo(BeNil())
+			g.Expect(model.Status.PodRef.Name).NotTo(Equal(podName))
+			g.Expect(meta.IsStatusConditionFalse(model.Status.Conditions, ollamav1alpha1.ModelConditionPaused)).To(BeTrue())
+		}).Should(Succeed())
+	})
+
+	t.Run("Should create the Service of the Model", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+				Namespace:    ns.Name,
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+				Idle: &ollamav1alpha1.ModelIdlePolicy{
+					Timeout: metav1.Duration{Duration: time.Hour},
+				},
+			},
+		}
+		g.Expect(env.Create(ctx, model)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, model)).To(Succeed())
+		})
+
+		key := client.ObjectKey{
+			Name:      model.Name,
+			Namespace: ns.Name,
+		}
+
+		g.Eventually(func(g Gomega) {
+			svc := &corev1.Service{}
+			g.Expect(env.Get(ctx, key, svc)).To(Succeed())
+			g.Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+			g.Expect(svc.Spec.Selector).To(Equal(map[string]string{ollamav1alpha1.ModelNameLabel: model.Name}))
+			g.Expect(svc.Spec.Ports).To(HaveLen(1))
+			g.Expect(svc.Spec.Ports[0].Port).To(Equal(int32(11434)))
+		}).Should(Succeed())
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			g.Expect(model.Status.LastActiveTime).NotTo(BeNil())
+			g.Expect(meta.IsStatusConditionFalse(model.Status.Conditions, ollamav1alpha1.ModelConditionIdle)).To(BeTrue())
+		}).Should(Succeed())
+	})
+
+	t.Run("Should create the Pods of the replicas", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+				Namespace:    ns.Name,
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images:   []string{"llama3"},
+				Replicas: ptr.To[int32](3),
+			},
+		}
+		g.Expect(env.Create(ctx, model)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, model)).To(Succeed())
+		})
+
+		key := client.ObjectKey{
+			Name:      model.Name,
+			Namespace: ns.Name,
+		}
+		livePods := func(g Gomega) []corev1.Pod {
+			pods := &corev1.PodList{}
+			g.Expect(env.List(ctx, pods, client.InNamespace(ns.Name), client.MatchingLabels{ollamav1alpha1.ModelNameLabel: model.Name})).To(Succeed())
+			return slices.DeleteFunc(pods.Items, func(pod corev1.Pod) bool { return pod.DeletionTimestamp != nil })
+		}
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			g.Expect(model.Status.Replicas).To(Equal(int32(3)))
+			g.Expect(model.Status.UpdatedReplicas).To(Equal(int32(3)))
+			g.Expect(model.Status.Selector).To(Equal(ollamav1alpha1.ModelNameLabel + "=" + model.Name))
+			g.Expect(livePods(g)).To(HaveLen(3))
+		}).Should(Succeed())
+
+		scale := &autoscalingv1.Scale{}
+		g.Expect(env.SubResource("scale").Get(ctx, model, scale)).To(Succeed())
+		g.Expect(scale.Spec.Replicas).To(Equal(int32(3)))
+		scale.Spec.Replicas = 1
+		g.Expect(env.SubResource("scale").Update(ctx, model, client.WithSubResourceBody(scale))).To(Succeed())
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			g.Expect(model.Status.Replicas).To(Equal(int32(1)))
+			g.Expect(livePods(g)).To(HaveLen(1))
+		}).Should(Succeed())
+	})
+
+	t.Run("Should create the PodDisruptionBudget of the replicated Model", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+				Namespace:    ns.Name,
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images:   []string{"llama3"},
+				Replicas: ptr.To[int32](2),
+			},
+		}
+		g.Expect(env.Create(ctx, model)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, model)).To(Succeed())
+		})
+
+		key := client.ObjectKey{
+			Name:      model.Name,
+			Namespace: ns.Name,
+		}
+
+		g.Eventually(func(g Gomega) {
+			pdb := &policyv1.PodDisruptionBudget{}
+			g.Expect(env.Get(ctx, key, pdb)).To(Succeed())
+			g.Expect(pdb.Spec.MaxUnavailable).To(Equal(ptr.To(intstr.FromInt32(1))))
+			g.Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{ollamav1alpha1.ModelNameLabel: model.Name}))
+		}).Should(Succeed())
+
+		model.Spec.Disruption = &ollamav1alpha1.ModelDisruptionPolicy{MinAvailable: ptr.To(intstr.FromString("50%"))}
+		g.Expect(updateModel(model)).To(Succeed())
+
+		g.Eventually(func(g Gomega) {
+			pdb := &policyv1.PodDisruptionBudget{}
+			g.Expect(env.Get(ctx, key, pdb)).To(Succeed())
+			g.Expect(pdb.Spec.MinAvailable).To(Equal(ptr.To(intstr.FromString("50%"))))
+			g.Expect(pdb.Spec.MaxUnavailable).To(BeNil())
+		}).Should(Succeed())
+
+		model.Spec.Paused = ptr.To(true)
+		g.Expect(updateModel(model)).To(Succeed())
+
+		g.Eventually(func(g Gomega) {
+			g.Expect(apierrors.IsNotFound(env.Get(ctx, key, &policyv1.PodDisruptionBudget{}))).To(BeTrue())
+		}).Should(Succeed())
+	})
+
+	t.Run("Should create the NetworkPolicy of the Model", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+				Namespace:    ns.Name,
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+				Network: &ollamav1alpha1.ModelNetwork{
+					Ingress: []networkingv1.NetworkPolicyPeer{
+						{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "ml"}}},
+					},
+				},
+			},
+		}
+		g.Expect(env.Create(ctx, model)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, model)).To(Succeed())
+		})
+
+		key := client.ObjectKey{
+			Name:      model.Name,
+			Namespace: ns.Name,
+		}
+
+		g.Eventually(func(g Gomega) {
+			np := &networkingv1.NetworkPolicy{}
+			g.Expect(env.Get(ctx, key, np)).To(Succeed())
+			g.Expect(np.Spec.PodSelector.MatchLabels).To(Equal(map[string]string{ollamav1alpha1.ModelNameLabel: model.Name}))
+			g.Expect(np.Spec.Ingress).To(HaveLen(2))
+		}).Should(Succeed())
+
+		model.Spec.Network = nil
+		g.Expect(updateModel(model)).To(Succeed())
+
+		g.Eventually(func(g Gomega) {
+			g.Expect(apierrors.IsNotFound(env.Get(ctx, key, &networkingv1.NetworkPolicy{}))).To(BeTrue())
+		}).Should(Succeed())
+	})
+
+	t.Run("Should expose the Model with the Ingress", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+				Namespace:    ns.Name,
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+				Expose: &ollamav1alpha1.ModelExpose{
+					Host: "llama.example.com",
+					TLS:  &ollamav1alpha1.ModelExposeTLS{SecretName: "llama-tls"},
+				},
+			},
+		}
+		g.Expect(env.Create(ctx, model)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, model)).To(Succeed())
+		})
+
+		key := client.ObjectKey{
+			Name:      model.Name,
+			Namespace: ns.Name,
+		}
+
+		g.Eventually(func(g Gomega) {
+			ing := &networkingv1.Ingress{}
+			g.Expect(env.Get(ctx, key, ing)).To(Succeed())
+			g.Expect(ing.Spec.Rules).To(HaveLen(1))
+			g.Expect(ing.Spec.Rules[0].Host).To(Equal("llama.example.com"))
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			g.Expect(model.Status.URL).To(Equal("https://llama.example.com/"))
+		}).Should(Succeed())
+
+		model.Spec.Expose = nil
+		g.Expect(updateModel(model)).To(Succeed())
+
+		g.Eventually(func(g Gomega) {
+			g.Expect(apierrors.IsNotFound(env.Get(ctx, key, &networkingv1.Ingress{}))).To(BeTrue())
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			g.Expect(model.Status.URL).To(BeEmpty())
+		}).Should(Succeed())
+	})
+
+	t.Run("Should use the defaults of the ModelClass", func(t *testing.T) {
+		g := NewWithT(t)
+		class := &ollamav1alpha1.ModelClass{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+			},
+			Spec: ollamav1alpha1.ModelClassSpec{
+				Runtime: &ollamav1alpha1.ModelRuntime{Image: "ollama/ollama:0.6.2"},
+				Template: &ollamav1alpha1.ModelTemplate{
+					Spec: &ollamav1alpha1.ModelTemplateSpec{
+						NodeSelector: map[string]string{"accelerator": "nvidia"},
+					},
+				},
+			},
+		}
+		g.Expect(env.Create(ctx, class)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, class)).To(Succeed())
+		})
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+				Namespace:    ns.Name,
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images:    []string{"llama3"},
+				ClassName: ptr.To(class.Name),
+				Template: &ollamav1alpha1.ModelTemplate{
+					Spec: &ollamav1alpha1.ModelTemplateSpec{
+						NodeSelector: map[string]string{"zone": "a"},
+					},
+				},
+			},
+		}
+		g.Expect(env.Create(ctx, model)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, model)).To(Succeed())
+		})
+
+		key := client.ObjectKey{
+			Name:      model.Name,
+			Namespace: ns.Name,
+		}
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			g.Expect(model.Spec.Runtime).To(BeNil())
+			g.Expect(model.Status.PodRef).NotTo(BeNil())
+			pod := &corev1.Pod{}
+			g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: model.Status.PodRef.Name}, pod)).To(Succeed())
+			g.Expect(pod.Spec.Containers[0].Image).To(Equal("ollama/ollama:0.6.2"))
+			g.Expect(pod.Spec.NodeSelector).To(Equal(map[string]string{"accelerator": "nvidia", "zone": "a"}))
+		}).Should(Succeed())
+
+		class.Spec.Runtime.Image = "ollama/ollama:0.6.5"
+		g.Expect(env.Update(ctx, class)).To(Succeed())
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			g.Expect(model.Status.PodRef).NotTo(BeNil())
+			pod := &corev1.Pod{}
+			g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: model.Status.PodRef.Name}, pod)).To(Succeed())
+			g.Expect(pod.Spec.Containers[0].Image).To(Equal("ollama/ollama:0.6.5"))
+		}).Should(Succeed())
+	})
+
+	t.Run("Should not pull the images blocked by the ModelCatalog", func(t *testing.T) {
+		g := NewWithT(t)
+		modelCatalog := &ollamav1alpha1.ModelCatalog{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+			},
+			Spec: ollamav1alpha1.ModelCatalogSpec{
+				Entries: []ollamav1alpha1.ModelCatalogEntry{{Name: "llama3"}},
+			},
+		}
+		g.Expect(env.Create(ctx, modelCatalog)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, modelCatalog)).To(Succeed())
+		})
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+				Namespace:    ns.Name,
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3", "phi3"},
+			},
+		}
+		g.Expect(env.Create(ctx, model)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, model)).To(Succeed())
+		})
+
+		key := client.ObjectKey{
+			Name:      model.Name,
+			Namespace: ns.Name,
+		}
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			g.Expect(model.Status.BlockedImages).To(Equal([]string{"phi3"}))
+			condition := meta.FindStatusCondition(model.Status.Conditions, ollamav1alpha1.ModelConditionApproved)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			g.Expect(condition.Reason).To(Equal(ollamav1alpha1.ImagesBlocked))
+			g.Expect(model.Status.PodRef).NotTo(BeNil())
+			pod := &corev1.Pod{}
+			g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: model.Status.PodRef.Name}, pod)).To(Succeed())
+			postStart := pod.Spec.Containers[0].Lifecycle.PostStart.Exec.Command[2]
+			g.Expect(postStart).To(ContainSubstring("llama3"))
+			g.Expect(postStart).NotTo(ContainSubstring("phi3"))
+		}).Should(Succeed())
+	})
+
+	t.Run("Should scale the Model exceeding the ModelQuota to zero", func(t *testing.T) {
+		g := NewWithT(t)
+		quotaNs, err := env.CreateNamespace(ctx, modelReconcilerNamespace+"-quota")
+		g.Expect(err).NotTo(HaveOccurred())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, quotaNs)).To(Succeed())
+		})
+		modelQuota := &ollamav1alpha1.ModelQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "models",
+				Namespace: quotaNs.Name,
+			},
+			Spec: ollamav1alpha1.ModelQuotaSpec{
+				Hard: ollamav1alpha1.ModelQuotaLimits{Models: ptr.To[int32](1)},
+			},
+		}
+		g.Expect(env.Create(ctx, modelQuota)).To(Succeed())
+		first := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "first",
+				Namespace: quotaNs.Name,
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+			},
+		}
+		g.Expect(env.Create(ctx, first)).To(Succeed())
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, client.ObjectKeyFromObject(first), model)).To(Succeed())
+			g.Expect(model.Status.PodRef).NotTo(BeNil())
+		}).Should(Succeed())
+		second := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "second",
+				Namespace: quotaNs.Name,
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"phi3"},
+			},
+		}
+		g.Expect(env.Create(ctx, second)).To(Succeed())
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, client.ObjectKeyFromObject(second), model)).To(Succeed())
+			g.Expect(model.Status.PodRef).To(BeNil())
+			g.Expect(meta.IsStatusConditionTrue(model.Status.Conditions, ollamav1alpha1.ModelConditionQuotaExceeded)).To(BeTrue())
+			modelQuota := &ollamav1alpha1.ModelQuota{}
+			g.Expect(env.Get(ctx, client.ObjectKey{Namespace: quotaNs.Name, Name: "models"}, modelQuota)).To(Succeed())
+			g.Expect(modelQuota.Status.Used.Models).To(Equal(int32(1)))
+		}).Should(Succeed())
+
+		g.Expect(env.Delete(ctx, first)).To(Succeed())
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, client.ObjectKeyFromObject(second), model)).To(Succeed())
+			g.Expect(model.Status.PodRef).NotTo(BeNil())
+			g.Expect(meta.IsStatusConditionFalse(model.Status.Conditions, ollamav1alpha1.ModelConditionQuotaExceeded)).To(BeTrue())
+		}, 2*time.Minute).Should(Succeed())
+		g.Expect(env.Delete(ctx, second)).To(Succeed())
+	})
+
+	t.Run("Should bind the Model granted to another namespace", func(t *testing.T) {
+		g := NewWithT(t)
+		consumerNs, err := env.CreateNamespace(ctx, modelReconcilerNamespace+"-consumer")
+		g.Expect(err).NotTo(HaveOccurred())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, consumerNs)).To(Succeed())
+		})
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+				Namespace:    ns.Name,
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+			},
+		}
+		g.Expect(env.Create(ctx, model)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, model)).To(Succeed())
+		})
+		binding := &ollamav1alpha1.ModelBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "llama",
+				Namespace: consumerNs.Name,
+			},
+			Spec: ollamav1alpha1.ModelBindingSpec{
+				Model: ollamav1alpha1.ModelReference{Name: model.Name, Namespace: ns.Name},
+			},
+		}
+		g.Expect(env.Create(ctx, binding)).To(Succeed())
+		key := client.ObjectKeyFromObject(binding)
+
+		g.Eventually(func(g Gomega) {
+			binding := &ollamav1alpha1.ModelBinding{}
+			g.Expect(env.Get(ctx, key, binding)).To(Succeed())
+			condition := meta.FindStatusCondition(binding.Status.Conditions, ollamav1alpha1.ModelBindingConditionBound)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			g.Expect(condition.Reason).To(Equal(ollamav1alpha1.ModelBindingNotGranted))
+			g.Expect(apierrors.IsNotFound(env.Get(ctx, key, &corev1.Service{}))).To(BeTrue())
+		}).Should(Succeed())
+
+		grant := &ollamav1alpha1.ModelGrant{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "share",
+				Namespace: ns.Name,
+			},
+			Spec: ollamav1alpha1.ModelGrantSpec{
+				Models: []string{model.Name},
+				To:     []ollamav1alpha1.ModelGrantTo{{Namespace: consumerNs.Name}},
+			},
+		}
+		g.Expect(env.Create(ctx, grant)).To(Succeed())
+
+		g.Eventually(func(g Gomega) {
+			binding := &ollamav1alpha1.ModelBinding{}
+			g.Expect(env.Get(ctx, key, binding)).To(Succeed())
+			g.Expect(meta.IsStatusConditionTrue(binding.Status.Conditions, ollamav1alpha1.ModelBindingConditionBound)).To(BeTrue())
+			svc := &corev1.Service{}
+			g.Expect(env.Get(ctx, key, svc)).To(Succeed())
+			g.Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeExternalName))
+			g.Expect(svc.Spec.ExternalName).To(Equal(model.Name + "." + ns.Name + ".svc.cluster.local"))
+			secret := &corev1.Secret{}
+			g.Expect(env.Get(ctx, key, secret)).To(Succeed())
+			g.Expect(string(secret.Data["url"])).To(Equal("http://llama." + consumerNs.Name + ".svc.cluster.local:11434"))
+		}).Should(Succeed())
+
+		g.Expect(env.Delete(ctx, grant)).To(Succeed())
+
+		g.Eventually(func(g Gomega) {
+			g.Expect(apierrors.IsNotFound(env.Get(ctx, key, &corev1.Service{}))).To(BeTrue())
+			g.Expect(apierrors.IsNotFound(env.Get(ctx, key, &corev1.Secret{}))).To(BeTrue())
+		}).Should(Succeed())
+	})
+
+	t.Run("Should prefer the nodes whose cache holds the images", func(t *testing.T) {
+		g := NewWithT(t)
+		cache := &ollamav1alpha1.NodeCache{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+			},
+		}
+		g.Expect(env.Create(ctx, cache)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, cache)).To(Succeed())
+		})
+		cache.Status.Models = []ollamav1alpha1.NodeCacheModel{{Name: "llama3:latest"}}
+		g.Expect(env.Status().Update(ctx, cache)).To(Succeed())
+
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+				Namespace:    ns.Name,
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+				Template: &ollamav1alpha1.ModelTemplate{
+					Spec: &ollamav1alpha1.ModelTemplateSpec{
+						Storage: &ollamav1alpha1.ModelStorage{NodeCache: ptr.To(true)},
+					},
+				},
+			},
+		}
+		g.Expect(env.Create(ctx, model)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, model)).To(Succeed())
+		})
+
+		key := client.ObjectKey{
+			Name:      model.Name,
+			Namespace: ns.Name,
+		}
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			g.Expect(model.Status.PodRef).NotTo(BeNil())
+			pod := &corev1.Pod{}
+			g.Expect(env.Get(ctx, client.ObjectKey{Namespace: ns.Name, Name: model.Status.PodRef.Name}, pod)).To(Succeed())
+			g.Expect(pod.Spec.Volumes).To(ContainElement(HaveField("HostPath.Path", nodeCachePath)))
+			g.Expect(pod.Spec.Affinity).NotTo(BeNil())
+			terms := pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+			g.Expect(terms).To(HaveLen(1))
+			g.Expect(terms[0].Preference.MatchFields[0].Values).To(Equal([]string{cache.Name}))
+		}).Should(Succeed())
+	})
+
+	t.Run("Should not create the pods until the images are in the ModelCache", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: modelReconcilerName,
+				Namespace:    ns.Name,
+			},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+				Template: &ollamav1alpha1.ModelTemplate{
+					Spec: &ollamav1alpha1.ModelTemplateSpec{
+						Storage: &ollamav1alpha1.ModelStorage{CacheName: ptr.To("missing")},
+					},
+				},
+			},
+		}
+		g.Expect(env.Create(ctx, model)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, model)).To(Succeed())
+		})
+
+		key := client.ObjectKey{
+			Name:      model.Name,
+			Namespace: ns.Name,
+		}
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			condition := meta.FindStatusCondition(model.Status.Conditions, ollamav1alpha1.ModelConditionCacheReady)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			g.Expect(condition.Reason).To(Equal(ollamav1alpha1.ModelCacheNotFound))
+			g.Expect(model.Status.PodRef).To(BeNil())
+		}).Should(Succeed())
+
+		cache := &ollamav1alpha1.ModelCache{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "missing",
+				Namespace: ns.Name,
+			},
+			Spec: ollamav1alpha1.ModelCacheSpec{Images: []string{"llama3"}},
+		}
+		g.Expect(env.Create(ctx, cache)).To(Succeed())
+		t.Cleanup(func() {
+			g.Expect(env.Delete(ctx, cache)).To(Succeed())
+		})
+
+		g.Eventually(func(g Gomega) {
+			model := &ollamav1alpha1.Model{}
+			g.Expect(env.Get(ctx, key, model)).To(Succeed())
+			condition := meta.FindStatusCondition(model.Status.Conditions, ollamav1alpha1.ModelConditionCacheReady)
+			g.Expect(condition).NotTo(BeNil())
+			g.Expect(condition.Reason).To(Equal(ollamav1alpha1.ImagesNotCached))
+			g.Expect(model.Status.PodRef).To(BeNil())
+			pods := &corev1.PodList{}
+			g.Expect(env.List(ctx, pods, client.InNamespace(ns.Name), client.MatchingLabels{ollamav1alpha1.ModelNameLabel: model.Name})).To(Succeed())
+			g.Expect(pods.Items).To(BeEmpty())
 		}).Should(Succeed())
 	})
 }
@@ -0,0 +1,248 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+)
+
+// ModelBindingReconciler reconciles a ModelBinding object
+type ModelBindingReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=ollama.sivchari.io,resources=modelbindings,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ollama.sivchari.io,resources=modelbindings/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ollama.sivchari.io,resources=modelgrants,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ollama.sivchari.io,resources=models,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=services;secrets,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile creates the Service and the Secret of the ModelBinding if a ModelGrant shares the bound Model
+// with its namespace. Otherwise, the binding is refused and they are deleted.
+func (r *ModelBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	binding := &ollamav1alpha1.ModelBinding{}
+	if err := r.Get(ctx, req.NamespacedName, binding); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	patch := client.MergeFrom(binding.DeepCopy())
+	defer func() {
+		if err := r.Status().Patch(ctx, binding, patch); err != nil {
+			ctrl.LoggerFrom(ctx).V(1).Error(err, "unable to update ModelBinding status")
+		}
+	}()
+	return ctrl.Result{}, r.reconcileBinding(ctx, binding)
+}
+
+func (r *ModelBindingReconciler) reconcileBinding(ctx context.Context, binding *ollamav1alpha1.ModelBinding) error {
+	ref := binding.Spec.Model
+	condition := metav1.Condition{
+		Type:               ollamav1alpha1.ModelBindingConditionBound,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: binding.Generation,
+	}
+	defer func() { meta.SetStatusCondition(&binding.Status.Conditions, condition) }()
+
+	grants := &ollamav1alpha1.ModelGrantList{}
+	if err := r.List(ctx, grants, client.InNamespace(ref.Namespace)); err != nil {
+		return err
+	}
+	if ref.Namespace != binding.Namespace && !isGranted(grants.Items, ref.Name, binding.Namespace) {
+		condition.Reason = ollamav1alpha1.ModelBindingNotGranted
+		condition.Message = fmt.Sprintf("no ModelGrant in namespace %s shares Model %s with namespace %s", ref.Namespace, ref.Name, binding.Namespace)
+		return r.unbind(ctx, binding)
+	}
+	model := &ollamav1alpha1.Model{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, model); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		condition.Reason = ollamav1alpha1.ModelBindingModelNotFound
+		condition.Message = fmt.Sprintf("Model %s/%s is not found", ref.Namespace, ref.Name)
+		return r.unbind(ctx, binding)
+	}
+
+	if err := r.reconcileBindingService(ctx, binding); err != nil {
+		return err
+	}
+	if err := r.reconcileBindingSecret(ctx, binding); err != nil {
+		return err
+	}
+	binding.Status.ServiceName = binding.Name
+	binding.Status.SecretName = binding.Name
+	binding.Status.URL = "http://" + bindingAddress(binding)
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = ollamav1alpha1.ModelBindingGranted
+	condition.Message = ""
+	return nil
+}
+
+// isGranted reports whether grants share the Model named name with namespace.
+func isGranted(grants []ollamav1alpha1.ModelGrant, name, namespace string) bool {
+	for _, grant := range grants {
+		if len(grant.Spec.Models) > 0 && !slices.Contains(grant.Spec.Models, name) {
+			continue
+		}
+		if slices.ContainsFunc(grant.Spec.To, func(to ollamav1alpha1.ModelGrantTo) bool { return to.Namespace == namespace }) {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileBindingService ensures the Service which is an alias of the Service of the bound Model.
+func (r *ModelBindingReconciler) reconcileBindingService(ctx context.Context, binding *ollamav1alpha1.ModelBinding) error {
+	svc := &corev1.Service{}
+	svc.Namespace = binding.Namespace
+	svc.Name = binding.Name
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, svc, func() error {
+		if svc.Labels == nil {
+			svc.Labels = map[string]string{}
+		}
+		svc.Labels[ollamav1alpha1.ModelBindingNameLabel] = binding.Name
+		svc.Spec.Type = corev1.ServiceTypeExternalName
+		svc.Spec.ExternalName = fmt.Sprintf("%s.%s.svc.cluster.local", binding.Spec.Model.Name, binding.Spec.Model.Namespace)
+		svc.Spec.Ports = []corev1.ServicePort{
+			{
+				Name:       "ollama-server",
+				Port:       ollama.DefaultPort,
+				TargetPort: intstr.FromInt32(ollama.DefaultPort),
+				Protocol:   corev1.ProtocolTCP,
+			},
+		}
+		return controllerutil.SetControllerReference(binding, svc, r.Scheme)
+	})
+	return err
+}
+
+// reconcileBindingSecret ensures the Secret which holds the connection details of the bound Model.
+func (r *ModelBindingReconciler) reconcileBindingSecret(ctx context.Context, binding *ollamav1alpha1.ModelBinding) error {
+	secret := &corev1.Secret{}
+	secret.Namespace = binding.Namespace
+	secret.Name = binding.Name
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		secret.Labels[ollamav1alpha1.ModelBindingNameLabel] = binding.Name
+		address := bindingAddress(binding)
+		host, port, _ := net.SplitHostPort(address)
+		secret.Type = corev1.SecretTypeOpaque
+		secret.StringData = nil
+		secret.Data = map[string][]byte{
+			"host":        []byte(host),
+			"port":        []byte(port),
+			"url":         []byte("http://" + address),
+			"OLLAMA_HOST": []byte("http://" + address),
+		}
+		return controllerutil.SetControllerReference(binding, secret, r.Scheme)
+	})
+	return err
+}
+
+func bindingAddress(binding *ollamav1alpha1.ModelBinding) string {
+	return net.JoinHostPort(fmt.Sprintf("%s.%s.svc.cluster.local", binding.Name, binding.Namespace), strconv.Itoa(ollama.DefaultPort))
+}
+
+// unbind deletes the Service and the Secret of the ModelBinding.
+func (r *ModelBindingReconciler) unbind(ctx context.Context, binding *ollamav1alpha1.ModelBinding) error {
+	for _, obj := range []client.Object{&corev1.Service{}, &corev1.Secret{}} {
+		obj.SetNamespace(binding.Namespace)
+		obj.SetName(binding.Name)
+		if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if !metav1.IsControlledBy(obj, binding) {
+			continue
+		}
+		if err := client.IgnoreNotFound(r.Delete(ctx, obj)); err != nil {
+			return err
+		}
+	}
+	binding.Status.ServiceName = ""
+	binding.Status.SecretName = ""
+	binding.Status.URL = ""
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ModelBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ollamav1alpha1.ModelBinding{}).
+		Owns(&corev1.Service{}).
+		Owns(&corev1.Secret{}).
+		Watches(
+			&ollamav1alpha1.ModelGrant{},
+			handler.EnqueueRequestsFromMapFunc(r.namespaceToModelBindings),
+		).
+		Watches(
+			&ollamav1alpha1.Model{},
+			handler.EnqueueRequestsFromMapFunc(r.modelToModelBindings),
+		).
+		Named("modelbinding").
+		Complete(r)
+}
+
+// namespaceToModelBindings maps a ModelGrant to the ModelBindings of the Models in its namespace.
+func (r *ModelBindingReconciler) namespaceToModelBindings(ctx context.Context, obj client.Object) []ctrl.Request {
+	return r.modelBindings(ctx, func(ref ollamav1alpha1.ModelReference) bool {
+		return ref.Namespace == obj.GetNamespace()
+	})
+}
+
+// modelToModelBindings maps a Model to the ModelBindings which bind it.
+func (r *ModelBindingReconciler) modelToModelBindings(ctx context.Context, obj client.Object) []ctrl.Request {
+	return r.modelBindings(ctx, func(ref ollamav1alpha1.ModelReference) bool {
+		return ref.Namespace == obj.GetNamespace() && ref.Name == obj.GetName()
+	})
+}
+
+func (r *ModelBindingReconciler) modelBindings(ctx context.Context, match func(ref ollamav1alpha1.ModelReference) bool) []ctrl.Request {
+	bindings := &ollamav1alpha1.ModelBindingList{}
+	if err := r.List(ctx, bindings); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "unable to list ModelBindings")
+		return nil
+	}
+	var requests []ctrl.Request
+	for _, binding := range bindings.Items {
+		if match(binding.Spec.Model) {
+			requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&binding)})
+		}
+	}
+	return requests
+}
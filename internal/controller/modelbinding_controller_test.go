@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestIsGranted(t *testing.T) {
+	grants := []ollamav1alpha1.ModelGrant{
+		{
+			Spec: ollamav1alpha1.ModelGrantSpec{
+				Models: []string{"llama"},
+				To:     []ollamav1alpha1.ModelGrantTo{{Namespace: "team-a"}},
+			},
+		},
+		{
+			Spec: ollamav1alpha1.ModelGrantSpec{
+				To: []ollamav1alpha1.ModelGrantTo{{Namespace: "team-b"}},
+			},
+		},
+	}
+	for _, tt := range []struct {
+		name      string
+		model     string
+		namespace string
+		want      bool
+	}{
+		{
+			name:      "Should grant the Model listed in the ModelGrant",
+			model:     "llama",
+			namespace: "team-a",
+			want:      true,
+		},
+		{
+			name:      "Should not grant the Model which is not listed in the ModelGrant",
+			model:     "mistral",
+			namespace: "team-a",
+		},
+		{
+			name:      "Should grant all Models if the ModelGrant does not list the Models",
+			model:     "mistral",
+			namespace: "team-b",
+			want:      true,
+		},
+		{
+			name:      "Should not grant the namespace which is not listed in the ModelGrant",
+			model:     "llama",
+			namespace: "team-c",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(isGranted(grants, tt.model, tt.namespace)).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+)
+
+// reconcileModelCache reports whether the ModelCache the Model reads the models from holds all images of the Model.
+// The pods are not created until then, since the read-only volume of the ModelCache cannot be pulled into.
+func (r *ModelReconciler) reconcileModelCache(ctx context.Context, model *ollamav1alpha1.Model) (bool, error) {
+	name := modelCacheName(model)
+	if name == "" {
+		meta.RemoveStatusCondition(&model.Status.Conditions, ollamav1alpha1.ModelConditionCacheReady)
+		return true, nil
+	}
+	condition := metav1.Condition{
+		Type:               ollamav1alpha1.ModelConditionCacheReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: model.Generation,
+	}
+	defer func() { meta.SetStatusCondition(&model.Status.Conditions, condition) }()
+
+	cache := &ollamav1alpha1.ModelCache{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: model.Namespace, Name: name}, cache); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, err
+		}
+		condition.Reason = ollamav1alpha1.ModelCacheNotFound
+		condition.Message = fmt.Sprintf("ModelCache %q is not found", name)
+		return false, nil
+	}
+	if missing := notCached(cache, model.Spec.Images); len(missing) > 0 {
+		condition.Reason = ollamav1alpha1.ImagesNotCached
+		condition.Message = fmt.Sprintf("images are not in ModelCache %s yet: %s", name, strings.Join(missing, ", "))
+		return false, nil
+	}
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = ollamav1alpha1.ImagesCached
+	return true, nil
+}
+
+// notCached returns the images which are not ready in cache.
+func notCached(cache *ollamav1alpha1.ModelCache, images []string) []string {
+	ready := map[string]bool{}
+	for _, image := range cache.Status.Images {
+		if image.Phase == ollamav1alpha1.ModelCacheImageReady {
+			ready[ollama.NormalizeName(image.Name)] = true
+		}
+	}
+	var missing []string
+	for _, image := range images {
+		if !ready[ollama.NormalizeName(image)] {
+			missing = append(missing, image)
+		}
+	}
+	return missing
+}
+
+// modelCacheToModels maps a ModelCache to the Models in its namespace, which may read the models from it
+// by themselves or through their ModelClass.
+func (r *ModelReconciler) modelCacheToModels(ctx context.Context, obj client.Object) []ctrl.Request {
+	models := &ollamav1alpha1.ModelList{}
+	if err := r.List(ctx, models, client.InNamespace(obj.GetNamespace())); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "unable to list Models for the ModelCache", "modelCache", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+	requests := make([]ctrl.Request, 0, len(models.Items))
+	for _, model := range models.Items {
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&model)})
+	}
+	return requests
+}
@@ -0,0 +1,344 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+)
+
+const (
+	pullContainerName = "pull"
+	// pullBackoffLimit is the number of retries of a pull Job.
+	pullBackoffLimit = 3
+	// pullScript starts the ollama server in the background and pulls $IMAGE.
+	pullScript = `ollama serve >/dev/null 2>&1 & until ollama list >/dev/null 2>&1; do sleep 1; done; ollama pull "$IMAGE"`
+)
+
+// ModelCacheReconciler reconciles a ModelCache object
+type ModelCacheReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// OllamaContainerImage is the container image of the ollama server pulling the images if spec.runtime.image is not set.
+	OllamaContainerImage string
+	// AgentImage is the container image of the agent, which pulls the images if any registry is configured.
+	AgentImage string
+	// DefaultRegistry is the registry configuration applied to the pulls of all ModelCaches, which spec.registry is merged with.
+	DefaultRegistry *ollamav1alpha1.ModelRegistry
+	// PullScheduling queues the pulls of the Jobs for the pull scheduler, which limits the pulls running at once.
+	PullScheduling bool
+}
+
+// +kubebuilder:rbac:groups=ollama.sivchari.io,resources=modelcaches,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ollama.sivchari.io,resources=modelcaches/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
+
+// Reconcile pulls the images of the ModelCache into its PersistentVolumeClaim.
+// The images are pulled one by one by a Job per image, so the pulls never write the volume at the same time.
+func (r *ModelCacheReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	cache := &ollamav1alpha1.ModelCache{}
+	if err := r.Get(ctx, req.NamespacedName, cache); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	patch := client.MergeFrom(cache.DeepCopy())
+	defer func() {
+		if err := r.Status().Patch(ctx, cache, patch); err != nil {
+			ctrl.LoggerFrom(ctx).V(1).Error(err, "unable to update ModelCache status")
+		}
+	}()
+	return ctrl.Result{}, r.reconcileCache(ctx, cache)
+}
+
+func (r *ModelCacheReconciler) reconcileCache(ctx context.Context, cache *ollamav1alpha1.ModelCache) error {
+	if err := r.reconcileClaim(ctx, cache); err != nil {
+		return err
+	}
+	cache.Status.ClaimName = cache.Name
+
+	jobs := &batchv1.JobList{}
+	if err := r.List(ctx, jobs, client.InNamespace(cache.Namespace), client.MatchingLabels{ollamav1alpha1.ModelCacheNameLabel: cache.Name}); err != nil {
+		return err
+	}
+	byName := map[string]*batchv1.Job{}
+	for i := range jobs.Items {
+		if metav1.IsControlledBy(&jobs.Items[i], cache) {
+			byName[jobs.Items[i].Name] = &jobs.Items[i]
+		}
+	}
+
+	queued, err := r.queuedJobs(ctx, cache)
+	if err != nil {
+		return err
+	}
+
+	images := make([]ollamav1alpha1.ModelCacheImageStatus, 0, len(cache.Spec.Images))
+	pulling := false
+	for _, image := range cache.Spec.Images {
+		status := ollamav1alpha1.ModelCacheImageStatus{Name: image, JobName: pullJobName(cache, image)}
+		job, ok := byName[status.JobName]
+		delete(byName, status.JobName)
+		switch {
+		case ok:
+			status.Phase = pullJobPhase(job)
+			if status.Phase == ollamav1alpha1.ModelCacheImagePulling && queued[job.Name] {
+				status.Phase = ollamav1alpha1.ModelCacheImageQueued
+			}
+		case pulling:
+			status.Phase = ollamav1alpha1.ModelCacheImagePending
+			status.JobName = ""
+		default:
+			job, err := r.pullJob(cache, image, status.JobName)
+			if err != nil {
+				return err
+			}
+			if err := r.Create(ctx, job); err != nil {
+				return err
+			}
+			status.Phase = ollamav1alpha1.ModelCacheImagePulling
+		}
+		pulling = pulling || status.Phase == ollamav1alpha1.ModelCacheImagePulling || status.Phase == ollamav1alpha1.ModelCacheImageQueued
+		images = append(images, status)
+	}
+	cache.Status.Images = images
+
+	// The Jobs of the images removed from spec.images are deleted. Their models are kept in the volume.
+	for _, job := range byName {
+		if err := client.IgnoreNotFound(r.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))); err != nil {
+			return err
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               ollamav1alpha1.ModelCacheConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             ollamav1alpha1.ModelCacheCached,
+		ObservedGeneration: cache.Generation,
+	}
+	if failed := imagesInPhase(images, ollamav1alpha1.ModelCacheImageFailed); len(failed) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ollamav1alpha1.ModelCachePullFailed
+		condition.Message = fmt.Sprintf("failed to pull images: %s", strings.Join(failed, ", "))
+	} else if len(imagesInPhase(images, ollamav1alpha1.ModelCacheImageReady)) < len(images) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ollamav1alpha1.ModelCachePulling
+		if queued := imagesInPhase(images, ollamav1alpha1.ModelCacheImageQueued); len(queued) > 0 {
+			condition.Message = fmt.Sprintf("waiting for the pull scheduler to pull images: %s", strings.Join(queued, ", "))
+		}
+	}
+	meta.SetStatusCondition(&cache.Status.Conditions, condition)
+	return nil
+}
+
+// reconcileClaim creates the PersistentVolumeClaim of the ModelCache. The claim is never updated,
+// since most of its spec is immutable.
+func (r *ModelCacheReconciler) reconcileClaim(ctx context.Context, cache *ollamav1alpha1.ModelCache) error {
+	claim := &corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: cache.Namespace, Name: cache.Name}, claim)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+	storage := cache.Spec.Storage
+	size := defaultStorageSize
+	if storage.Size != nil {
+		size = *storage.Size
+	}
+	accessModes := storage.AccessModes
+	if len(accessModes) == 0 {
+		accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+	}
+	claim = &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cache.Namespace,
+			Name:      cache.Name,
+			Labels:    map[string]string{ollamav1alpha1.ModelCacheNameLabel: cache.Name},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      accessModes,
+			StorageClassName: storage.StorageClassName,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+		},
+	}
+	if err := controllerutil.SetControllerReference(cache, claim, r.Scheme); err != nil {
+		return err
+	}
+	return r.Create(ctx, claim)
+}
+
+func (r *ModelCacheReconciler) pullJob(cache *ollamav1alpha1.ModelCache, image, name string) (*batchv1.Job, error) {
+	labels := map[string]string{ollamav1alpha1.ModelCacheNameLabel: cache.Name}
+	runtimeImage := r.OllamaContainerImage
+	if runtimeImage == "" {
+		runtimeImage = defaultOllamaContainerImage
+	}
+	var imagePullPolicy corev1.PullPolicy
+	if cache.Spec.Runtime != nil {
+		if cache.Spec.Runtime.Image != "" {
+			runtimeImage = cache.Spec.Runtime.Image
+		}
+		imagePullPolicy = cache.Spec.Runtime.ImagePullPolicy
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: cache.Namespace,
+			Name:      name,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To[int32](pullBackoffLimit),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:            pullContainerName,
+							Image:           runtimeImage,
+							ImagePullPolicy: imagePullPolicy,
+							Command:         []string{"/bin/sh", "-c", pullScript},
+							Env:             []corev1.EnvVar{{Name: "IMAGE", Value: image}},
+							VolumeMounts:    []corev1.VolumeMount{{Name: storageVolumeName, MountPath: modelsPath}},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: storageVolumeName,
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: cache.Name},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	registry := mergeRegistry(r.DefaultRegistry, cache.Spec.Registry)
+	if registry != nil || len(ociSources(cache.Spec.Sources, []string{image})) > 0 {
+		// The agent applies the registry configuration and imports the sources, which the ollama server does not support.
+		container, volumes := pullContainer(r.AgentImage, registry, cache.Spec.Sources, nil, nil, "", []string{image})
+		container.Name = pullContainerName
+		spec := &job.Spec.Template.Spec
+		spec.Containers[0] = container
+		spec.Volumes = append(spec.Volumes, volumes...)
+	}
+	if r.PullScheduling {
+		injectPullPermit(r.AgentImage, &job.Spec.Template.ObjectMeta, &job.Spec.Template.Spec)
+	}
+	if err := controllerutil.SetControllerReference(cache, job, r.Scheme); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// pullJobName returns the name of the Job pulling image, which is unique among the images of the ModelCache
+// and within the length of a label value.
+func pullJobName(cache *ollamav1alpha1.ModelCache, image string) string {
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(ollama.NormalizeName(image)))
+	prefix := cache.Name
+	if len(prefix) > 52 {
+		prefix = prefix[:52]
+	}
+	return fmt.Sprintf("%s-%s", strings.TrimSuffix(prefix, "-"), rand.SafeEncodeString(fmt.Sprint(hasher.Sum32())))
+}
+
+// queuedJobs returns the names of the pull Jobs of the ModelCache whose pods wait for the pull scheduler.
+func (r *ModelCacheReconciler) queuedJobs(ctx context.Context, cache *ollamav1alpha1.ModelCache) (map[string]bool, error) {
+	if !r.PullScheduling {
+		return nil, nil
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(cache.Namespace), client.MatchingLabels{
+		ollamav1alpha1.ModelCacheNameLabel: cache.Name,
+		ollamav1alpha1.PullStateLabel:      ollamav1alpha1.PullStateQueued,
+	}); err != nil {
+		return nil, err
+	}
+	queued := map[string]bool{}
+	for _, pod := range pods.Items {
+		if !pullDone(&pod) {
+			queued[pod.Labels[batchv1.JobNameLabel]] = true
+		}
+	}
+	return queued, nil
+}
+
+func pullJobPhase(job *batchv1.Job) ollamav1alpha1.ModelCacheImagePhase {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return ollamav1alpha1.ModelCacheImageReady
+		case batchv1.JobFailed:
+			return ollamav1alpha1.ModelCacheImageFailed
+		}
+	}
+	return ollamav1alpha1.ModelCacheImagePulling
+}
+
+func imagesInPhase(images []ollamav1alpha1.ModelCacheImageStatus, phase ollamav1alpha1.ModelCacheImagePhase) []string {
+	var names []string
+	for _, image := range images {
+		if image.Phase == phase {
+			names = append(names, image.Name)
+		}
+	}
+	return names
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ModelCacheReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ollamav1alpha1.ModelCache{}).
+		Owns(&batchv1.Job{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		// The pods of the pull Jobs tell whether they wait for the pull scheduler.
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(podToModelCache)).
+		Named("modelcache").
+		Complete(r)
+}
+
+func podToModelCache(_ context.Context, obj client.Object) []ctrl.Request {
+	name, ok := obj.GetLabels()[ollamav1alpha1.ModelCacheNameLabel]
+	if !ok {
+		return nil
+	}
+	return []ctrl.Request{{NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: name}}}
+}
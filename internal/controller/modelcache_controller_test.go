@@ -0,0 +1,143 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestModelCacheReconcile(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(ollamav1alpha1.AddToScheme(scheme)).To(Succeed())
+	ctx := context.Background()
+
+	cache := &ollamav1alpha1.ModelCache{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "default", UID: "uid-shared"},
+		Spec:       ollamav1alpha1.ModelCacheSpec{Images: []string{"llama3:8b", "mistral"}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cache).WithStatusSubresource(cache).Build()
+	r := &ModelCacheReconciler{Client: c, Scheme: scheme}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cache)}
+	reconcile := func(g Gomega) *ollamav1alpha1.ModelCache {
+		_, err := r.Reconcile(ctx, req)
+		g.Expect(err).NotTo(HaveOccurred())
+		got := &ollamav1alpha1.ModelCache{}
+		g.Expect(c.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		return got
+	}
+	setJobCondition := func(g Gomega, name string, conditionType batchv1.JobConditionType) {
+		job := &batchv1.Job{}
+		g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, job)).To(Succeed())
+		job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{Type: conditionType, Status: corev1.ConditionTrue})
+		g.Expect(c.Status().Update(ctx, job)).To(Succeed())
+	}
+
+	t.Run("Should create the claim and pull the first image", func(t *testing.T) {
+		g := NewWithT(t)
+		got := reconcile(g)
+		claim := &corev1.PersistentVolumeClaim{}
+		g.Expect(c.Get(ctx, req.NamespacedName, claim)).To(Succeed())
+		g.Expect(claim.Spec.AccessModes).To(Equal([]corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}))
+		g.Expect(claim.Spec.Resources.Requests.Storage().String()).To(Equal("50Gi"))
+		g.Expect(got.Status.ClaimName).To(Equal("shared"))
+		g.Expect(got.Status.Images).To(HaveLen(2))
+		g.Expect(got.Status.Images[0].Phase).To(Equal(ollamav1alpha1.ModelCacheImagePulling))
+		g.Expect(got.Status.Images[1].Phase).To(Equal(ollamav1alpha1.ModelCacheImagePending))
+		g.Expect(meta.IsStatusConditionFalse(got.Status.Conditions, ollamav1alpha1.ModelCacheConditionReady)).To(BeTrue())
+
+		jobs := &batchv1.JobList{}
+		g.Expect(c.List(ctx, jobs)).To(Succeed())
+		g.Expect(jobs.Items).To(HaveLen(1))
+		container := jobs.Items[0].Spec.Template.Spec.Containers[0]
+		g.Expect(container.Image).To(Equal(defaultOllamaContainerImage))
+		g.Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "IMAGE", Value: "llama3:8b"}))
+		g.Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: storageVolumeName, MountPath: modelsPath}))
+	})
+
+	t.Run("Should pull the next image once the previous one is pulled", func(t *testing.T) {
+		g := NewWithT(t)
+		setJobCondition(g, pullJobName(cache, "llama3:8b"), batchv1.JobComplete)
+		got := reconcile(g)
+		g.Expect(got.Status.Images[0].Phase).To(Equal(ollamav1alpha1.ModelCacheImageReady))
+		g.Expect(got.Status.Images[1].Phase).To(Equal(ollamav1alpha1.ModelCacheImagePulling))
+		g.Expect(got.Status.Images[1].JobName).To(Equal(pullJobName(cache, "mistral")))
+	})
+
+	t.Run("Should report the failed pulls", func(t *testing.T) {
+		g := NewWithT(t)
+		setJobCondition(g, pullJobName(cache, "mistral"), batchv1.JobFailed)
+		got := reconcile(g)
+		g.Expect(got.Status.Images[1].Phase).To(Equal(ollamav1alpha1.ModelCacheImageFailed))
+		condition := meta.FindStatusCondition(got.Status.Conditions, ollamav1alpha1.ModelCacheConditionReady)
+		g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		g.Expect(condition.Reason).To(Equal(ollamav1alpha1.ModelCachePullFailed))
+		g.Expect(condition.Message).To(ContainSubstring("mistral"))
+	})
+
+	t.Run("Should be ready once all images are pulled and delete the Jobs of the removed images", func(t *testing.T) {
+		g := NewWithT(t)
+		got := &ollamav1alpha1.ModelCache{}
+		g.Expect(c.Get(ctx, req.NamespacedName, got)).To(Succeed())
+		got.Spec.Images = []string{"llama3:8b"}
+		g.Expect(c.Update(ctx, got)).To(Succeed())
+		got = reconcile(g)
+		g.Expect(got.Status.Images).To(HaveLen(1))
+		g.Expect(meta.IsStatusConditionTrue(got.Status.Conditions, ollamav1alpha1.ModelCacheConditionReady)).To(BeTrue())
+		jobs := &batchv1.JobList{}
+		g.Expect(c.List(ctx, jobs)).To(Succeed())
+		g.Expect(jobs.Items).To(HaveLen(1))
+		g.Expect(jobs.Items[0].Name).To(Equal(pullJobName(cache, "llama3:8b")))
+	})
+}
+
+func TestNotCached(t *testing.T) {
+	g := NewWithT(t)
+	cache := &ollamav1alpha1.ModelCache{
+		Status: ollamav1alpha1.ModelCacheStatus{
+			Images: []ollamav1alpha1.ModelCacheImageStatus{
+				{Name: "llama3", Phase: ollamav1alpha1.ModelCacheImageReady},
+				{Name: "mistral", Phase: ollamav1alpha1.ModelCacheImagePulling},
+			},
+		},
+	}
+	g.Expect(notCached(cache, []string{"registry.ollama.ai/library/llama3:latest", "mistral", "gemma"})).To(Equal([]string{"mistral", "gemma"}))
+}
+
+func TestPullJobName(t *testing.T) {
+	g := NewWithT(t)
+	cache := &ollamav1alpha1.ModelCache{ObjectMeta: metav1.ObjectMeta{Name: "a-very-long-name-of-the-model-cache-which-exceeds-the-limit"}}
+	name := pullJobName(cache, "llama3")
+	g.Expect(len(name)).To(BeNumerically("<=", 63))
+	g.Expect(name).To(Equal(pullJobName(cache, "registry.ollama.ai/library/llama3:latest")))
+	g.Expect(name).NotTo(Equal(pullJobName(cache, "mistral")))
+}
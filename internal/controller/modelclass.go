@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+// reconcileModelClass returns the ModelClass of the Model, that is the class named by spec.className or the default class.
+// It returns nil if the Model does not name a class and there is no default class. If the named class does not exist,
+// it reports false and the pods are not created until the class is created.
+func (r *ModelReconciler) reconcileModelClass(ctx context.Context, model *ollamav1alpha1.Model) (*ollamav1alpha1.ModelClass, bool, error) {
+	if model.Spec.ClassName == nil {
+		meta.RemoveStatusCondition(&model.Status.Conditions, ollamav1alpha1.ModelConditionClassResolved)
+		classes := &ollamav1alpha1.ModelClassList{}
+		if err := r.List(ctx, classes); err != nil {
+			return nil, false, err
+		}
+		return defaultModelClass(classes.Items), true, nil
+	}
+	name := *model.Spec.ClassName
+	class := &ollamav1alpha1.ModelClass{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, class); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, false, err
+		}
+		meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+			Type:               ollamav1alpha1.ModelConditionClassResolved,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: model.Generation,
+			Reason:             ollamav1alpha1.ModelClassNotFound,
+			Message:            fmt.Sprintf("ModelClass %q is not found", name),
+		})
+		return nil, false, nil
+	}
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:               ollamav1alpha1.ModelConditionClassResolved,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: model.Generation,
+		Reason:             ollamav1alpha1.ModelClassFound,
+	})
+	return class, true, nil
+}
+
+// defaultModelClass returns the default class among classes.
+// If more than one class is marked as the default, the oldest one is used.
+func defaultModelClass(classes []ollamav1alpha1.ModelClass) *ollamav1alpha1.ModelClass {
+	var found *ollamav1alpha1.ModelClass
+	for i := range classes {
+		class := &classes[i]
+		if class.Annotations[ollamav1alpha1.DefaultModelClassAnnotation] != "true" {
+			continue
+		}
+		if found == nil || class.CreationTimestamp.Before(&found.CreationTimestamp) ||
+			(class.CreationTimestamp.Equal(&found.CreationTimestamp) && class.Name < found.Name) {
+			found = class
+		}
+	}
+	return found
+}
+
+// withModelClass returns a copy of the Model whose spec is merged onto the defaults of class.
+// The merged spec is used to generate the pods and is never written back to the Model.
+func withModelClass(model *ollamav1alpha1.Model, class *ollamav1alpha1.ModelClass) *ollamav1alpha1.Model {
+	classed := model.DeepCopy()
+	if class == nil {
+		return classed
+	}
+	classed.Spec.Runtime = mergeRuntime(class.Spec.Runtime, classed.Spec.Runtime)
+	classed.Spec.Template = mergeTemplate(class.Spec.Template, classed.Spec.Template)
+	return classed
+}
+
+func mergeRuntime(class, model *ollamav1alpha1.ModelRuntime) *ollamav1alpha1.ModelRuntime {
+	if class == nil {
+		return model
+	}
+	merged := class.DeepCopy()
+	if model == nil {
+		return merged
+	}
+	if model.Image != "" {
+		merged.Image = model.Image
+	}
+	if model.ImagePullPolicy != "" {
+		merged.ImagePullPolicy = model.ImagePullPolicy
+	}
+	if model.Version != "" {
+		merged.Version = model.Version
+	}
+	return merged
+}
+
+func mergeTemplate(class, model *ollamav1alpha1.ModelTemplate) *ollamav1alpha1.ModelTemplate {
+	if class == nil {
+		return model
+	}
+	merged := class.DeepCopy()
+	if model == nil {
+		return merged
+	}
+	if model.Metadata != nil {
+		if merged.Metadata == nil {
+			merged.Metadata = &ollamav1alpha1.ObjectMeta{}
+		}
+		merged.Metadata.Labels = mergeMap(merged.Metadata.Labels, model.Metadata.Labels)
+		merged.Metadata.Annotations = mergeMap(merged.Metadata.Annotations, model.Metadata.Annotations)
+	}
+	if model.Spec != nil {
+		merged.Spec = mergeTemplateSpec(merged.Spec, model.Spec)
+	}
+	if model.PodOverride != nil {
+		merged.PodOverride = model.PodOverride.DeepCopy()
+	}
+	return merged
+}
+
+func mergeTemplateSpec(class, model *ollamav1alpha1.ModelTemplateSpec) *ollamav1alpha1.ModelTemplateSpec {
+	if class == nil {
+		return model.DeepCopy()
+	}
+	merged := class.DeepCopy()
+	model = model.DeepCopy()
+	merged.Volumes = mergeByKey(merged.Volumes, model.Volumes, func(v corev1.Volume) string { return v.Name })
+	merged.VolumeMounts = mergeByKey(merged.VolumeMounts, model.VolumeMounts, func(m corev1.VolumeMount) string { return m.MountPath })
+	merged.NodeSelector = mergeMap(merged.NodeSelector, model.NodeSelector)
+	if model.Affinity != nil {
+		merged.Affinity = model.Affinity
+	}
+	if model.Tolerations != nil {
+		merged.Tolerations = model.Tolerations
+	}
+	if model.TopologySpreadConstraints != nil {
+		merged.TopologySpreadConstraints = model.TopologySpreadConstraints
+	}
+	if model.Resources != nil {
+		merged.Resources = model.Resources
+	}
+	if model.Storage != nil {
+		if merged.Storage == nil {
+			merged.Storage = &ollamav1alpha1.ModelStorage{}
+		}
+		if model.Storage.StorageClassName != nil {
+			merged.Storage.StorageClassName = model.Storage.StorageClassName
+		}
+		if model.Storage.Size != nil {
+			merged.Storage.Size = model.Storage.Size
+		}
+		if model.Storage.NodeCache != nil {
+			merged.Storage.NodeCache = model.Storage.NodeCache
+		}
+		if model.Storage.CacheName != nil {
+			merged.Storage.CacheName = model.Storage.CacheName
+		}
+	}
+	return merged
+}
+
+// mergeMap returns the union of class and model. The values of model take precedence.
+func mergeMap(class, model map[string]string) map[string]string {
+	if class == nil && model == nil {
+		return nil
+	}
+	merged := maps.Clone(class)
+	if merged == nil {
+		merged = map[string]string{}
+	}
+	maps.Copy(merged, model)
+	return merged
+}
+
+// mergeByKey returns the items of class which are not replaced by an item of model with the same key, followed by the items of model.
+func mergeByKey[T any](class, model []T, key func(T) string) []T {
+	if len(class) == 0 {
+		return model
+	}
+	merged := slices.DeleteFunc(slices.Clone(class), func(item T) bool {
+		return slices.ContainsFunc(model, func(m T) bool { return key(m) == key(item) })
+	})
+	return append(merged, model...)
+}
+
+// modelClassToModels maps a ModelClass to the Models which use it, so a Model waiting for its class is reconciled
+// once the class is created. The Models which do not name a class are enqueued as well, since the default class
+// may have been changed.
+func (r *ModelReconciler) modelClassToModels(ctx context.Context, obj client.Object) []ctrl.Request {
+	models := &ollamav1alpha1.ModelList{}
+	if err := r.List(ctx, models); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "unable to list Models for the ModelClass", "modelClass", obj.GetName())
+		return nil
+	}
+	var requests []ctrl.Request
+	for _, model := range models.Items {
+		if model.Spec.ClassName != nil && *model.Spec.ClassName != obj.GetName() {
+			continue
+		}
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&model)})
+	}
+	return requests
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestDefaultModelClass(t *testing.T) {
+	now := time.Now()
+	newClass := func(name string, created time.Time, isDefault bool) ollamav1alpha1.ModelClass {
+		class := ollamav1alpha1.ModelClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+		}
+		if isDefault {
+			class.Annotations = map[string]string{ollamav1alpha1.DefaultModelClassAnnotation: "true"}
+		}
+		return class
+	}
+
+	t.Run("Should return the class annotated as the default", func(t *testing.T) {
+		g := NewWithT(t)
+		class := defaultModelClass([]ollamav1alpha1.ModelClass{
+			newClass("gpu", now, false),
+			newClass("cpu", now, true),
+		})
+		g.Expect(class).NotTo(BeNil())
+		g.Expect(class.Name).To(Equal("cpu"))
+	})
+
+	t.Run("Should return the oldest default class", func(t *testing.T) {
+		g := NewWithT(t)
+		class := defaultModelClass([]ollamav1alpha1.ModelClass{
+			newClass("new", now, true),
+			newClass("old", now.Add(-time.Hour), true),
+		})
+		g.Expect(class).NotTo(BeNil())
+		g.Expect(class.Name).To(Equal("old"))
+	})
+
+	t.Run("Should return nil without the default class", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(defaultModelClass([]ollamav1alpha1.ModelClass{newClass("gpu", now, false)})).To(BeNil())
+	})
+}
+
+func TestReconcileModelClass(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	NewWithT(t).Expect(ollamav1alpha1.AddToScheme(scheme)).To(Succeed())
+	newModel := func(name string, className *string) *ollamav1alpha1.Model {
+		return &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       ollamav1alpha1.ModelSpec{Images: []string{"llama3"}, ClassName: className},
+		}
+	}
+
+	t.Run("Should report the missing class and resolve it once the class is created", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel("model", ptr.To("gpu"))
+		other := newModel("other", ptr.To("cpu"))
+		r := &ModelReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(model.DeepCopy(), other).Build(), Scheme: scheme}
+
+		class, resolved, err := r.reconcileModelClass(ctx, model)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(resolved).To(BeFalse())
+		g.Expect(class).To(BeNil())
+		condition := meta.FindStatusCondition(model.Status.Conditions, ollamav1alpha1.ModelConditionClassResolved)
+		g.Expect(condition).NotTo(BeNil())
+		g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		g.Expect(condition.Reason).To(Equal(ollamav1alpha1.ModelClassNotFound))
+
+		// The creation of the class enqueues the Model waiting for it.
+		gpu := &ollamav1alpha1.ModelClass{ObjectMeta: metav1.ObjectMeta{Name: "gpu"}}
+		g.Expect(r.Create(ctx, gpu)).To(Succeed())
+		g.Expect(r.modelClassToModels(ctx, gpu)).To(ConsistOf(ctrl.Request{NamespacedName: client.ObjectKeyFromObject(model)}))
+
+		class, resolved, err = r.reconcileModelClass(ctx, model)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(resolved).To(BeTrue())
+		g.Expect(class.Name).To(Equal("gpu"))
+		g.Expect(meta.IsStatusConditionTrue(model.Status.Conditions, ollamav1alpha1.ModelConditionClassResolved)).To(BeTrue())
+	})
+
+	t.Run("Should not report the condition for the Model which does not name a class", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel("model", nil)
+		model.Status.Conditions = []metav1.Condition{{Type: ollamav1alpha1.ModelConditionClassResolved, Status: metav1.ConditionFalse}}
+		r := &ModelReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(model.DeepCopy()).Build(), Scheme: scheme}
+
+		class, resolved, err := r.reconcileModelClass(ctx, model)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(resolved).To(BeTrue())
+		g.Expect(class).To(BeNil())
+		g.Expect(meta.FindStatusCondition(model.Status.Conditions, ollamav1alpha1.ModelConditionClassResolved)).To(BeNil())
+	})
+}
+
+func TestWithModelClass(t *testing.T) {
+	class := &ollamav1alpha1.ModelClass{
+		Spec: ollamav1alpha1.ModelClassSpec{
+			Runtime: &ollamav1alpha1.ModelRuntime{
+				Image:           "ollama/ollama:0.6.2",
+				ImagePullPolicy: corev1.PullIfNotPresent,
+			},
+			Template: &ollamav1alpha1.ModelTemplate{
+				Metadata: &ollamav1alpha1.ObjectMeta{Labels: map[string]string{"team": "ml", "tier": "shared"}},
+				Spec: &ollamav1alpha1.ModelTemplateSpec{
+					NodeSelector: map[string]string{"accelerator": "nvidia"},
+					Tolerations:  []corev1.Toleration{{Key: "nvidia.com/gpu", Operator: corev1.TolerationOpExists}},
+					Volumes: []corev1.Volume{
+						{Name: "cache", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+						{Name: "config", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+					},
+					Resources: &corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")},
+					},
+					Storage: &ollamav1alpha1.ModelStorage{
+						StorageClassName: ptr.To("fast"),
+						Size:             ptr.To(resource.MustParse("100Gi")),
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("Should use the defaults of the class", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{Spec: ollamav1alpha1.ModelSpec{Images: []string{"llama3"}}}
+		classed := withModelClass(model, class)
+		g.Expect(classed.Spec.Runtime).To(Equal(class.Spec.Runtime))
+		g.Expect(classed.Spec.Template).To(Equal(class.Spec.Template))
+		g.Expect(model.Spec.Runtime).To(BeNil())
+		g.Expect(model.Spec.Template).To(BeNil())
+	})
+
+	t.Run("Should merge the Model onto the defaults of the class", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			Spec: ollamav1alpha1.ModelSpec{
+				Images:  []string{"llama3"},
+				Runtime: &ollamav1alpha1.ModelRuntime{Version: ">=0.6.0"},
+				Template: &ollamav1alpha1.ModelTemplate{
+					Metadata: &ollamav1alpha1.ObjectMeta{Labels: map[string]string{"tier": "dedicated"}},
+					Spec: &ollamav1alpha1.ModelTemplateSpec{
+						NodeSelector: map[string]string{"zone": "a"},
+						Tolerations:  []corev1.Toleration{},
+						Volumes: []corev1.Volume{
+							{Name: "cache", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/cache"}}},
+						},
+						Storage: &ollamav1alpha1.ModelStorage{Size: ptr.To(resource.MustParse("10Gi"))},
+					},
+				},
+			},
+		}
+		classed := withModelClass(model, class)
+		g.Expect(classed.Spec.Runtime).To(Equal(&ollamav1alpha1.ModelRuntime{
+			Image:           "ollama/ollama:0.6.2",
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Version:         ">=0.6.0",
+		}))
+		g.Expect(classed.Spec.Template.Metadata.Labels).To(Equal(map[string]string{"team": "ml", "tier": "dedicated"}))
+		spec := classed.Spec.Template.Spec
+		g.Expect(spec.NodeSelector).To(Equal(map[string]string{"accelerator": "nvidia", "zone": "a"}))
+		g.Expect(spec.Tolerations).To(BeEmpty())
+		g.Expect(spec.Volumes).To(HaveLen(2))
+		g.Expect(spec.Volumes[0].Name).To(Equal("config"))
+		g.Expect(spec.Volumes[1].HostPath).NotTo(BeNil())
+		g.Expect(spec.Resources).To(Equal(class.Spec.Template.Spec.Resources))
+		g.Expect(spec.Storage.StorageClassName).To(Equal(ptr.To("fast")))
+		g.Expect(spec.Storage.Size.String()).To(Equal("10Gi"))
+	})
+
+	t.Run("Should generate the pod with the resources and the storage of the class", func(t *testing.T) {
+		g := NewWithT(t)
+		r := &ModelReconciler{}
+		model := &ollamav1alpha1.Model{Spec: ollamav1alpha1.ModelSpec{Images: []string{"llama3"}}}
+		pod := &corev1.Pod{}
+		g.Expect(r.modelToPod(withModelClass(model, class), pod)).To(Succeed())
+		container := pod.Spec.Containers[0]
+		g.Expect(container.Image).To(Equal("ollama/ollama:0.6.2"))
+		g.Expect(container.Resources.Limits).To(HaveKey(corev1.ResourceName("nvidia.com/gpu")))
+		g.Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: storageVolumeName, MountPath: modelsPath}))
+		volume := pod.Spec.Volumes[len(pod.Spec.Volumes)-1]
+		g.Expect(volume.Name).To(Equal(storageVolumeName))
+		g.Expect(volume.Ephemeral).NotTo(BeNil())
+		claim := volume.Ephemeral.VolumeClaimTemplate.Spec
+		g.Expect(claim.StorageClassName).To(Equal(ptr.To("fast")))
+		g.Expect(claim.Resources.Requests.Storage().String()).To(Equal("100Gi"))
+	})
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+// ModelQuotaReconciler reconciles a ModelQuota object
+type ModelQuotaReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=ollama.sivchari.io,resources=modelquotas,verbs=get;list;watch
+// +kubebuilder:rbac:groups=ollama.sivchari.io,resources=modelquotas/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=ollama.sivchari.io,resources=models,verbs=get;list;watch
+
+// Reconcile reports the usage of the Models within the ModelQuota.
+// The ModelQuota is enforced by the Model reconciler and the admission webhook of the Models.
+func (r *ModelQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	modelQuota := &ollamav1alpha1.ModelQuota{}
+	if err := r.Get(ctx, req.NamespacedName, modelQuota); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	quotas := &ollamav1alpha1.ModelQuotaList{}
+	if err := r.List(ctx, quotas, client.InNamespace(modelQuota.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	result, err := evaluateQuotas(ctx, r.Client, modelQuota.Namespace, quotas.Items)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	patch := client.MergeFrom(modelQuota.DeepCopy())
+	modelQuota.Status.Hard = modelQuota.Spec.Hard
+	modelQuota.Status.Used = result.Used[modelQuota.Name]
+	return ctrl.Result{}, r.Status().Patch(ctx, modelQuota, patch)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ModelQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ollamav1alpha1.ModelQuota{}).
+		Watches(
+			&ollamav1alpha1.Model{},
+			handler.EnqueueRequestsFromMapFunc(r.modelToModelQuotas),
+		).
+		Named("modelquota").
+		Complete(r)
+}
+
+// modelToModelQuotas maps a Model to the ModelQuotas in its namespace.
+// The sizes are known from the Models in all namespaces, but the usage is updated by the next change in the namespace.
+func (r *ModelQuotaReconciler) modelToModelQuotas(ctx context.Context, obj client.Object) []ctrl.Request {
+	quotas := &ollamav1alpha1.ModelQuotaList{}
+	if err := r.List(ctx, quotas, client.InNamespace(obj.GetNamespace())); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "unable to list ModelQuotas for the Model", "model", obj.GetName())
+		return nil
+	}
+	requests := make([]ctrl.Request, 0, len(quotas.Items))
+	for _, q := range quotas.Items {
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&q)})
+	}
+	return requests
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+	"github.com/sivchari/ollama-operator/internal/pull"
+)
+
+// controlPlaneLabel is the label which tells the operator components apart.
+const controlPlaneLabel = "control-plane"
+
+// controllerManager is the controlPlaneLabel of the pods of the controller manager.
+const controllerManager = "controller-manager"
+
+// reconcileNetworkPolicy ensures the NetworkPolicy which has the same name as the Model
+// and restricts the traffic of its serving pods to spec.network.
+func (r *ModelReconciler) reconcileNetworkPolicy(ctx context.Context, model *ollamav1alpha1.Model) error {
+	np := &networkingv1.NetworkPolicy{}
+	np.Namespace = model.Namespace
+	np.Name = model.Name
+
+	if model.Spec.Network == nil {
+		return r.deleteOwned(ctx, model, np)
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, np, func() error {
+		if np.Labels == nil {
+			np.Labels = map[string]string{}
+		}
+		np.Labels[ollamav1alpha1.ModelNameLabel] = model.Name
+		np.Spec = r.networkPolicySpec(model)
+		return controllerutil.SetControllerReference(model, np, r.Scheme)
+	})
+	return err
+}
+
+func (r *ModelReconciler) networkPolicySpec(model *ollamav1alpha1.Model) networkingv1.NetworkPolicySpec {
+	spec := networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{
+			MatchLabels: map[string]string{ollamav1alpha1.ModelNameLabel: model.Name},
+		},
+		PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		Ingress: []networkingv1.NetworkPolicyIngressRule{
+			{From: []networkingv1.NetworkPolicyPeer{r.operatorPeer()}},
+		},
+		Egress: []networkingv1.NetworkPolicyEgressRule{
+			{
+				Ports: []networkingv1.NetworkPolicyPort{
+					{Protocol: ptr.To(corev1.ProtocolUDP), Port: ptr.To(intstr.FromInt32(53))},
+					{Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(intstr.FromInt32(53))},
+				},
+			},
+		},
+	}
+	if len(model.Spec.Network.Ingress) > 0 {
+		spec.Ingress = append(spec.Ingress, networkingv1.NetworkPolicyIngressRule{
+			From: model.Spec.Network.Ingress,
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(intstr.FromInt32(ollama.DefaultPort))},
+			},
+		})
+	}
+	if peerSeeding(model) {
+		// The pods of the Model copy the blobs from each other.
+		peer := []networkingv1.NetworkPolicyPeer{{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{ollamav1alpha1.ModelNameLabel: model.Name}},
+		}}
+		ports := []networkingv1.NetworkPolicyPort{
+			{Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(intstr.FromInt32(pull.SeedPort))},
+		}
+		spec.Ingress = append(spec.Ingress, networkingv1.NetworkPolicyIngressRule{From: peer, Ports: ports})
+		spec.Egress = append(spec.Egress, networkingv1.NetworkPolicyEgressRule{To: peer, Ports: ports})
+	}
+	if len(model.Spec.Network.Egress) > 0 {
+		spec.Egress = append(spec.Egress, model.Spec.Network.Egress...)
+	} else {
+		spec.Egress = append(spec.Egress, networkingv1.NetworkPolicyEgressRule{
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(intstr.FromInt32(443))},
+			},
+		})
+	}
+	return spec
+}
+
+// operatorPeer returns the peer which matches the pods of the controller manager, which checks the ollama server and
+// scrapes the load of the pods. The activator and the gateway forward the requests of any client, so they are not
+// matched and have to be allowed by spec.network.ingress like the other clients.
+func (r *ModelReconciler) operatorPeer() networkingv1.NetworkPolicyPeer {
+	peer := networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{controlPlaneLabel: controllerManager},
+		},
+		NamespaceSelector: &metav1.LabelSelector{},
+	}
+	if r.OperatorNamespace != "" {
+		peer.NamespaceSelector.MatchLabels = map[string]string{corev1.LabelMetadataName: r.OperatorNamespace}
+	}
+	return peer
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestNetworkPolicySpec(t *testing.T) {
+	r := &ModelReconciler{OperatorNamespace: "ollama-operator-system"}
+	newModel := func(network *ollamav1alpha1.ModelNetwork) *ollamav1alpha1.Model {
+		return &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{Name: "model", Namespace: "default"},
+			Spec:       ollamav1alpha1.ModelSpec{Network: network},
+		}
+	}
+	clients := networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "ml"}},
+	}
+
+	t.Run("Should only allow the operator and the registry by default", func(t *testing.T) {
+		g := NewWithT(t)
+		spec := r.networkPolicySpec(newModel(&ollamav1alpha1.ModelNetwork{}))
+		g.Expect(spec.PodSelector.MatchLabels).To(Equal(map[string]string{ollamav1alpha1.ModelNameLabel: "model"}))
+		g.Expect(spec.Ingress).To(HaveLen(1))
+		g.Expect(spec.Ingress[0].From).To(ConsistOf(networkingv1.NetworkPolicyPeer{
+			PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{controlPlaneLabel: controllerManager}},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: "ollama-operator-system"}},
+		}))
+		g.Expect(spec.Egress).To(HaveLen(2))
+		g.Expect(spec.Egress[1].Ports).To(ConsistOf(networkingv1.NetworkPolicyPort{
+			Protocol: ptr.To(corev1.ProtocolTCP),
+			Port:     ptr.To(intstr.FromInt32(443)),
+		}))
+	})
+
+	t.Run("Should not admit the operator components forwarding the requests of the clients", func(t *testing.T) {
+		g := NewWithT(t)
+		spec := r.networkPolicySpec(newModel(&ollamav1alpha1.ModelNetwork{}))
+		namespace := labels.Set{corev1.LabelMetadataName: "ollama-operator-system"}
+		admitted := func(pod labels.Set) bool {
+			for _, rule := range spec.Ingress {
+				for _, peer := range rule.From {
+					podSelector, err := metav1.LabelSelectorAsSelector(peer.PodSelector)
+					g.Expect(err).NotTo(HaveOccurred())
+					namespaceSelector, err := metav1.LabelSelectorAsSelector(peer.NamespaceSelector)
+					g.Expect(err).NotTo(HaveOccurred())
+					if podSelector.Matches(pod) && namespaceSelector.Matches(namespace) {
+						return true
+					}
+				}
+			}
+			return false
+		}
+		g.Expect(admitted(labels.Set{"control-plane": "controller-manager", "app.kubernetes.io/name": "ollama-operator"})).To(BeTrue())
+		g.Expect(admitted(labels.Set{"control-plane": "gateway", "app.kubernetes.io/name": "ollama-operator"})).To(BeFalse())
+		g.Expect(admitted(labels.Set{"control-plane": "activator", "app.kubernetes.io/name": "ollama-operator"})).To(BeFalse())
+	})
+
+	t.Run("Should allow the clients and the egress of the Model", func(t *testing.T) {
+		g := NewWithT(t)
+		registry := networkingv1.NetworkPolicyEgressRule{
+			To: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/24"}}},
+		}
+		spec := r.networkPolicySpec(newModel(&ollamav1alpha1.ModelNetwork{
+			Ingress: []networkingv1.NetworkPolicyPeer{clients},
+			Egress:  []networkingv1.NetworkPolicyEgressRule{registry},
+		}))
+		g.Expect(spec.Ingress).To(HaveLen(2))
+		g.Expect(spec.Ingress[1].From).To(ConsistOf(clients))
+		g.Expect(spec.Ingress[1].Ports).To(ConsistOf(networkingv1.NetworkPolicyPort{
+			Protocol: ptr.To(corev1.ProtocolTCP),
+			Port:     ptr.To(intstr.FromInt32(11434)),
+		}))
+		g.Expect(spec.Egress).To(HaveLen(2))
+		g.Expect(spec.Egress[1]).To(Equal(registry))
+	})
+
+	t.Run("Should allow the seeders between the pods of the Model", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel(&ollamav1alpha1.ModelNetwork{})
+		model.Spec.PeerSeeding = ptr.To(true)
+		spec := r.networkPolicySpec(model)
+		peer := networkingv1.NetworkPolicyPeer{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{ollamav1alpha1.ModelNameLabel: "model"}},
+		}
+		port := networkingv1.NetworkPolicyPort{Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(intstr.FromInt32(11436))}
+		g.Expect(spec.Ingress).To(ContainElement(networkingv1.NetworkPolicyIngressRule{
+			From:  []networkingv1.NetworkPolicyPeer{peer},
+			Ports: []networkingv1.NetworkPolicyPort{port},
+		}))
+		g.Expect(spec.Egress).To(ContainElement(networkingv1.NetworkPolicyEgressRule{
+			To:    []networkingv1.NetworkPolicyPeer{peer},
+			Ports: []networkingv1.NetworkPolicyPort{port},
+		}))
+	})
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// applyPodOverride merges override onto pod with strategic merge patch semantics.
+// The fields owned by the operator are restored from the generated pod after merging.
+func applyPodOverride(pod *corev1.Pod, override *runtime.RawExtension) error {
+	if override == nil || len(override.Raw) == 0 {
+		return nil
+	}
+	original, err := json.Marshal(pod)
+	if err != nil {
+		return err
+	}
+	merged, err := strategicpatch.StrategicMergePatch(original, override.Raw, corev1.Pod{})
+	if err != nil {
+		return fmt.Errorf("failed to apply podOverride: %w", err)
+	}
+	overridden := &corev1.Pod{}
+	if err := json.Unmarshal(merged, overridden); err != nil {
+		return fmt.Errorf("failed to apply podOverride: %w", err)
+	}
+	protectPodFields(pod, overridden)
+	*pod = *overridden
+	return nil
+}
+
+// protectPodFields restores the fields owned by the operator from generated into overridden.
+func protectPodFields(generated, overridden *corev1.Pod) {
+	labels, annotations := overridden.Labels, overridden.Annotations
+	overridden.ObjectMeta = *generated.ObjectMeta.DeepCopy()
+	overridden.Labels = labels
+	overridden.Annotations = annotations
+	overridden.Status = *generated.Status.DeepCopy()
+
+	// All containers of the generated pod are owned by the operator.
+	for i := len(generated.Spec.Containers) - 1; i >= 0; i-- {
+		protectContainer(overridden, &generated.Spec.Containers[i])
+	}
+}
+
+// protectContainer restores the fields of the container owned by the operator.
+// The container is added back if it is removed by the override.
+func protectContainer(overridden *corev1.Pod, want *corev1.Container) {
+	for i := range overridden.Spec.Containers {
+		c := &overridden.Spec.Containers[i]
+		if c.Name != want.Name {
+			continue
+		}
+		c.Image = want.Image
+		c.Command = want.Command
+		c.Args = want.Args
+		c.Ports = want.Ports
+		c.Lifecycle = want.Lifecycle
+		for _, env := range want.Env {
+			c.Env = setEnv(c.Env, env)
+		}
+		return
+	}
+	overridden.Spec.Containers = append([]corev1.Container{*want.DeepCopy()}, overridden.Spec.Containers...)
+}
+
+// setEnv sets env in envs, replacing the existing variable which has the same name.
+func setEnv(envs []corev1.EnvVar, env corev1.EnvVar) []corev1.EnvVar {
+	for i := range envs {
+		if envs[i].Name == env.Name {
+			envs[i] = env
+			return envs
+		}
+	}
+	return append(envs, env)
+}
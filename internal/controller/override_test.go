@@ -0,0 +1,155 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestModelToPodWithPodOverride(t *testing.T) {
+	r := &ModelReconciler{OllamaContainerImage: "ollama/ollama:latest"}
+	newModel := func(override string) *ollamav1alpha1.Model {
+		return &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{Name: "model", Namespace: "default"},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+				Template: &ollamav1alpha1.ModelTemplate{
+					Metadata: &ollamav1alpha1.ObjectMeta{
+						Labels: map[string]string{"app": "ollama"},
+					},
+					PodOverride: &runtime.RawExtension{Raw: []byte(override)},
+				},
+			},
+		}
+	}
+	newPod := func() *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", GenerateName: "model-"},
+		}
+	}
+
+	t.Run("Should set pod fields which are not exposed by the template", func(t *testing.T) {
+		g := NewWithT(t)
+		pod := newPod()
+		g.Expect(r.modelToPod(newModel(`{
+			"spec": {
+				"serviceAccountName": "ollama",
+				"priorityClassName": "high",
+				"runtimeClassName": "nvidia",
+				"imagePullSecrets": [{"name": "regcred"}],
+				"hostAliases": [{"ip": "10.0.0.1", "hostnames": ["registry.local"]}],
+				"securityContext": {"runAsNonRoot": true}
+			}
+		}`), pod)).To(Succeed())
+		g.Expect(pod.Spec.ServiceAccountName).To(Equal("ollama"))
+		g.Expect(pod.Spec.PriorityClassName).To(Equal("high"))
+		g.Expect(pod.Spec.RuntimeClassName).To(Equal(ptr.To("nvidia")))
+		g.Expect(pod.Spec.ImagePullSecrets).To(Equal([]corev1.LocalObjectReference{{Name: "regcred"}}))
+		g.Expect(pod.Spec.HostAliases).To(Equal([]corev1.HostAlias{{IP: "10.0.0.1", Hostnames: []string{"registry.local"}}}))
+		g.Expect(pod.Spec.SecurityContext.RunAsNonRoot).To(Equal(ptr.To(true)))
+	})
+
+	t.Run("Should add init containers and sidecars", func(t *testing.T) {
+		g := NewWithT(t)
+		pod := newPod()
+		g.Expect(r.modelToPod(newModel(`{
+			"spec": {
+				"initContainers": [{"name": "init", "image": "busybox"}],
+				"containers": [{"name": "sidecar", "image": "envoy"}]
+			}
+		}`), pod)).To(Succeed())
+		g.Expect(pod.Spec.InitContainers).To(HaveLen(1))
+		g.Expect(pod.Spec.InitContainers[0].Name).To(Equal("init"))
+		g.Expect(pod.Spec.Containers).To(HaveLen(2))
+		g.Expect(pod.Spec.Containers[0].Name).To(Equal("sidecar"))
+		g.Expect(pod.Spec.Containers[1].Name).To(Equal(ollamaServerContainerName))
+		g.Expect(pod.Spec.Containers[1].Image).To(Equal("ollama/ollama:latest"))
+	})
+
+	t.Run("Should merge the ollama-server container but protect the operator owned fields", func(t *testing.T) {
+		g := NewWithT(t)
+		pod := newPod()
+		g.Expect(r.modelToPod(newModel(`{
+			"spec": {
+				"containers": [{
+					"name": "ollama-server",
+					"image": "evil/ollama",
+					"args": ["run"],
+					"resources": {"limits": {"nvidia.com/gpu": "1"}},
+					"env": [
+						{"name": "OLLAMA_HOST", "value": "127.0.0.1:8080"},
+						{"name": "OLLAMA_KEEP_ALIVE", "value": "24h"}
+					]
+				}]
+			}
+		}`), pod)).To(Succeed())
+		g.Expect(pod.Spec.Containers).To(HaveLen(1))
+		c := pod.Spec.Containers[0]
+		g.Expect(c.Image).To(Equal("ollama/ollama:latest"))
+		g.Expect(c.Args).To(Equal([]string{"serve"}))
+		g.Expect(c.Lifecycle.PostStart.Exec.Command).To(Equal([]string{"/bin/sh", "-c", "ollama show llama3 >/dev/null 2>&1 || ollama pull llama3;"}))
+		g.Expect(c.Resources.Limits).To(HaveKey(corev1.ResourceName("nvidia.com/gpu")))
+		g.Expect(c.Env).To(ConsistOf(
+			corev1.EnvVar{Name: "OLLAMA_HOST", Value: "0.0.0.0:11434"},
+			corev1.EnvVar{Name: "OLLAMA_KEEP_ALIVE", Value: "24h"},
+		))
+	})
+
+	t.Run("Should keep the ollama-server container when the containers are replaced", func(t *testing.T) {
+		g := NewWithT(t)
+		pod := newPod()
+		g.Expect(r.modelToPod(newModel(`{
+			"spec": {
+				"containers": [{"$patch": "replace"}, {"name": "sidecar", "image": "envoy"}]
+			}
+		}`), pod)).To(Succeed())
+		g.Expect(pod.Spec.Containers).To(HaveLen(2))
+		g.Expect(pod.Spec.Containers[0].Name).To(Equal(ollamaServerContainerName))
+		g.Expect(pod.Spec.Containers[1].Name).To(Equal("sidecar"))
+	})
+
+	t.Run("Should merge labels and annotations but protect the pod identity", func(t *testing.T) {
+		g := NewWithT(t)
+		pod := newPod()
+		g.Expect(r.modelToPod(newModel(`{
+			"metadata": {
+				"name": "other",
+				"namespace": "other",
+				"labels": {"team": "ml"},
+				"annotations": {"example.com/owner": "ml"}
+			}
+		}`), pod)).To(Succeed())
+		g.Expect(pod.Name).To(BeEmpty())
+		g.Expect(pod.Namespace).To(Equal("default"))
+		g.Expect(pod.GenerateName).To(Equal("model-"))
+		g.Expect(pod.Labels).To(Equal(map[string]string{"app": "ollama", "team": "ml"}))
+		g.Expect(pod.Annotations).To(Equal(map[string]string{"example.com/owner": "ml"}))
+	})
+
+	t.Run("Should fail when the override is malformed", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(r.modelToPod(newModel(`{"spec": {"containers": [{"image": "envoy"}]}}`), newPod())).NotTo(Succeed())
+	})
+}
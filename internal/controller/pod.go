@@ -2,88 +2,326 @@ package controller
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
 	"text/template"
 
-	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/podutil"
 )
 
+const (
+	ollamaServerContainerName = "ollama-server"
+)
+
+// reconcilePod rolls out spec.replicas serving pods of the Model.
+// While the pods are replaced, the outdated ready pods keep serving until the same number of up-to-date pods
+// are ready and run a satisfying runtime. At most one pod is surged above the replicas.
 func (r *ModelReconciler) reconcilePod(ctx context.Context, model *ollamav1alpha1.Model) error {
-	pod := &corev1.Pod{}
-	var name string
-	if model.Status.PodRef != nil {
-		name = model.Status.PodRef.Name
+	desired, err := r.desiredPod(ctx, model)
+	if err != nil {
+		return err
+	}
+	pods, err := r.listPods(ctx, model)
+	if err != nil {
+		return err
+	}
+	replicas := int(ptr.Deref(model.Spec.Replicas, 1))
+	hash := desired.Labels[ollamav1alpha1.PodTemplateHashLabel]
+
+	var updated, outdated []*corev1.Pod
+	for _, pod := range pods {
+		switch {
+		case !pod.DeletionTimestamp.IsZero():
+		case pod.Labels[ollamav1alpha1.PodTemplateHashLabel] == hash:
+			updated = append(updated, pod)
+		case podutil.IsReady(pod):
+			outdated = append(outdated, pod)
+		default:
+			// The outdated pod does not serve anything, so it is replaced immediately.
+			if err := r.deletePod(ctx, pod); err != nil {
+				return err
+			}
+		}
+	}
+	// The pods which are not ready are removed first when scaling down.
+	slices.SortStableFunc(updated, func(a, b *corev1.Pod) int {
+		return cmp.Compare(podRank(b), podRank(a))
+	})
+	for len(updated) > replicas {
+		if err := r.deletePod(ctx, updated[len(updated)-1]); err != nil {
+			return err
+		}
+		updated = updated[:len(updated)-1]
+	}
+	surge := 0
+	if len(outdated) > 0 {
+		surge = 1
+	}
+	for len(updated) < replicas && len(updated)+len(outdated) < replicas+surge {
+		pod, err := r.createPod(ctx, desired)
+		if err != nil {
+			return err
+		}
+		updated = append(updated, pod)
+	}
+
+	var available []*corev1.Pod
+	blocked := false
+	for _, pod := range updated {
+		if !podutil.IsReady(pod) {
+			continue
+		}
+		if len(outdated) > 0 {
+			ok, err := r.checkRuntime(ctx, model, pod)
+			if err != nil {
+				ctrl.LoggerFrom(ctx).V(1).Error(err, "unable to check the ollama server runtime", "pod", pod.Name)
+			}
+			if !ok {
+				blocked = true
+				continue
+			}
+		}
+		available = append(available, pod)
+	}
+	for len(outdated) > 0 && len(available)+len(outdated) > replicas {
+		if err := r.deletePod(ctx, outdated[len(outdated)-1]); err != nil {
+			return err
+		}
+		outdated = outdated[:len(outdated)-1]
+	}
+
+	current := r.selectPod(model, available, outdated, updated)
+	if current == nil {
+		model.Status.PodRef = nil
+	} else {
+		setPodRef(model, current)
+	}
+	setReplicas(model, updated, outdated)
+	setPullScheduled(model, r.PullScheduling, updated)
+	setPulls(model, updated)
+	setBackedUp(model, updated)
+	if !blocked {
+		r.reconcileRuntime(ctx, model, current)
+	}
+	return nil
+}
+
+// selectPod returns the pod which is referenced by status.podRef.
+// The referenced pod is kept as long as no better pod is available.
+func (r *ModelReconciler) selectPod(model *ollamav1alpha1.Model, tiers ...[]*corev1.Pod) *corev1.Pod {
+	for _, pods := range tiers {
+		if len(pods) == 0 {
+			continue
+		}
+		if model.Status.PodRef != nil {
+			if pod := findPod(pods, func(pod *corev1.Pod) bool { return pod.Name == model.Status.PodRef.Name }); pod != nil {
+				return pod
+			}
+		}
+		return pods[0]
+	}
+	return nil
+}
+
+// setReplicas records the serving pods in the Model status.
+func setReplicas(model *ollamav1alpha1.Model, updated, outdated []*corev1.Pod) {
+	model.Status.Replicas = int32(len(updated) + len(outdated))
+	model.Status.UpdatedReplicas = int32(len(updated))
+	model.Status.ReadyReplicas = int32(len(outdated))
+	for _, pod := range updated {
+		if podutil.IsReady(pod) {
+			model.Status.ReadyReplicas++
+		}
+	}
+	model.Status.Selector = labels.SelectorFromSet(labels.Set{ollamav1alpha1.ModelNameLabel: model.Name}).String()
+}
+
+func podRank(pod *corev1.Pod) int {
+	if podutil.IsReady(pod) {
+		return 1
+	}
+	return 0
+}
+
+func (r *ModelReconciler) listPods(ctx context.Context, model *ollamav1alpha1.Model) ([]*corev1.Pod, error) {
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(model.Namespace), client.MatchingLabels{ollamav1alpha1.ModelNameLabel: model.Name}); err != nil {
+		return nil, err
+	}
+	pods := make([]*corev1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		if isOwnedBy(&podList.Items[i], model) {
+			pods = append(pods, &podList.Items[i])
+		}
+	}
+	// The pods created by older versions of the operator do not have the labels.
+	if model.Status.PodRef != nil && findPod(pods, func(pod *corev1.Pod) bool { return pod.Name == model.Status.PodRef.Name }) == nil {
+		pod := &corev1.Pod{}
+		err := r.Get(ctx, client.ObjectKey{Namespace: model.Namespace, Name: model.Status.PodRef.Name}, pod)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil && isOwnedBy(pod, model) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+func isOwnedBy(obj metav1.Object, model *ollamav1alpha1.Model) bool {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == model.UID {
+			return true
+		}
 	}
-	err := r.Get(ctx, client.ObjectKey{Namespace: model.Namespace, Name: name}, pod)
+	return false
+}
+
+// deletePods deletes all pods of the Model.
+func (r *ModelReconciler) deletePods(ctx context.Context, model *ollamav1alpha1.Model) error {
+	pods, err := r.listPods(ctx, model)
 	if err != nil {
-		if !apierrors.IsNotFound(err) {
+		return err
+	}
+	for _, pod := range pods {
+		if err := r.deletePod(ctx, pod); err != nil {
 			return err
 		}
-		return r.createPod(ctx, model)
 	}
-	return r.updatePod(ctx, model, pod)
+	return nil
+}
+
+func (r *ModelReconciler) deletePod(ctx context.Context, pod *corev1.Pod) error {
+	if err := r.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (r *ModelReconciler) createPod(ctx context.Context, desired *corev1.Pod) (*corev1.Pod, error) {
+	pod := desired.DeepCopy()
+	if err := r.Create(ctx, pod); err != nil {
+		return nil, err
+	}
+	return pod, nil
 }
 
-func (r *ModelReconciler) createPod(ctx context.Context, model *ollamav1alpha1.Model) error {
+func (r *ModelReconciler) desiredPod(ctx context.Context, model *ollamav1alpha1.Model) (*corev1.Pod, error) {
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace:    model.Namespace,
 			GenerateName: fmt.Sprintf("%s-", model.Name),
 		},
 	}
-	if err := controllerutil.SetOwnerReference(model, pod, r.Scheme); err != nil {
-		return err
+	if err := controllerutil.SetControllerReference(model, pod, r.Scheme); err != nil {
+		return nil, err
 	}
-	r.modelToPod(model, pod)
-	if err := r.Create(ctx, pod); err != nil {
-		return err
+	if err := r.modelToPod(model, pod); err != nil {
+		return nil, err
+	}
+	hash, err := podTemplateHash(pod)
+	if err != nil {
+		return nil, err
 	}
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	pod.Labels[ollamav1alpha1.ModelNameLabel] = model.Name
+	pod.Labels[ollamav1alpha1.PodTemplateHashLabel] = hash
+	if usesNodeCache(model) {
+		// The preferences are not a part of the revision, since the cached models change without the Model.
+		caches := &ollamav1alpha1.NodeCacheList{}
+		if err := r.List(ctx, caches); err != nil {
+			return nil, err
+		}
+		if terms := nodeCacheAffinity(caches.Items, model.Spec.Images); len(terms) > 0 {
+			if pod.Spec.Affinity == nil {
+				pod.Spec.Affinity = &corev1.Affinity{}
+			} else {
+				pod.Spec.Affinity = pod.Spec.Affinity.DeepCopy()
+			}
+			if pod.Spec.Affinity.NodeAffinity == nil {
+				pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+			}
+			pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+				pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, terms...)
+		}
+	}
+	return pod, nil
+}
+
+// podTemplateHash returns the hash of the generated pod which identifies its revision.
+func podTemplateHash(pod *corev1.Pod) (string, error) {
+	b, err := json.Marshal(struct {
+		Labels      map[string]string `json:"labels,omitempty"`
+		Annotations map[string]string `json:"annotations,omitempty"`
+		Spec        corev1.PodSpec    `json:"spec"`
+	}{pod.Labels, pod.Annotations, pod.Spec})
+	if err != nil {
+		return "", err
+	}
+	hasher := fnv.New32a()
+	_, _ = hasher.Write(b)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32())), nil
+}
+
+func setPodRef(model *ollamav1alpha1.Model, pod *corev1.Pod) {
 	model.Status.PodRef = &corev1.ObjectReference{
 		Name:      pod.Name,
 		Namespace: pod.Namespace,
 	}
-	return nil
 }
 
-func (r *ModelReconciler) updatePod(ctx context.Context, model *ollamav1alpha1.Model, pod *corev1.Pod) error {
-	before := pod.DeepCopy()
-	r.modelToPod(model, pod)
-	if !equality.Semantic.DeepEqual(before.Spec, pod.Spec) {
-		if err := r.Delete(ctx, before); err != nil {
-			return err
-		}
-		if err := r.createPod(ctx, model); err != nil {
-			return err
+func findPod(pods []*corev1.Pod, match func(pod *corev1.Pod) bool) *corev1.Pod {
+	for _, pod := range pods {
+		if match(pod) {
+			return pod
 		}
 	}
 	return nil
 }
 
-func (r *ModelReconciler) modelToPod(model *ollamav1alpha1.Model, pod *corev1.Pod) {
-	image := "ollama/ollama:latest"
-	if r.OllamaContainerImage != "" {
-		image = r.OllamaContainerImage
-	}
-
+func (r *ModelReconciler) modelToPod(model *ollamav1alpha1.Model, pod *corev1.Pod) error {
 	var buf bytes.Buffer
 	tmpl := template.Must(template.New("postStart").Parse(postStartScript))
+	weights := weightsSources(model.Spec.Sources, model.Spec.Images)
+	registry := mergeRegistry(r.DefaultRegistry, model.Spec.Registry)
+	images := pulledImages(model)
+	agentPulls := len(images) > 0 && modelCacheName(model) == "" &&
+		(registry != nil || len(ociSources(model.Spec.Sources, images)) > 0 || model.Spec.Backup != nil || model.Spec.PullPolicy != nil || peerSeeding(model))
+	postStartImages := model.Spec.Images
+	if agentPulls && model.Spec.PullPolicy != nil {
+		// The images failed by the pull policy are not pulled again by the ollama server without the retries and the timeout.
+		postStartImages = slices.DeleteFunc(slices.Clone(postStartImages), func(image string) bool { return slices.Contains(images, image) })
+	}
 	_ = tmpl.Execute(&buf, PostStartInput{
-		Images: model.Spec.Images,
+		Images:     postStartImages,
+		Modelfiles: modelfiles(weights),
 	})
 
 	if model.Spec.Template != nil && model.Spec.Template.Metadata != nil {
-		pod.Labels = model.Spec.Template.Metadata.Labels
-		pod.Annotations = model.Spec.Template.Metadata.Annotations
+		pod.Labels = maps.Clone(model.Spec.Template.Metadata.Labels)
+		pod.Annotations = maps.Clone(model.Spec.Template.Metadata.Annotations)
 	}
 
 	var volumeMounts []corev1.VolumeMount
+	var resources corev1.ResourceRequirements
 	if model.Spec.Template != nil && model.Spec.Template.Spec != nil {
 		pod.Spec.Volumes = model.Spec.Template.Spec.Volumes
 		pod.Spec.NodeSelector = model.Spec.Template.Spec.NodeSelector
@@ -91,12 +329,29 @@ func (r *ModelReconciler) modelToPod(model *ollamav1alpha1.Model, pod *corev1.Po
 		pod.Spec.Tolerations = model.Spec.Template.Spec.Tolerations
 		pod.Spec.TopologySpreadConstraints = model.Spec.Template.Spec.TopologySpreadConstraints
 		volumeMounts = append(volumeMounts, model.Spec.Template.Spec.VolumeMounts...)
+		if model.Spec.Template.Spec.Resources != nil {
+			resources = *model.Spec.Template.Spec.Resources
+		}
+		if storage := model.Spec.Template.Spec.Storage; storage != nil {
+			pod.Spec.Volumes = append(slices.Clone(pod.Spec.Volumes), storageVolume(storage))
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      storageVolumeName,
+				MountPath: modelsPath,
+				ReadOnly:  storage.CacheName != nil,
+			})
+		}
+	}
+
+	var imagePullPolicy corev1.PullPolicy
+	if model.Spec.Runtime != nil {
+		imagePullPolicy = model.Spec.Runtime.ImagePullPolicy
 	}
 
 	pod.Spec.Containers = []corev1.Container{
 		{
-			Name:  "ollama-server",
-			Image: image,
+			Name:            ollamaServerContainerName,
+			Image:           r.runtimeImage(model),
+			ImagePullPolicy: imagePullPolicy,
 			Ports: []corev1.ContainerPort{
 				{
 					Name:          "ollama-server",
@@ -105,6 +360,7 @@ func (r *ModelReconciler) modelToPod(model *ollamav1alpha1.Model, pod *corev1.Po
 				},
 			},
 			VolumeMounts: volumeMounts,
+			Resources:    resources,
 			// TODO: Deal with other env vars
 			Env: []corev1.EnvVar{
 				{
@@ -128,4 +384,34 @@ func (r *ModelReconciler) modelToPod(model *ollamav1alpha1.Model, pod *corev1.Po
 			},
 		},
 	}
+
+	if agentPulls {
+		r.injectPuller(model, pod, registry, images)
+		if peerSeeding(model) {
+			r.injectSeeder(pod)
+		}
+	}
+	if len(weights) > 0 {
+		r.injectWeights(pod, weights)
+	}
+	requestEphemeralStorage(model, pod)
+
+	if usesNodeCache(model) || modelCacheName(model) != "" {
+		// The ollama server removes the blobs which are not referenced by its manifests on start,
+		// which would break the pulls of the other pods on the node and fail on the read-only volume of the ModelCache.
+		pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, corev1.EnvVar{Name: "OLLAMA_NOPRUNE", Value: "1"})
+	}
+
+	if r.PullScheduling && modelCacheName(model) == "" {
+		injectPullPermit(r.AgentImage, &pod.ObjectMeta, &pod.Spec)
+	}
+
+	if needsProxy(model) {
+		r.injectProxy(model, pod)
+	}
+
+	if model.Spec.Template != nil {
+		return applyPodOverride(pod, model.Spec.Template.PodOverride)
+	}
+	return nil
 }
@@ -1,7 +1,11 @@
 package controller
 
-var postStartScript = "{{- range .Images }}ollama pull {{ . }};{{- end }}"
+// postStartScript pulls the images which are not cached yet, or creates them from their Modelfiles.
+var postStartScript = "{{- range $image := .Images }}ollama show {{ $image }} >/dev/null 2>&1 || " +
+	"{{ with index $.Modelfiles $image }}ollama create {{ $image }} -f {{ . }}{{ else }}ollama pull {{ $image }}{{ end }};{{- end }}"
 
 type PostStartInput struct {
 	Images []string
+	// Modelfiles are the paths of the Modelfiles the images are created from, by the image.
+	Modelfiles map[string]string
 }
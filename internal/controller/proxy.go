@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+	"github.com/sivchari/ollama-operator/internal/proxy"
+)
+
+const (
+	ollamaProxyContainerName = "ollama-proxy"
+	// ollamaUpstreamPort is the port the ollama server listens on behind the proxy.
+	ollamaUpstreamPort = 11435
+	// proxyConfigDir is the directory the configurations of the proxy are mounted in.
+	proxyConfigDir = "/etc/ollama-proxy"
+)
+
+// needsProxy reports whether the proxy is injected in the pods of the Model.
+func needsProxy(model *ollamav1alpha1.Model) bool {
+	return model.Spec.Autoscaling != nil || model.Spec.Auth != nil
+}
+
+// injectProxy puts the proxy in front of the ollama server of pod, which measures the load and authenticates the requests.
+// The proxy takes over the ollama server port, and the ollama server listens on the loopback address instead.
+func (r *ModelReconciler) injectProxy(model *ollamav1alpha1.Model, pod *corev1.Pod) {
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		if c.Name != ollamaServerContainerName {
+			continue
+		}
+		c.Ports = nil
+		c.Env = setEnv(c.Env, corev1.EnvVar{
+			Name:  "OLLAMA_HOST",
+			Value: net.JoinHostPort("127.0.0.1", strconv.Itoa(ollamaUpstreamPort)),
+		})
+	}
+	args := []string{
+		"proxy",
+		fmt.Sprintf("--bind-address=:%d", ollama.DefaultPort),
+		fmt.Sprintf("--metrics-bind-address=:%d", proxy.MetricsPort),
+		fmt.Sprintf("--upstream=http://127.0.0.1:%d", ollamaUpstreamPort),
+	}
+	if model.Spec.Autoscaling != nil && model.Spec.Autoscaling.Concurrency != nil {
+		args = append(args, fmt.Sprintf("--max-concurrency=%d", *model.Spec.Autoscaling.Concurrency))
+	}
+	var volumeMounts []corev1.VolumeMount
+	if auth := model.Spec.Auth; auth != nil {
+		args = append(args, "--auth")
+		if len(auth.APIKeys) > 0 {
+			args = append(args,
+				"--api-keys-dir="+path.Join(proxyConfigDir, "api-keys"),
+				"--admin-keys-dir="+path.Join(proxyConfigDir, "admin-keys"),
+			)
+		}
+		for i, secret := range auth.APIKeys {
+			name := fmt.Sprintf("ollama-proxy-api-keys-%d", i)
+			dir := "api-keys"
+			if secret.Admin {
+				dir = "admin-keys"
+			}
+			pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+				Name: name,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{SecretName: secret.Name},
+				},
+			})
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      name,
+				MountPath: path.Join(proxyConfigDir, dir, secret.Name),
+				ReadOnly:  true,
+			})
+		}
+		if jwt := auth.JWT; jwt != nil {
+			args = append(args, "--jwt-issuer="+jwt.Issuer)
+			args = append(args, "--jwt-audiences="+strings.Join(jwt.Audiences, ","))
+			if jwt.AdminClaim != nil {
+				args = append(args, fmt.Sprintf("--jwt-admin-claim=%s=%s", jwt.AdminClaim.Name, jwt.AdminClaim.Value))
+			}
+		}
+	}
+	pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{
+		Name:         ollamaProxyContainerName,
+		Image:        r.AgentImage,
+		Command:      []string{"/agent"},
+		Args:         args,
+		VolumeMounts: volumeMounts,
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "ollama-server",
+				ContainerPort: ollama.DefaultPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+			{
+				Name:          "proxy-metrics",
+				ContainerPort: proxy.MetricsPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+	})
+}
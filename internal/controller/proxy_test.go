@@ -0,0 +1,76 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestModelToPodWithAuth(t *testing.T) {
+	r := &ModelReconciler{OllamaContainerImage: "ollama/ollama:latest", AgentImage: "ollama-operator:latest"}
+
+	t.Run("Should put the authenticating proxy in front of the ollama server", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{Name: "model", Namespace: "default"},
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+				Auth: &ollamav1alpha1.ModelAuth{
+					APIKeys: []ollamav1alpha1.ModelAPIKeySecret{
+						{Name: "team-a"},
+						{Name: "ops", Admin: true},
+					},
+					JWT: &ollamav1alpha1.ModelJWT{
+						Issuer:     "https://issuer.example.com",
+						Audiences:  []string{"ollama", "llm"},
+						AdminClaim: &ollamav1alpha1.ModelJWTClaim{Name: "groups", Value: "ollama-admins"},
+					},
+				},
+			},
+		}
+		pod := &corev1.Pod{}
+		g.Expect(r.modelToPod(model, pod)).To(Succeed())
+		g.Expect(pod.Spec.Containers).To(HaveLen(2))
+		g.Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "OLLAMA_HOST", Value: "127.0.0.1:11435"}))
+
+		proxy := pod.Spec.Containers[1]
+		g.Expect(proxy.Name).To(Equal(ollamaProxyContainerName))
+		g.Expect(proxy.Args).To(ContainElements(
+			"--auth",
+			"--api-keys-dir=/etc/ollama-proxy/api-keys",
+			"--admin-keys-dir=/etc/ollama-proxy/admin-keys",
+			"--jwt-issuer=https://issuer.example.com",
+			"--jwt-audiences=ollama,llm",
+			"--jwt-admin-claim=groups=ollama-admins",
+		))
+		g.Expect(proxy.Args).NotTo(ContainElement(HavePrefix("--max-concurrency")))
+		g.Expect(proxy.VolumeMounts).To(ConsistOf(
+			corev1.VolumeMount{Name: "ollama-proxy-api-keys-0", MountPath: "/etc/ollama-proxy/api-keys/team-a", ReadOnly: true},
+			corev1.VolumeMount{Name: "ollama-proxy-api-keys-1", MountPath: "/etc/ollama-proxy/admin-keys/ops", ReadOnly: true},
+		))
+		g.Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name:         "ollama-proxy-api-keys-1",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "ops"}},
+		}))
+	})
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/pull"
+)
+
+// pullPolicyArgs returns the arguments of the agent applying the pull policy.
+func pullPolicyArgs(policy *ollamav1alpha1.ModelPullPolicy) []string {
+	if policy == nil {
+		return nil
+	}
+	var args []string
+	if policy.MaxAttempts > 0 {
+		args = append(args, "--max-attempts="+strconv.Itoa(int(policy.MaxAttempts)))
+	}
+	if policy.Backoff != nil {
+		args = append(args, "--backoff="+policy.Backoff.Duration.String())
+	}
+	if policy.MaxBackoff != nil {
+		args = append(args, "--max-backoff="+policy.MaxBackoff.Duration.String())
+	}
+	if policy.Timeout != nil {
+		args = append(args, "--timeout="+policy.Timeout.Duration.String())
+	}
+	if policy.OnError == ollamav1alpha1.ModelPullContinue {
+		args = append(args, "--continue-on-error")
+	}
+	return args
+}
+
+// setPulls records in the Model status the results of the pulls reported by the agent of the pods.
+// For each image, the largest number of the attempts and the error of a pod which has not pulled it are kept.
+func setPulls(model *ollamav1alpha1.Model, pods []*corev1.Pod) {
+	if model.Spec.PullPolicy == nil {
+		model.Status.Pulls = nil
+		return
+	}
+	var pulls []ollamav1alpha1.ModelPullStatus
+	for _, pod := range pods {
+		for _, result := range pullResults(pod) {
+			i := slices.IndexFunc(pulls, func(p ollamav1alpha1.ModelPullStatus) bool { return p.Image == result.Image })
+			if i < 0 {
+				pulls = append(pulls, ollamav1alpha1.ModelPullStatus{Image: result.Image})
+				i = len(pulls) - 1
+			}
+			pulls[i].Attempts = max(pulls[i].Attempts, int32(result.Attempts))
+			if pulls[i].LastError == "" {
+				pulls[i].LastError = result.Error
+			}
+		}
+	}
+	if len(pulls) == 0 && len(pods) > 0 {
+		// The pulls of the pods are in progress, so the results of the previous pulls are kept.
+		return
+	}
+	model.Status.Pulls = pulls
+}
+
+// setBackedUp records in the Model status whether the agent of the pods has backed up the pulled images.
+// The condition is kept while the pulls of the pods are in progress.
+func setBackedUp(model *ollamav1alpha1.Model, pods []*corev1.Pod) {
+	if model.Spec.Backup == nil {
+		meta.RemoveStatusCondition(&model.Status.Conditions, ollamav1alpha1.ModelConditionBackedUp)
+		return
+	}
+	pulled := false
+	var failures []string
+	for _, pod := range pods {
+		for _, result := range pullResults(pod) {
+			if result.Error != "" {
+				continue
+			}
+			pulled = true
+			if result.BackupError != "" && !slices.Contains(failures, result.BackupError) {
+				failures = append(failures, result.BackupError)
+			}
+		}
+	}
+	if !pulled {
+		return
+	}
+	condition := metav1.Condition{
+		Type:               ollamav1alpha1.ModelConditionBackedUp,
+		Status:             metav1.ConditionTrue,
+		Reason:             ollamav1alpha1.BackupSucceeded,
+		ObservedGeneration: model.Generation,
+	}
+	if len(failures) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ollamav1alpha1.BackupFailed
+		condition.Message = strings.Join(failures, "; ")
+	}
+	meta.SetStatusCondition(&model.Status.Conditions, condition)
+}
+
+// pullResults returns the results of the pulls written by the agent to the termination message of the pull container.
+// The results of the last run are used while the container is restarted after its failure.
+func pullResults(pod *corev1.Pod) []pull.Result {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.Name != ollamaPullContainerName {
+			continue
+		}
+		terminated := status.State.Terminated
+		if terminated == nil {
+			terminated = status.LastTerminationState.Terminated
+		}
+		if terminated == nil || terminated.Message == "" {
+			return nil
+		}
+		var results []pull.Result
+		if err := json.Unmarshal([]byte(terminated.Message), &results); err != nil {
+			return nil
+		}
+		return results
+	}
+	return nil
+}
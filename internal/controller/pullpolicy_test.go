@@ -0,0 +1,150 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestModelToPodWithPullPolicy(t *testing.T) {
+	g := NewWithT(t)
+	r := &ModelReconciler{AgentImage: "agent:latest"}
+	model := &ollamav1alpha1.Model{
+		Spec: ollamav1alpha1.ModelSpec{
+			Images: []string{"llama3", "phi3"},
+			PullPolicy: &ollamav1alpha1.ModelPullPolicy{
+				MaxAttempts: 3,
+				Backoff:     &metav1.Duration{Duration: 5 * time.Second},
+				Timeout:     &metav1.Duration{Duration: 30 * time.Minute},
+				OnError:     ollamav1alpha1.ModelPullContinue,
+			},
+		},
+	}
+	pod := &corev1.Pod{}
+	g.Expect(r.modelToPod(model, pod)).To(Succeed())
+	g.Expect(pod.Spec.InitContainers).To(HaveLen(1))
+	init := pod.Spec.InitContainers[0]
+	g.Expect(init.Name).To(Equal(ollamaPullContainerName))
+	g.Expect(init.Args).To(Equal([]string{
+		"pull",
+		"--models-dir=/root/.ollama/models",
+		"--max-attempts=3",
+		"--backoff=5s",
+		"--timeout=30m0s",
+		"--continue-on-error",
+		"--",
+		"llama3",
+		"phi3",
+	}))
+	postStart := pod.Spec.Containers[0].Lifecycle.PostStart.Exec.Command[2]
+	g.Expect(postStart).NotTo(ContainSubstring("ollama pull"))
+}
+
+// podWithMessage returns the pod whose pull container has terminated with the messages of the current and the last state.
+func podWithMessage(state, last string) *corev1.Pod {
+	status := corev1.ContainerStatus{Name: ollamaPullContainerName}
+	if state != "" {
+		status.State.Terminated = &corev1.ContainerStateTerminated{Message: state}
+	}
+	if last != "" {
+		status.LastTerminationState.Terminated = &corev1.ContainerStateTerminated{Message: last}
+	}
+	return &corev1.Pod{Status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{status}}}
+}
+
+func TestSetPulls(t *testing.T) {
+	policy := &ollamav1alpha1.ModelPullPolicy{MaxAttempts: 3}
+
+	t.Run("Should merge the results of the pods", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{Spec: ollamav1alpha1.ModelSpec{PullPolicy: policy}}
+		setPulls(model, []*corev1.Pod{
+			podWithMessage(`[{"image":"llama3","attempts":1},{"image":"phi3","attempts":3,"error":"timed out after 1m0s"}]`, ""),
+			podWithMessage("", `[{"image":"llama3","attempts":2}]`),
+		})
+		g.Expect(model.Status.Pulls).To(Equal([]ollamav1alpha1.ModelPullStatus{
+			{Image: "llama3", Attempts: 2},
+			{Image: "phi3", Attempts: 3, LastError: "timed out after 1m0s"},
+		}))
+	})
+
+	t.Run("Should keep the previous results while the pulls are in progress", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{Spec: ollamav1alpha1.ModelSpec{PullPolicy: policy}}
+		model.Status.Pulls = []ollamav1alpha1.ModelPullStatus{{Image: "llama3", Attempts: 1}}
+		setPulls(model, []*corev1.Pod{podWithMessage("", "")})
+		g.Expect(model.Status.Pulls).To(Equal([]ollamav1alpha1.ModelPullStatus{{Image: "llama3", Attempts: 1}}))
+	})
+
+	t.Run("Should clear the results without the pull policy", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{}
+		model.Status.Pulls = []ollamav1alpha1.ModelPullStatus{{Image: "llama3", Attempts: 1}}
+		setPulls(model, []*corev1.Pod{podWithMessage(`[{"image":"llama3","attempts":1}]`, "")})
+		g.Expect(model.Status.Pulls).To(BeNil())
+	})
+}
+
+func TestSetBackedUp(t *testing.T) {
+	backup := &ollamav1alpha1.ModelBackup{Endpoint: "http://minio.minio:9000", Bucket: "models", CredentialsSecretName: "minio"}
+
+	t.Run("Should report the failures of the backup of the pulled images", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{Spec: ollamav1alpha1.ModelSpec{Backup: backup}}
+		setBackedUp(model, []*corev1.Pod{
+			podWithMessage(`[{"image":"llama3","attempts":1,"backupError":"failed to back up llama3: 403 Forbidden"}]`, ""),
+			podWithMessage(`[{"image":"llama3","attempts":1,"backupError":"failed to back up llama3: 403 Forbidden"},`+
+				`{"image":"phi3","attempts":1,"error":"not found","backupError":"failed to restore phi3"}]`, ""),
+		})
+		condition := meta.FindStatusCondition(model.Status.Conditions, ollamav1alpha1.ModelConditionBackedUp)
+		g.Expect(condition).NotTo(BeNil())
+		g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		g.Expect(condition.Reason).To(Equal(ollamav1alpha1.BackupFailed))
+		g.Expect(condition.Message).To(Equal("failed to back up llama3: 403 Forbidden"))
+	})
+
+	t.Run("Should report the backup once the images are pulled", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{Spec: ollamav1alpha1.ModelSpec{Backup: backup}}
+		setBackedUp(model, []*corev1.Pod{podWithMessage("", "")})
+		g.Expect(model.Status.Conditions).To(BeEmpty())
+
+		setBackedUp(model, []*corev1.Pod{podWithMessage(`[{"image":"llama3","attempts":1}]`, "")})
+		condition := meta.FindStatusCondition(model.Status.Conditions, ollamav1alpha1.ModelConditionBackedUp)
+		g.Expect(condition).NotTo(BeNil())
+		g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+		g.Expect(condition.Reason).To(Equal(ollamav1alpha1.BackupSucceeded))
+	})
+
+	t.Run("Should remove the condition without the backup", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{}
+		meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+			Type: ollamav1alpha1.ModelConditionBackedUp, Status: metav1.ConditionTrue, Reason: ollamav1alpha1.BackupSucceeded,
+		})
+		setBackedUp(model, []*corev1.Pod{podWithMessage(`[{"image":"llama3","attempts":1}]`, "")})
+		g.Expect(model.Status.Conditions).To(BeEmpty())
+	})
+}
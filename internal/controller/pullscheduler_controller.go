@@ -0,0 +1,224 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/podutil"
+)
+
+const (
+	pullPermitContainerName = "ollama-pull-permit"
+	pullPermitVolumeName    = "pull-permit"
+	// pullPermitDir is the directory the labels of the pod are projected into, which the agent waits for the permit in.
+	pullPermitDir = "/etc/ollama-pull-permit"
+	// pullSchedulerKey is the single request of the pull scheduler, which schedules all the queued pulls at once.
+	pullSchedulerKey = "pull-scheduler"
+)
+
+var (
+	pullQueueLength = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "ollama_pull_queue_length",
+		Help: "Number of the pods waiting for the pull scheduler to permit their pulls.",
+	})
+	pullsRunning = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ollama_pulls_running",
+		Help: "Number of the pods whose pulls are permitted and running, by the node.",
+	}, []string{"node"})
+	pullQueueWaitSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "ollama_pull_queue_wait_seconds",
+		Help:    "Time the pods waited for the pull scheduler to permit their pulls.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 14),
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(pullQueueLength, pullsRunning, pullQueueWaitSeconds)
+}
+
+// PullSchedulerReconciler permits the pulls of the queued pods in the order they are created, limiting the pulls
+// running at once in the cluster and on each node. The serving pods of the Models pull until they are ready,
+// and the pods of the pull Jobs until they terminate.
+type PullSchedulerReconciler struct {
+	client.Client
+	// MaxConcurrentPulls is the maximum number of the pulls running at once in the cluster. 0 means unlimited.
+	MaxConcurrentPulls int
+	// MaxConcurrentPullsPerNode is the maximum number of the pulls running at once on a node. 0 means unlimited.
+	MaxConcurrentPullsPerNode int
+
+	now func() time.Time
+}
+
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;patch
+
+// Reconcile permits the queued pulls while the limits allow.
+func (r *PullSchedulerReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (ctrl.Result, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.HasLabels{ollamav1alpha1.PullStateLabel}); err != nil {
+		return ctrl.Result{}, err
+	}
+	running := map[string]int{}
+	total := 0
+	var queued []*corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pullDone(pod) {
+			continue
+		}
+		switch pod.Labels[ollamav1alpha1.PullStateLabel] {
+		case ollamav1alpha1.PullStatePermitted:
+			running[pod.Spec.NodeName]++
+			total++
+		case ollamav1alpha1.PullStateQueued:
+			queued = append(queued, pod)
+		}
+	}
+	slices.SortFunc(queued, func(a, b *corev1.Pod) int {
+		if c := a.CreationTimestamp.Compare(b.CreationTimestamp.Time); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+	})
+
+	waiting := 0
+	for _, pod := range queued {
+		if r.MaxConcurrentPulls > 0 && total >= r.MaxConcurrentPulls {
+			waiting++
+			continue
+		}
+		// The node of a pod is known once it is scheduled. The pods on the other nodes are not blocked by the busy node.
+		node := pod.Spec.NodeName
+		if node == "" || (r.MaxConcurrentPullsPerNode > 0 && running[node] >= r.MaxConcurrentPullsPerNode) {
+			waiting++
+			continue
+		}
+		patch := client.MergeFrom(pod.DeepCopy())
+		pod.Labels[ollamav1alpha1.PullStateLabel] = ollamav1alpha1.PullStatePermitted
+		if err := r.Patch(ctx, pod, patch); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		running[node]++
+		total++
+		pullQueueWaitSeconds.Observe(r.clock().Sub(pod.CreationTimestamp.Time).Seconds())
+		ctrl.LoggerFrom(ctx).V(1).Info("permitted pulls", "pod", client.ObjectKeyFromObject(pod), "node", node)
+	}
+
+	pullQueueLength.Set(float64(waiting))
+	pullsRunning.Reset()
+	for node, n := range running {
+		pullsRunning.WithLabelValues(node).Set(float64(n))
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *PullSchedulerReconciler) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// pullDone reports whether the pulls of the pod have ended.
+func pullDone(pod *corev1.Pod) bool {
+	if !pod.DeletionTimestamp.IsZero() || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return true
+	}
+	// The ollama server is ready once its postStart hook has pulled the images.
+	return pod.Labels[ollamav1alpha1.ModelNameLabel] != "" && podutil.IsReady(pod)
+}
+
+// injectPullPermit queues the pulls of the pod for the pull scheduler. The pod waits in its first init container
+// until the pull scheduler permits its pulls.
+func injectPullPermit(agentImage string, objectMeta *metav1.ObjectMeta, spec *corev1.PodSpec) {
+	if objectMeta.Labels == nil {
+		objectMeta.Labels = map[string]string{}
+	}
+	objectMeta.Labels[ollamav1alpha1.PullStateLabel] = ollamav1alpha1.PullStateQueued
+	spec.Volumes = append(slices.Clone(spec.Volumes), corev1.Volume{
+		Name: pullPermitVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			DownwardAPI: &corev1.DownwardAPIVolumeSource{
+				Items: []corev1.DownwardAPIVolumeFile{
+					{Path: "labels", FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.labels"}},
+				},
+			},
+		},
+	})
+	spec.InitContainers = append([]corev1.Container{{
+		Name:         pullPermitContainerName,
+		Image:        agentImage,
+		Command:      []string{"/agent"},
+		Args:         []string{"wait-permit", "--labels-file=" + pullPermitDir + "/labels"},
+		VolumeMounts: []corev1.VolumeMount{{Name: pullPermitVolumeName, MountPath: pullPermitDir, ReadOnly: true}},
+	}}, spec.InitContainers...)
+}
+
+// setPullScheduled records in the PullScheduled condition whether the pulls of the pods of the Model are queued.
+func setPullScheduled(model *ollamav1alpha1.Model, enabled bool, pods []*corev1.Pod) {
+	if !enabled {
+		meta.RemoveStatusCondition(&model.Status.Conditions, ollamav1alpha1.ModelConditionPullScheduled)
+		return
+	}
+	queued := 0
+	for _, pod := range pods {
+		if pod.Labels[ollamav1alpha1.PullStateLabel] == ollamav1alpha1.PullStateQueued {
+			queued++
+		}
+	}
+	condition := metav1.Condition{
+		Type:               ollamav1alpha1.ModelConditionPullScheduled,
+		Status:             metav1.ConditionTrue,
+		Reason:             ollamav1alpha1.PullsPermitted,
+		ObservedGeneration: model.Generation,
+	}
+	if queued > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ollamav1alpha1.PullsQueued
+		condition.Message = fmt.Sprintf("%d of %d pods wait for the pull scheduler", queued, len(pods))
+	}
+	meta.SetStatusCondition(&model.Status.Conditions, condition)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PullSchedulerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("pullscheduler").
+		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(func(_ context.Context, obj client.Object) []reconcile.Request {
+			if _, ok := obj.GetLabels()[ollamav1alpha1.PullStateLabel]; !ok {
+				return nil
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: pullSchedulerKey}}}
+		})).
+		Complete(r)
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestPullSchedulerReconcile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	NewWithT(t).Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	ctx := context.Background()
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newPod := func(name, node, state string, age int) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "default",
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(created.Add(time.Duration(age) * time.Second)),
+				Labels:            map[string]string{ollamav1alpha1.ModelNameLabel: "llama3", ollamav1alpha1.PullStateLabel: state},
+			},
+			Spec: corev1.PodSpec{NodeName: node},
+		}
+	}
+	states := func(g Gomega, c client.Client) map[string]string {
+		pods := &corev1.PodList{}
+		g.Expect(c.List(ctx, pods)).To(Succeed())
+		states := map[string]string{}
+		for _, pod := range pods.Items {
+			states[pod.Name] = pod.Labels[ollamav1alpha1.PullStateLabel]
+		}
+		return states
+	}
+
+	t.Run("Should permit the queued pulls in order within the limits", func(t *testing.T) {
+		g := NewWithT(t)
+		ready := newPod("ready", "node-a", ollamav1alpha1.PullStatePermitted, 0)
+		ready.Status.PodIP = "10.0.0.1"
+		ready.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			ready,
+			newPod("pulling", "node-a", ollamav1alpha1.PullStatePermitted, 1),
+			newPod("first", "node-a", ollamav1alpha1.PullStateQueued, 2),
+			newPod("second", "node-b", ollamav1alpha1.PullStateQueued, 3),
+			newPod("unscheduled", "", ollamav1alpha1.PullStateQueued, 4),
+			newPod("third", "node-c", ollamav1alpha1.PullStateQueued, 5),
+			newPod("fourth", "node-d", ollamav1alpha1.PullStateQueued, 6),
+		).Build()
+		r := &PullSchedulerReconciler{Client: c, MaxConcurrentPulls: 3, MaxConcurrentPullsPerNode: 1}
+		_, err := r.Reconcile(ctx, ctrl.Request{})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(states(g, c)).To(Equal(map[string]string{
+			"ready":       ollamav1alpha1.PullStatePermitted,
+			"pulling":     ollamav1alpha1.PullStatePermitted,
+			"first":       ollamav1alpha1.PullStateQueued,
+			"second":      ollamav1alpha1.PullStatePermitted,
+			"unscheduled": ollamav1alpha1.PullStateQueued,
+			"third":       ollamav1alpha1.PullStatePermitted,
+			"fourth":      ollamav1alpha1.PullStateQueued,
+		}))
+	})
+
+	t.Run("Should permit the next pull when a pull ends", func(t *testing.T) {
+		g := NewWithT(t)
+		pulling := newPod("pulling", "node-a", ollamav1alpha1.PullStatePermitted, 0)
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			pulling,
+			newPod("queued", "node-b", ollamav1alpha1.PullStateQueued, 1),
+		).Build()
+		r := &PullSchedulerReconciler{Client: c, MaxConcurrentPulls: 1}
+		_, err := r.Reconcile(ctx, ctrl.Request{})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(states(g, c)).To(HaveKeyWithValue("queued", ollamav1alpha1.PullStateQueued))
+
+		pulling.Status.PodIP = "10.0.0.1"
+		pulling.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		g.Expect(c.Status().Update(ctx, pulling)).To(Succeed())
+		_, err = r.Reconcile(ctx, ctrl.Request{})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(states(g, c)).To(HaveKeyWithValue("queued", ollamav1alpha1.PullStatePermitted))
+	})
+}
+
+func TestPullDone(t *testing.T) {
+	g := NewWithT(t)
+	readyJobPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{ollamav1alpha1.ModelCacheNameLabel: "shared"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}},
+	}
+	// The pod of a pull Job pulls until it terminates.
+	g.Expect(pullDone(readyJobPod)).To(BeFalse())
+	readyJobPod.Status.Phase = corev1.PodSucceeded
+	g.Expect(pullDone(readyJobPod)).To(BeTrue())
+}
+
+func TestModelToPodWithPullScheduling(t *testing.T) {
+	g := NewWithT(t)
+	r := &ModelReconciler{AgentImage: "agent:latest", PullScheduling: true}
+	model := &ollamav1alpha1.Model{
+		Spec: ollamav1alpha1.ModelSpec{
+			Images:   []string{"llama3"},
+			Registry: &ollamav1alpha1.ModelRegistry{Hosts: []ollamav1alpha1.RegistryHost{{Host: "registry.ollama.ai", Insecure: true}}},
+		},
+	}
+	pod := &corev1.Pod{}
+	g.Expect(r.modelToPod(model, pod)).To(Succeed())
+	g.Expect(pod.Labels).To(HaveKeyWithValue(ollamav1alpha1.PullStateLabel, ollamav1alpha1.PullStateQueued))
+	g.Expect(pod.Spec.InitContainers).To(HaveLen(2))
+	g.Expect(pod.Spec.InitContainers[0].Name).To(Equal(pullPermitContainerName))
+	g.Expect(pod.Spec.InitContainers[0].Args).To(Equal([]string{"wait-permit", "--labels-file=/etc/ollama-pull-permit/labels"}))
+	g.Expect(pod.Spec.InitContainers[1].Name).To(Equal(ollamaPullContainerName))
+	g.Expect(pod.Spec.Volumes).To(ContainElement(HaveField("Name", pullPermitVolumeName)))
+
+	updated := []*corev1.Pod{pod, {ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{ollamav1alpha1.PullStateLabel: ollamav1alpha1.PullStatePermitted}}}}
+	setPullScheduled(model, true, updated)
+	condition := meta.FindStatusCondition(model.Status.Conditions, ollamav1alpha1.ModelConditionPullScheduled)
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(condition.Reason).To(Equal(ollamav1alpha1.PullsQueued))
+	g.Expect(condition.Message).To(Equal("1 of 2 pods wait for the pull scheduler"))
+
+	setPullScheduled(model, false, updated)
+	g.Expect(meta.FindStatusCondition(model.Status.Conditions, ollamav1alpha1.ModelConditionPullScheduled)).To(BeNil())
+}
+
+func TestModelCacheReconcileWithPullScheduling(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(ollamav1alpha1.AddToScheme(scheme)).To(Succeed())
+	ctx := context.Background()
+	cache := &ollamav1alpha1.ModelCache{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "default", UID: "uid-shared"},
+		Spec:       ollamav1alpha1.ModelCacheSpec{Images: []string{"llama3"}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cache).WithStatusSubresource(cache).Build()
+	r := &ModelCacheReconciler{Client: c, Scheme: scheme, AgentImage: "agent:latest", PullScheduling: true}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cache)}
+	_, err := r.Reconcile(ctx, req)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	job := &batchv1.Job{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: pullJobName(cache, "llama3")}, job)).To(Succeed())
+	g.Expect(job.Spec.Template.Labels).To(HaveKeyWithValue(ollamav1alpha1.PullStateLabel, ollamav1alpha1.PullStateQueued))
+	g.Expect(job.Spec.Template.Spec.InitContainers[0].Name).To(Equal(pullPermitContainerName))
+
+	// The pod created by the Job waits for the pull scheduler.
+	labels := map[string]string{batchv1.JobNameLabel: job.Name}
+	for k, v := range job.Spec.Template.Labels {
+		labels[k] = v
+	}
+	g.Expect(c.Create(ctx, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: job.Name + "-abcde", Labels: labels}})).To(Succeed())
+	_, err = r.Reconcile(ctx, req)
+	g.Expect(err).NotTo(HaveOccurred())
+	got := &ollamav1alpha1.ModelCache{}
+	g.Expect(c.Get(ctx, req.NamespacedName, got)).To(Succeed())
+	g.Expect(got.Status.Images[0].Phase).To(Equal(ollamav1alpha1.ModelCacheImageQueued))
+	condition := meta.FindStatusCondition(got.Status.Conditions, ollamav1alpha1.ModelCacheConditionReady)
+	g.Expect(condition.Message).To(Equal("waiting for the pull scheduler to pull images: llama3"))
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/quota"
+)
+
+// quotaRecheckInterval is the interval to check whether the Model exceeding a ModelQuota is within the quotas again,
+// e.g. after another Model in the namespace is deleted.
+const quotaRecheckInterval = time.Minute
+
+// reconcileQuota reports whether the serving pods of the Model have to be scaled to zero because it exceeds a ModelQuota.
+func (r *ModelReconciler) reconcileQuota(ctx context.Context, model *ollamav1alpha1.Model) (bool, error) {
+	quotas := &ollamav1alpha1.ModelQuotaList{}
+	if err := r.List(ctx, quotas, client.InNamespace(model.Namespace)); err != nil {
+		return false, err
+	}
+	if len(quotas.Items) == 0 {
+		meta.RemoveStatusCondition(&model.Status.Conditions, ollamav1alpha1.ModelConditionQuotaExceeded)
+		return false, nil
+	}
+	result, err := evaluateQuotas(ctx, r.Client, model.Namespace, quotas.Items)
+	if err != nil {
+		return false, err
+	}
+	if msg, ok := result.Exceeded[model.Name]; ok {
+		meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+			Type:               ollamav1alpha1.ModelConditionQuotaExceeded,
+			Status:             metav1.ConditionTrue,
+			Reason:             ollamav1alpha1.QuotaExceeded,
+			Message:            "serving pods are scaled to zero because the Model " + msg,
+			ObservedGeneration: model.Generation,
+		})
+		return true, nil
+	}
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:               ollamav1alpha1.ModelConditionQuotaExceeded,
+		Status:             metav1.ConditionFalse,
+		Reason:             ollamav1alpha1.WithinQuota,
+		ObservedGeneration: model.Generation,
+	})
+	return false, nil
+}
+
+// evaluateQuotas evaluates quotas against the Models in namespace.
+// The sizes of the models are known from the Models in all namespaces.
+func evaluateQuotas(ctx context.Context, c client.Client, namespace string, quotas []ollamav1alpha1.ModelQuota) (quota.Result, error) {
+	models := &ollamav1alpha1.ModelList{}
+	if err := c.List(ctx, models); err != nil {
+		return quota.Result{}, err
+	}
+	var inNamespace []ollamav1alpha1.Model
+	for _, model := range models.Items {
+		if model.Namespace == namespace {
+			inNamespace = append(inNamespace, model)
+		}
+	}
+	return quota.Evaluate(quotas, inNamespace, quota.KnownSizes(models.Items)), nil
+}
+
+// modelQuotaToModels maps a ModelQuota to the Models in its namespace.
+func (r *ModelReconciler) modelQuotaToModels(ctx context.Context, obj client.Object) []ctrl.Request {
+	models := &ollamav1alpha1.ModelList{}
+	if err := r.List(ctx, models, client.InNamespace(obj.GetNamespace())); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "unable to list Models for the ModelQuota", "modelQuota", obj.GetName())
+		return nil
+	}
+	requests := make([]ctrl.Request, 0, len(models.Items))
+	for _, model := range models.Items {
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&model)})
+	}
+	return requests
+}
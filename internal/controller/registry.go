@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+const (
+	ollamaPullContainerName = "ollama-pull"
+	// registryAuthDir is the directory the Secrets of the registry credentials are mounted in.
+	registryAuthDir = "/etc/ollama-registry"
+	// backupCredentialsDir is the directory the Secret of the credentials of the backup is mounted in.
+	backupCredentialsDir        = "/etc/ollama-backup"
+	backupCredentialsVolumeName = "backup-credentials"
+)
+
+// mergeRegistry returns the registry configured for the operator merged with registry, whose mirrors and hosts
+// take precedence. It returns nil if no registry is configured.
+func mergeRegistry(defaults, registry *ollamav1alpha1.ModelRegistry) *ollamav1alpha1.ModelRegistry {
+	merged := &ollamav1alpha1.ModelRegistry{}
+	if defaults != nil {
+		merged = defaults.DeepCopy()
+	}
+	if registry != nil {
+		merged.Mirrors = mergeByKey(merged.Mirrors, registry.Mirrors, func(m ollamav1alpha1.RegistryMirror) string { return m.Host })
+		merged.Hosts = mergeByKey(merged.Hosts, registry.Hosts, func(h ollamav1alpha1.RegistryHost) string { return h.Host })
+	}
+	if len(merged.Mirrors) == 0 && len(merged.Hosts) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// injectPuller pulls images of the Model by the agent in an init container, which applies the registry configuration,
+// imports the images from their sources, and backs them up.
+// The models directory is shared with the ollama server through an emptyDir if the Model does not configure the storage.
+func (r *ModelReconciler) injectPuller(model *ollamav1alpha1.Model, pod *corev1.Pod, registry *ollamav1alpha1.ModelRegistry, images []string) {
+	if !slices.ContainsFunc(pod.Spec.Volumes, func(v corev1.Volume) bool { return v.Name == storageVolumeName }) {
+		pod.Spec.Volumes = append(slices.Clone(pod.Spec.Volumes), corev1.Volume{
+			Name:         storageVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+		for i := range pod.Spec.Containers {
+			if c := &pod.Spec.Containers[i]; c.Name == ollamaServerContainerName {
+				c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: storageVolumeName, MountPath: modelsPath})
+			}
+		}
+	}
+	var peers string
+	if peerSeeding(model) {
+		peers = peerHost(model)
+	}
+	container, volumes := pullContainer(r.AgentImage, registry, model.Spec.Sources, model.Spec.Backup, model.Spec.PullPolicy, peers, images)
+	container.Name = ollamaPullContainerName
+	pod.Spec.Volumes = append(pod.Spec.Volumes, volumes...)
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, container)
+}
+
+// pullContainer returns the container pulling images into the models volume by the agent, and the volumes of the
+// registry credentials, the sources and the backup credentials it mounts. registry and backup may be nil.
+func pullContainer(
+	agentImage string,
+	registry *ollamav1alpha1.ModelRegistry,
+	sources []ollamav1alpha1.ModelSource,
+	backup *ollamav1alpha1.ModelBackup,
+	policy *ollamav1alpha1.ModelPullPolicy,
+	peers string,
+	images []string,
+) (corev1.Container, []corev1.Volume) {
+	if registry == nil {
+		registry = &ollamav1alpha1.ModelRegistry{}
+	}
+	args := []string{"pull", "--models-dir=" + modelsPath}
+	for _, mirror := range registry.Mirrors {
+		args = append(args, fmt.Sprintf("--mirror=%s=%s", mirror.Host, mirror.Mirror))
+	}
+	volumeMounts := []corev1.VolumeMount{{Name: storageVolumeName, MountPath: modelsPath}}
+	var volumes []corev1.Volume
+	for i, host := range registry.Hosts {
+		if host.Insecure {
+			args = append(args, "--insecure-registry="+host.Host)
+		}
+		if host.AuthSecretName == nil {
+			continue
+		}
+		name := fmt.Sprintf("registry-auth-%d", i)
+		dir := path.Join(registryAuthDir, strconv.Itoa(i))
+		volumes = append(volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: *host.AuthSecretName},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: name, MountPath: dir, ReadOnly: true})
+		args = append(args, fmt.Sprintf("--registry-auth=%s=%s", host.Host, dir))
+	}
+	importArgs, sourceVolumes, sourceVolumeMounts := sourceArgs(ociSources(sources, images))
+	args = append(args, importArgs...)
+	volumes = append(volumes, sourceVolumes...)
+	volumeMounts = append(volumeMounts, sourceVolumeMounts...)
+	if backup != nil {
+		args = append(args,
+			"--backup-endpoint="+backup.Endpoint,
+			"--backup-bucket="+backup.Bucket,
+			"--backup-credentials-dir="+backupCredentialsDir,
+		)
+		if backup.Prefix != "" {
+			args = append(args, "--backup-prefix="+backup.Prefix)
+		}
+		if backup.Region != "" {
+			args = append(args, "--backup-region="+backup.Region)
+		}
+		if backup.Insecure {
+			args = append(args, "--backup-insecure")
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name: backupCredentialsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: backup.CredentialsSecretName},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: backupCredentialsVolumeName, MountPath: backupCredentialsDir, ReadOnly: true})
+	}
+	args = append(args, pullPolicyArgs(policy)...)
+	if peers != "" {
+		args = append(args, "--peers="+peers)
+	}
+	args = append(append(args, "--"), images...)
+	return corev1.Container{
+		Image:        agentImage,
+		Command:      []string{"/agent"},
+		Args:         args,
+		VolumeMounts: volumeMounts,
+		// The models directory is owned by the ollama server, which runs as root.
+		SecurityContext: &corev1.SecurityContext{RunAsUser: ptr.To[int64](0)},
+	}, volumes
+}
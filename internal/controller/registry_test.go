@@ -0,0 +1,180 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestMergeRegistry(t *testing.T) {
+	defaults := &ollamav1alpha1.ModelRegistry{
+		Mirrors: []ollamav1alpha1.RegistryMirror{{Host: "registry.ollama.ai", Mirror: "mirror.example.com"}},
+		Hosts:   []ollamav1alpha1.RegistryHost{{Host: "mirror.example.com", AuthSecretName: ptr.To("default-auth")}},
+	}
+
+	t.Run("Should return nil if no registry is configured", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(mergeRegistry(nil, nil)).To(BeNil())
+		g.Expect(mergeRegistry(nil, &ollamav1alpha1.ModelRegistry{})).To(BeNil())
+	})
+
+	t.Run("Should use the defaults", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(mergeRegistry(defaults, nil)).To(Equal(defaults))
+	})
+
+	t.Run("Should prefer the hosts of the Model", func(t *testing.T) {
+		g := NewWithT(t)
+		merged := mergeRegistry(defaults, &ollamav1alpha1.ModelRegistry{
+			Hosts: []ollamav1alpha1.RegistryHost{
+				{Host: "mirror.example.com", AuthSecretName: ptr.To("team-auth")},
+				{Host: "hf.co", Insecure: true},
+			},
+		})
+		g.Expect(merged.Mirrors).To(Equal(defaults.Mirrors))
+		g.Expect(merged.Hosts).To(Equal([]ollamav1alpha1.RegistryHost{
+			{Host: "mirror.example.com", AuthSecretName: ptr.To("team-auth")},
+			{Host: "hf.co", Insecure: true},
+		}))
+		g.Expect(defaults.Hosts[0].AuthSecretName).To(Equal(ptr.To("default-auth")))
+	})
+}
+
+func TestModelToPodWithRegistry(t *testing.T) {
+	r := &ModelReconciler{
+		AgentImage: "agent:latest",
+		DefaultRegistry: &ollamav1alpha1.ModelRegistry{
+			Mirrors: []ollamav1alpha1.RegistryMirror{{Host: "registry.ollama.ai", Mirror: "mirror.example.com"}},
+		},
+	}
+
+	t.Run("Should pull the images by the agent with the registry configuration", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+				Registry: &ollamav1alpha1.ModelRegistry{
+					Hosts: []ollamav1alpha1.RegistryHost{{Host: "mirror.example.com", AuthSecretName: ptr.To("mirror-auth"), Insecure: true}},
+				},
+			},
+		}
+		pod := &corev1.Pod{}
+		g.Expect(r.modelToPod(model, pod)).To(Succeed())
+		g.Expect(pod.Spec.InitContainers).To(HaveLen(1))
+		init := pod.Spec.InitContainers[0]
+		g.Expect(init.Name).To(Equal(ollamaPullContainerName))
+		g.Expect(init.Image).To(Equal("agent:latest"))
+		g.Expect(init.Args).To(Equal([]string{
+			"pull",
+			"--models-dir=/root/.ollama/models",
+			"--mirror=registry.ollama.ai=mirror.example.com",
+			"--insecure-registry=mirror.example.com",
+			"--registry-auth=mirror.example.com=/etc/ollama-registry/0",
+			"--",
+			"llama3",
+		}))
+		g.Expect(init.VolumeMounts).To(ConsistOf(
+			corev1.VolumeMount{Name: storageVolumeName, MountPath: modelsPath},
+			corev1.VolumeMount{Name: "registry-auth-0", MountPath: "/etc/ollama-registry/0", ReadOnly: true},
+		))
+		g.Expect(pod.Spec.Volumes).To(ContainElements(
+			corev1.Volume{Name: storageVolumeName, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			corev1.Volume{Name: "registry-auth-0", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "mirror-auth"}}},
+		))
+		g.Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: storageVolumeName, MountPath: modelsPath}))
+	})
+
+	t.Run("Should share the configured storage with the agent", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+				Template: &ollamav1alpha1.ModelTemplate{
+					Spec: &ollamav1alpha1.ModelTemplateSpec{Storage: &ollamav1alpha1.ModelStorage{}},
+				},
+			},
+		}
+		pod := &corev1.Pod{}
+		g.Expect(r.modelToPod(model, pod)).To(Succeed())
+		g.Expect(pod.Spec.InitContainers).To(HaveLen(1))
+		volume := pod.Spec.Volumes[len(pod.Spec.Volumes)-1]
+		g.Expect(volume.Name).To(Equal(storageVolumeName))
+		g.Expect(volume.Ephemeral).NotTo(BeNil())
+	})
+
+	t.Run("Should not pull the images read from the ModelCache", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3"},
+				Template: &ollamav1alpha1.ModelTemplate{
+					Spec: &ollamav1alpha1.ModelTemplateSpec{Storage: &ollamav1alpha1.ModelStorage{CacheName: ptr.To("shared")}},
+				},
+			},
+		}
+		pod := &corev1.Pod{}
+		g.Expect(r.modelToPod(model, pod)).To(Succeed())
+		g.Expect(pod.Spec.InitContainers).To(BeEmpty())
+	})
+
+	t.Run("Should pull the images by the ollama server without the registry configuration", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{Spec: ollamav1alpha1.ModelSpec{Images: []string{"llama3"}}}
+		pod := &corev1.Pod{}
+		g.Expect((&ModelReconciler{}).modelToPod(model, pod)).To(Succeed())
+		g.Expect(pod.Spec.InitContainers).To(BeEmpty())
+		g.Expect(pod.Spec.Volumes).To(BeEmpty())
+	})
+}
+
+func TestPullJobWithRegistry(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(ollamav1alpha1.AddToScheme(scheme)).To(Succeed())
+	r := &ModelCacheReconciler{
+		Scheme:     scheme,
+		AgentImage: "agent:latest",
+	}
+	cache := &ollamav1alpha1.ModelCache{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "default", UID: "uid-shared"},
+		Spec: ollamav1alpha1.ModelCacheSpec{
+			Images: []string{"llama3"},
+			Registry: &ollamav1alpha1.ModelRegistry{
+				Hosts: []ollamav1alpha1.RegistryHost{{Host: "registry.ollama.ai", AuthSecretName: ptr.To("auth")}},
+			},
+		},
+	}
+	job, err := r.pullJob(cache, "llama3", "shared-pull")
+	g.Expect(err).NotTo(HaveOccurred())
+	spec := job.Spec.Template.Spec
+	g.Expect(spec.Containers).To(HaveLen(1))
+	g.Expect(spec.Containers[0].Name).To(Equal(pullContainerName))
+	g.Expect(spec.Containers[0].Image).To(Equal("agent:latest"))
+	g.Expect(spec.Containers[0].Args).To(ContainElements("--registry-auth=registry.ollama.ai=/etc/ollama-registry/0", "llama3"))
+	g.Expect(spec.Volumes).To(HaveLen(2))
+	g.Expect(spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("shared"))
+}
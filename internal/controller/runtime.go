@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/blang/semver/v4"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+	"github.com/sivchari/ollama-operator/internal/podutil"
+)
+
+const defaultOllamaContainerImage = "ollama/ollama:latest"
+
+// runtimeImage returns the ollama server image used by model.
+func (r *ModelReconciler) runtimeImage(model *ollamav1alpha1.Model) string {
+	if model.Spec.Runtime != nil && model.Spec.Runtime.Image != "" {
+		return model.Spec.Runtime.Image
+	}
+	if r.OllamaContainerImage != "" {
+		return r.OllamaContainerImage
+	}
+	return defaultOllamaContainerImage
+}
+
+// reconcileRuntime reports the runtime of the pod referenced by the Model.
+func (r *ModelReconciler) reconcileRuntime(ctx context.Context, model *ollamav1alpha1.Model, pod *corev1.Pod) {
+	if pod == nil {
+		return
+	}
+	image := containerImage(pod, ollamaServerContainerName)
+	if model.Status.Runtime == nil || model.Status.Runtime.Image != image {
+		model.Status.Runtime = &ollamav1alpha1.ModelRuntimeStatus{Image: image}
+	}
+	if !podutil.IsReady(pod) {
+		return
+	}
+	if _, err := r.checkRuntime(ctx, model, pod); err != nil {
+		ctrl.LoggerFrom(ctx).V(1).Error(err, "unable to check the ollama server runtime", "pod", pod.Name)
+	}
+	if err := r.reconcileImages(ctx, model, pod); err != nil {
+		ctrl.LoggerFrom(ctx).V(1).Error(err, "unable to list the pulled models", "pod", pod.Name)
+	}
+}
+
+// reconcileImages records the images of the Model pulled by the ollama server running in pod with their size.
+func (r *ModelReconciler) reconcileImages(ctx context.Context, model *ollamav1alpha1.Model, pod *corev1.Pod) error {
+	pulled, err := ollama.NewClient(podURL(pod), nil).List(ctx)
+	if err != nil {
+		return err
+	}
+	sizes := make(map[string]int64, len(pulled))
+	for _, m := range pulled {
+		sizes[ollama.NormalizeName(m.Name)] = m.Size
+	}
+	var images []ollamav1alpha1.ModelImageStatus
+	for _, image := range model.Spec.Images {
+		if size, ok := sizes[ollama.NormalizeName(image)]; ok {
+			images = append(images, ollamav1alpha1.ModelImageStatus{
+				Name: image,
+				Size: *resource.NewQuantity(size, resource.BinarySI),
+			})
+		}
+	}
+	model.Status.Images = images
+	return nil
+}
+
+// checkRuntime reports whether the ollama server running in pod satisfies spec.runtime.version.
+// The reported version and the RuntimeCompatible condition are recorded in the Model status.
+func (r *ModelReconciler) checkRuntime(ctx context.Context, model *ollamav1alpha1.Model, pod *corev1.Pod) (bool, error) {
+	version, err := ollama.NewClient(podURL(pod), nil).Version(ctx)
+	if err != nil {
+		return false, err
+	}
+	if model.Status.PodRef != nil && model.Status.PodRef.Name == pod.Name {
+		model.Status.Runtime = &ollamav1alpha1.ModelRuntimeStatus{
+			Image:   containerImage(pod, ollamaServerContainerName),
+			Version: version,
+		}
+	}
+
+	if model.Spec.Runtime == nil || model.Spec.Runtime.Version == "" {
+		meta.RemoveStatusCondition(&model.Status.Conditions, ollamav1alpha1.ModelConditionRuntimeCompatible)
+		return true, nil
+	}
+	condition := metav1.Condition{
+		Type:               ollamav1alpha1.ModelConditionRuntimeCompatible,
+		ObservedGeneration: model.Generation,
+	}
+	defer func() { meta.SetStatusCondition(&model.Status.Conditions, condition) }()
+
+	versionRange, err := semver.ParseRange(model.Spec.Runtime.Version)
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ollamav1alpha1.RuntimeVersionInvalid
+		condition.Message = err.Error()
+		return false, nil
+	}
+	v, err := semver.ParseTolerant(version)
+	if err != nil || !versionRange(v) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ollamav1alpha1.RuntimeVersionUnsatisfied
+		condition.Message = fmt.Sprintf("ollama %s in pod %s does not satisfy %q", version, pod.Name, model.Spec.Runtime.Version)
+		return false, nil
+	}
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = ollamav1alpha1.RuntimeVersionSatisfied
+	condition.Message = fmt.Sprintf("ollama %s satisfies %q", version, model.Spec.Runtime.Version)
+	return true, nil
+}
+
+func podURL(pod *corev1.Pod) string {
+	return "http://" + net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(ollama.DefaultPort))
+}
+
+func containerImage(pod *corev1.Pod, name string) string {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == name {
+			return c.Image
+		}
+	}
+	return ""
+}
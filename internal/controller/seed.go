@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/pull"
+)
+
+const ollamaSeedContainerName = "ollama-seed"
+
+// peerSeeding returns true if the pods of the Model copy the blobs from each other.
+func peerSeeding(model *ollamav1alpha1.Model) bool {
+	return ptr.Deref(model.Spec.PeerSeeding, false) && modelCacheName(model) == ""
+}
+
+// peerServiceName returns the name of the headless Service which publishes the ready pods of the Model to their peers.
+func peerServiceName(model *ollamav1alpha1.Model) string {
+	return model.Name + "-peers"
+}
+
+// peerHost returns the DNS name the agent resolves the ready peers of the Model from.
+func peerHost(model *ollamav1alpha1.Model) string {
+	return fmt.Sprintf("%s.%s.svc", peerServiceName(model), model.Namespace)
+}
+
+// reconcilePeerService ensures the headless Service of the seeders of the Model while spec.peerSeeding is set.
+// Only the ready pods are published, which include the outdated pods during a rollout.
+func (r *ModelReconciler) reconcilePeerService(ctx context.Context, model *ollamav1alpha1.Model) error {
+	svc := &corev1.Service{}
+	svc.Namespace = model.Namespace
+	svc.Name = peerServiceName(model)
+
+	if !peerSeeding(model) {
+		return r.deleteOwned(ctx, model, svc)
+	}
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, svc, func() error {
+		if svc.Labels == nil {
+			svc.Labels = map[string]string{}
+		}
+		svc.Labels[ollamav1alpha1.ModelNameLabel] = model.Name
+		svc.Spec.Type = corev1.ServiceTypeClusterIP
+		svc.Spec.ClusterIP = corev1.ClusterIPNone
+		svc.Spec.PublishNotReadyAddresses = false
+		svc.Spec.Selector = map[string]string{ollamav1alpha1.ModelNameLabel: model.Name}
+		svc.Spec.Ports = []corev1.ServicePort{
+			{
+				Name:       "seed",
+				Port:       pull.SeedPort,
+				TargetPort: intstr.FromInt32(pull.SeedPort),
+				Protocol:   corev1.ProtocolTCP,
+			},
+		}
+		return controllerutil.SetControllerReference(model, svc, r.Scheme)
+	})
+	return err
+}
+
+// injectSeeder serves the blobs of the models volume of pod to its peers by the agent.
+// The volume is mounted read-only, so the seeder never changes the models of the ollama server.
+func (r *ModelReconciler) injectSeeder(pod *corev1.Pod) {
+	pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{
+		Name:    ollamaSeedContainerName,
+		Image:   r.AgentImage,
+		Command: []string{"/agent"},
+		Args: []string{
+			"seed",
+			"--models-dir=" + modelsPath,
+			fmt.Sprintf("--bind-address=:%d", pull.SeedPort),
+		},
+		VolumeMounts: []corev1.VolumeMount{{Name: storageVolumeName, MountPath: modelsPath, ReadOnly: true}},
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "seed",
+				ContainerPort: pull.SeedPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+	})
+}
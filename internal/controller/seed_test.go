@@ -0,0 +1,140 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestModelToPodWithPeerSeeding(t *testing.T) {
+	g := NewWithT(t)
+	r := &ModelReconciler{AgentImage: "agent:latest"}
+	model := &ollamav1alpha1.Model{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama3", Namespace: "default"},
+		Spec: ollamav1alpha1.ModelSpec{
+			Images:      []string{"llama3"},
+			PeerSeeding: ptr.To(true),
+		},
+	}
+	pod := &corev1.Pod{}
+	g.Expect(r.modelToPod(model, pod)).To(Succeed())
+	g.Expect(pod.Spec.InitContainers).To(HaveLen(1))
+	g.Expect(pod.Spec.InitContainers[0].Args).To(Equal([]string{
+		"pull",
+		"--models-dir=/root/.ollama/models",
+		"--peers=llama3-peers.default.svc",
+		"--",
+		"llama3",
+	}))
+	g.Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
+		Name:         storageVolumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	}))
+	seeder := pod.Spec.Containers[len(pod.Spec.Containers)-1]
+	g.Expect(seeder.Name).To(Equal(ollamaSeedContainerName))
+	g.Expect(seeder.Args).To(Equal([]string{"seed", "--models-dir=/root/.ollama/models", "--bind-address=:11436"}))
+	g.Expect(seeder.VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: storageVolumeName, MountPath: modelsPath, ReadOnly: true}))
+}
+
+func TestModelToPodWithPeerSeedingAndProxy(t *testing.T) {
+	g := NewWithT(t)
+	r := &ModelReconciler{AgentImage: "agent:latest"}
+	model := &ollamav1alpha1.Model{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama3", Namespace: "default"},
+		Spec: ollamav1alpha1.ModelSpec{
+			Images:      []string{"llama3"},
+			PeerSeeding: ptr.To(true),
+			Auth:        &ollamav1alpha1.ModelAuth{APIKeys: []ollamav1alpha1.ModelAPIKeySecret{{Name: "team-a"}}},
+		},
+	}
+	pod := &corev1.Pod{}
+	g.Expect(r.modelToPod(model, pod)).To(Succeed())
+	g.Expect(pod.Spec.Containers).To(ContainElement(HaveField("Name", ollamaProxyContainerName)))
+	g.Expect(pod.Spec.Containers).To(ContainElement(HaveField("Name", ollamaSeedContainerName)))
+
+	// The containers share the network namespace of the pod, so each port is bound by one container,
+	// including the ollama server listening on the loopback address behind the proxy.
+	ports := map[int32]string{}
+	bind := func(container string, port int32) {
+		g.Expect(ports).NotTo(HaveKey(port), "port %d is bound by %s and %s", port, ports[port], container)
+		ports[port] = container
+	}
+	for _, c := range pod.Spec.Containers {
+		for _, port := range c.Ports {
+			bind(c.Name, port.ContainerPort)
+		}
+		for _, env := range c.Env {
+			if env.Name != "OLLAMA_HOST" {
+				continue
+			}
+			_, port, err := net.SplitHostPort(env.Value)
+			g.Expect(err).NotTo(HaveOccurred())
+			n, err := strconv.ParseInt(port, 10, 32)
+			g.Expect(err).NotTo(HaveOccurred())
+			bind(c.Name, int32(n))
+		}
+	}
+}
+
+func TestReconcilePeerService(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(ollamav1alpha1.AddToScheme(scheme)).To(Succeed())
+	model := &ollamav1alpha1.Model{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama3", Namespace: "default", UID: "uid"},
+		Spec:       ollamav1alpha1.ModelSpec{Images: []string{"llama3"}, PeerSeeding: ptr.To(true)},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(model).Build()
+	r := &ModelReconciler{Client: c, Scheme: scheme}
+	key := client.ObjectKey{Namespace: "default", Name: "llama3-peers"}
+
+	t.Run("Should publish the ready pods of the Model", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(r.reconcilePeerService(context.Background(), model)).To(Succeed())
+		svc := &corev1.Service{}
+		g.Expect(c.Get(context.Background(), key, svc)).To(Succeed())
+		g.Expect(svc.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+		g.Expect(svc.Spec.PublishNotReadyAddresses).To(BeFalse())
+		g.Expect(svc.Spec.Selector).To(Equal(map[string]string{ollamav1alpha1.ModelNameLabel: "llama3"}))
+		g.Expect(svc.Spec.Ports).To(HaveLen(1))
+		g.Expect(svc.Spec.Ports[0].Port).To(Equal(int32(11436)))
+	})
+
+	t.Run("Should delete the Service when the seeding is disabled", func(t *testing.T) {
+		g := NewWithT(t)
+		model.Spec.PeerSeeding = nil
+		g.Expect(r.reconcilePeerService(context.Background(), model)).To(Succeed())
+		err := c.Get(context.Background(), key, &corev1.Service{})
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+}
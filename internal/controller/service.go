@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+	"github.com/sivchari/ollama-operator/internal/podutil"
+)
+
+// reconcileService ensures the Service which has the same name as the Model and exposes its serving pods.
+// While the Model is scaled to zero because it is idle, the Service is an alias of the activator,
+// so a request sent to the Service address wakes up the Model. Once the Model is woken up, the Service stays
+// the alias until a serving pod is ready, so the activator holds the requests sent during the cold start.
+func (r *ModelReconciler) reconcileService(ctx context.Context, model *ollamav1alpha1.Model, idle bool) error {
+	ready := true
+	if !idle && r.ActivatorHost != "" {
+		pods, err := r.listPods(ctx, model)
+		if err != nil {
+			return err
+		}
+		ready = slices.ContainsFunc(pods, podutil.IsReady)
+	}
+	svc := &corev1.Service{}
+	svc.Namespace = model.Namespace
+	svc.Name = model.Name
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, svc, func() error {
+		if svc.Labels == nil {
+			svc.Labels = map[string]string{}
+		}
+		svc.Labels[ollamav1alpha1.ModelNameLabel] = model.Name
+		waking := !ready && svc.Spec.Type == corev1.ServiceTypeExternalName
+		if (idle || waking) && r.ActivatorHost != "" {
+			svc.Spec.Type = corev1.ServiceTypeExternalName
+			svc.Spec.ExternalName = r.ActivatorHost
+			svc.Spec.Selector = nil
+			svc.Spec.ClusterIP = ""
+			svc.Spec.ClusterIPs = nil
+			svc.Spec.IPFamilies = nil
+			svc.Spec.IPFamilyPolicy = nil
+			svc.Spec.InternalTrafficPolicy = nil
+		} else {
+			svc.Spec.Type = corev1.ServiceTypeClusterIP
+			svc.Spec.ExternalName = ""
+			svc.Spec.Selector = map[string]string{ollamav1alpha1.ModelNameLabel: model.Name}
+		}
+		svc.Spec.Ports = []corev1.ServicePort{
+			{
+				Name:       "ollama-server",
+				Port:       ollama.DefaultPort,
+				TargetPort: intstr.FromInt32(ollama.DefaultPort),
+				Protocol:   corev1.ProtocolTCP,
+			},
+		}
+		return controllerutil.SetControllerReference(model, svc, r.Scheme)
+	})
+	return err
+}
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+const (
+	// sourcesDir is the directory the volumes of the OCI sources are mounted in.
+	sourcesDir = "/var/lib/ollama-sources"
+	// weightsDir is the directory the weights are fetched into, with the generated Modelfiles.
+	weightsDir        = "/var/lib/ollama-weights"
+	weightsVolumeName = "weights"
+	// weightsSourcesDir is the directory the volumes of the weights sources are mounted in.
+	weightsSourcesDir = "/var/lib/ollama-weights-sources"
+	// weightsTokenDir is the directory the Secrets of the bearer tokens fetching the weights are mounted in.
+	weightsTokenDir = "/etc/ollama-weights"
+)
+
+// ociSources returns the OCI sources of images, in the order of sources.
+func ociSources(sources []ollamav1alpha1.ModelSource, images []string) []ollamav1alpha1.ModelSource {
+	return slices.DeleteFunc(slices.Clone(sources), func(s ollamav1alpha1.ModelSource) bool {
+		return s.OCI == nil || !slices.Contains(images, s.Image)
+	})
+}
+
+// weightsSources returns the weights sources of images, in the order of sources.
+func weightsSources(sources []ollamav1alpha1.ModelSource, images []string) []ollamav1alpha1.ModelSource {
+	return slices.DeleteFunc(slices.Clone(sources), func(s ollamav1alpha1.ModelSource) bool {
+		return s.Weights == nil || !slices.Contains(images, s.Image)
+	})
+}
+
+// pulledImages returns the images of the Model which are pulled or imported, not created from the weights.
+func pulledImages(model *ollamav1alpha1.Model) []string {
+	weights := weightsSources(model.Spec.Sources, model.Spec.Images)
+	return slices.DeleteFunc(slices.Clone(model.Spec.Images), func(image string) bool {
+		return slices.ContainsFunc(weights, func(s ollamav1alpha1.ModelSource) bool { return s.Image == image })
+	})
+}
+
+// sourceArgs returns the arguments of the agent importing the images from the OCI sources, and the volumes of the
+// sources it mounts. A PersistentVolumeClaim is mounted once for all the sources in it.
+func sourceArgs(sources []ollamav1alpha1.ModelSource) ([]string, []corev1.Volume, []corev1.VolumeMount) {
+	var (
+		args         []string
+		volumes      []corev1.Volume
+		volumeMounts []corev1.VolumeMount
+		claims       []string
+	)
+	for _, source := range sources {
+		if source.OCI == nil {
+			continue
+		}
+		i := slices.Index(claims, source.OCI.ClaimName)
+		if i < 0 {
+			i = len(claims)
+			claims = append(claims, source.OCI.ClaimName)
+			name := fmt.Sprintf("source-%d", i)
+			volumes = append(volumes, corev1.Volume{
+				Name: name,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: source.OCI.ClaimName, ReadOnly: true},
+				},
+			})
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: name, MountPath: path.Join(sourcesDir, strconv.Itoa(i)), ReadOnly: true})
+		}
+		args = append(args, fmt.Sprintf("--oci-layout=%s=%s", source.Image, path.Join(sourcesDir, strconv.Itoa(i), source.OCI.Path)))
+		if source.OCI.Reference != "" {
+			args = append(args, fmt.Sprintf("--oci-reference=%s=%s", source.Image, source.OCI.Reference))
+		}
+	}
+	return args, volumes, volumeMounts
+}
+
+// modelfiles returns the paths of the Modelfiles the images are created from by the weights sources.
+func modelfiles(sources []ollamav1alpha1.ModelSource) map[string]string {
+	if len(sources) == 0 {
+		return nil
+	}
+	paths := make(map[string]string, len(sources))
+	for i, source := range sources {
+		paths[source.Image] = path.Join(weightsDir, strconv.Itoa(i), "Modelfile")
+	}
+	return paths
+}
+
+// injectWeights fetches the weights of the sources by the agent in the init containers, which write the Modelfiles
+// the ollama server creates the images from. The weights in the volumes are mounted in the ollama server.
+func (r *ModelReconciler) injectWeights(pod *corev1.Pod, sources []ollamav1alpha1.ModelSource) {
+	pod.Spec.Volumes = append(slices.Clone(pod.Spec.Volumes), corev1.Volume{
+		Name:         weightsVolumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+	serverMounts := []corev1.VolumeMount{{Name: weightsVolumeName, MountPath: weightsDir, ReadOnly: true}}
+	for i, source := range sources {
+		weights := source.Weights
+		dir := path.Join(weightsDir, strconv.Itoa(i))
+		args := []string{"fetch", "--dir=" + dir}
+		volumeMounts := []corev1.VolumeMount{{Name: weightsVolumeName, MountPath: weightsDir}}
+		var (
+			from  string
+			files []string
+		)
+		if weights.ClaimName != "" {
+			name := fmt.Sprintf("weights-source-%d", i)
+			mountPath := path.Join(weightsSourcesDir, strconv.Itoa(i))
+			pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+				Name: name,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: weights.ClaimName, ReadOnly: true},
+				},
+			})
+			serverMounts = append(serverMounts, corev1.VolumeMount{Name: name, MountPath: mountPath, ReadOnly: true})
+			from = path.Join(mountPath, weights.Path)
+		} else {
+			if weights.BearerTokenSecretName != nil {
+				name := fmt.Sprintf("weights-token-%d", i)
+				tokenDir := path.Join(weightsTokenDir, strconv.Itoa(i))
+				pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+					Name: name,
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{SecretName: *weights.BearerTokenSecretName},
+					},
+				})
+				volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: name, MountPath: tokenDir, ReadOnly: true})
+				args = append(args, "--bearer-token-file="+path.Join(tokenDir, "token"))
+			}
+			for j, file := range weights.Files {
+				name := weightsFileName(file, j)
+				files = append(files, fmt.Sprintf("%s=%s=%s", name, strings.ToLower(file.SHA256), file.URL))
+				from = path.Join(dir, name)
+			}
+			// The ollama server creates a model from a GGUF file, or from the directory of the safetensors files.
+			if len(weights.Files) != 1 || !strings.HasSuffix(strings.ToLower(from), ".gguf") {
+				from = dir
+			}
+		}
+		args = append(append(args, "--modelfile="+generateModelfile(from, weights.Modelfile)), files...)
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{
+			Name:         fmt.Sprintf("ollama-fetch-%d", i),
+			Image:        r.AgentImage,
+			Command:      []string{"/agent"},
+			Args:         args,
+			VolumeMounts: volumeMounts,
+		})
+	}
+	for i := range pod.Spec.Containers {
+		if c := &pod.Spec.Containers[i]; c.Name == ollamaServerContainerName {
+			c.VolumeMounts = append(c.VolumeMounts, serverMounts...)
+		}
+	}
+}
+
+// weightsFileName returns the name of the j-th file of the weights, which defaults to the last segment of its URL.
+func weightsFileName(file ollamav1alpha1.WeightsFile, j int) string {
+	if file.Name != "" {
+		return file.Name
+	}
+	if u, err := url.Parse(file.URL); err == nil {
+		if name := path.Base(u.Path); name != "/" && !strings.HasPrefix(name, ".") && !strings.Contains(name, "=") {
+			return name
+		}
+	}
+	return fmt.Sprintf("file-%d", j)
+}
+
+// generateModelfile returns the Modelfile creating a model from the weights at from, followed by modelfile.
+func generateModelfile(from, modelfile string) string {
+	generated := "FROM " + from + "\n"
+	if modelfile != "" {
+		generated += strings.TrimSuffix(modelfile, "\n") + "\n"
+	}
+	return generated
+}
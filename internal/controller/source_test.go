@@ -0,0 +1,214 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestModelToPodWithSources(t *testing.T) {
+	r := &ModelReconciler{AgentImage: "agent:latest"}
+
+	t.Run("Should import the images from the OCI image layouts by the agent", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3:8b", "llama3:70b", "phi3"},
+				Sources: []ollamav1alpha1.ModelSource{
+					{Image: "llama3:8b", OCI: &ollamav1alpha1.OCISource{ClaimName: "models", Path: "llama3", Reference: "8b"}},
+					{Image: "llama3:70b", OCI: &ollamav1alpha1.OCISource{ClaimName: "models", Path: "llama3", Reference: "70b"}},
+					{Image: "phi3", OCI: &ollamav1alpha1.OCISource{ClaimName: "phi3", Path: "phi3.tar"}},
+					{Image: "mistral", OCI: &ollamav1alpha1.OCISource{ClaimName: "mistral"}},
+				},
+			},
+		}
+		pod := &corev1.Pod{}
+		g.Expect(r.modelToPod(model, pod)).To(Succeed())
+		g.Expect(pod.Spec.InitContainers).To(HaveLen(1))
+		init := pod.Spec.InitContainers[0]
+		g.Expect(init.Args).To(Equal([]string{
+			"pull",
+			"--models-dir=/root/.ollama/models",
+			"--oci-layout=llama3:8b=/var/lib/ollama-sources/0/llama3",
+			"--oci-reference=llama3:8b=8b",
+			"--oci-layout=llama3:70b=/var/lib/ollama-sources/0/llama3",
+			"--oci-reference=llama3:70b=70b",
+			"--oci-layout=phi3=/var/lib/ollama-sources/1/phi3.tar",
+			"--",
+			"llama3:8b",
+			"llama3:70b",
+			"phi3",
+		}))
+		g.Expect(init.VolumeMounts).To(ConsistOf(
+			corev1.VolumeMount{Name: storageVolumeName, MountPath: modelsPath},
+			corev1.VolumeMount{Name: "source-0", MountPath: "/var/lib/ollama-sources/0", ReadOnly: true},
+			corev1.VolumeMount{Name: "source-1", MountPath: "/var/lib/ollama-sources/1", ReadOnly: true},
+		))
+		g.Expect(pod.Spec.Volumes).To(ContainElements(
+			corev1.Volume{Name: "source-0", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "models", ReadOnly: true},
+			}},
+			corev1.Volume{Name: "source-1", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "phi3", ReadOnly: true},
+			}},
+		))
+		g.Expect(pod.Spec.Volumes).NotTo(ContainElement(HaveField("Name", "source-2")))
+	})
+
+	t.Run("Should not inject the agent for the sources of the images which are not in the Model", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			Spec: ollamav1alpha1.ModelSpec{
+				Images:  []string{"llama3"},
+				Sources: []ollamav1alpha1.ModelSource{{Image: "phi3", OCI: &ollamav1alpha1.OCISource{ClaimName: "phi3"}}},
+			},
+		}
+		pod := &corev1.Pod{}
+		g.Expect(r.modelToPod(model, pod)).To(Succeed())
+		g.Expect(pod.Spec.InitContainers).To(BeEmpty())
+	})
+}
+
+func TestPullJobWithSources(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(ollamav1alpha1.AddToScheme(scheme)).To(Succeed())
+	r := &ModelCacheReconciler{Scheme: scheme, AgentImage: "agent:latest"}
+	cache := &ollamav1alpha1.ModelCache{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "default", UID: "uid-shared"},
+		Spec: ollamav1alpha1.ModelCacheSpec{
+			Images: []string{"llama3", "phi3"},
+			Sources: []ollamav1alpha1.ModelSource{
+				{Image: "phi3", OCI: &ollamav1alpha1.OCISource{ClaimName: "models", Path: "phi3"}},
+			},
+		},
+	}
+
+	job, err := r.pullJob(cache, "phi3", "shared-phi3")
+	g.Expect(err).NotTo(HaveOccurred())
+	spec := job.Spec.Template.Spec
+	g.Expect(spec.Containers[0].Image).To(Equal("agent:latest"))
+	g.Expect(spec.Containers[0].Args).To(ContainElements("--oci-layout=phi3=/var/lib/ollama-sources/0/phi3", "phi3"))
+	g.Expect(spec.Volumes).To(HaveLen(2))
+
+	// The image without the source is pulled by the ollama server.
+	job, err = r.pullJob(cache, "llama3", "shared-llama3")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(job.Spec.Template.Spec.Containers[0].Image).NotTo(Equal("agent:latest"))
+}
+
+func TestModelToPodWithWeights(t *testing.T) {
+	r := &ModelReconciler{AgentImage: "agent:latest"}
+	const sum = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	t.Run("Should create the images from the fetched weights and pull the others", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"llama3", "tiny", "qwen"},
+				Sources: []ollamav1alpha1.ModelSource{
+					{Image: "tiny", Weights: &ollamav1alpha1.WeightsSource{
+						Files:                 []ollamav1alpha1.WeightsFile{{URL: "https://example.com/models/tiny.Q4_K_M.gguf?download=true", SHA256: sum}},
+						BearerTokenSecretName: ptr.To("hf-token"),
+						Modelfile:             "PARAMETER temperature 0.2",
+					}},
+					{Image: "qwen", Weights: &ollamav1alpha1.WeightsSource{ClaimName: "weights", Path: "qwen"}},
+				},
+			},
+		}
+		pod := &corev1.Pod{}
+		g.Expect(r.modelToPod(model, pod)).To(Succeed())
+		g.Expect(pod.Spec.Containers[0].Lifecycle.PostStart.Exec.Command).To(Equal([]string{"/bin/sh", "-c",
+			"ollama show llama3 >/dev/null 2>&1 || ollama pull llama3;" +
+				"ollama show tiny >/dev/null 2>&1 || ollama create tiny -f /var/lib/ollama-weights/0/Modelfile;" +
+				"ollama show qwen >/dev/null 2>&1 || ollama create qwen -f /var/lib/ollama-weights/1/Modelfile;",
+		}))
+		g.Expect(pod.Spec.InitContainers).To(HaveLen(2))
+		fetch := pod.Spec.InitContainers[0]
+		g.Expect(fetch.Name).To(Equal("ollama-fetch-0"))
+		g.Expect(fetch.Image).To(Equal("agent:latest"))
+		g.Expect(fetch.Args).To(Equal([]string{
+			"fetch",
+			"--dir=/var/lib/ollama-weights/0",
+			"--bearer-token-file=/etc/ollama-weights/0/token",
+			"--modelfile=FROM /var/lib/ollama-weights/0/tiny.Q4_K_M.gguf\nPARAMETER temperature 0.2\n",
+			"tiny.Q4_K_M.gguf=" + sum + "=https://example.com/models/tiny.Q4_K_M.gguf?download=true",
+		}))
+		g.Expect(fetch.VolumeMounts).To(ConsistOf(
+			corev1.VolumeMount{Name: weightsVolumeName, MountPath: weightsDir},
+			corev1.VolumeMount{Name: "weights-token-0", MountPath: "/etc/ollama-weights/0", ReadOnly: true},
+		))
+		g.Expect(pod.Spec.InitContainers[1].Args).To(Equal([]string{
+			"fetch",
+			"--dir=/var/lib/ollama-weights/1",
+			"--modelfile=FROM /var/lib/ollama-weights-sources/1/qwen\n",
+		}))
+		g.Expect(pod.Spec.Volumes).To(ContainElements(
+			corev1.Volume{Name: weightsVolumeName, VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			corev1.Volume{Name: "weights-token-0", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "hf-token"}}},
+			corev1.Volume{Name: "weights-source-1", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "weights", ReadOnly: true},
+			}},
+		))
+		g.Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElements(
+			corev1.VolumeMount{Name: weightsVolumeName, MountPath: weightsDir, ReadOnly: true},
+			corev1.VolumeMount{Name: "weights-source-1", MountPath: "/var/lib/ollama-weights-sources/1", ReadOnly: true},
+		))
+	})
+
+	t.Run("Should create the image from the directory of the safetensors files", func(t *testing.T) {
+		g := NewWithT(t)
+		model := &ollamav1alpha1.Model{
+			Spec: ollamav1alpha1.ModelSpec{
+				Images: []string{"custom"},
+				Sources: []ollamav1alpha1.ModelSource{
+					{Image: "custom", Weights: &ollamav1alpha1.WeightsSource{
+						Files: []ollamav1alpha1.WeightsFile{
+							{URL: "https://example.com/custom/model.safetensors", SHA256: sum},
+							{URL: "https://example.com/custom/config.json", SHA256: strings.ToUpper(sum)},
+							{URL: "https://example.com/custom/", SHA256: sum, Name: "tokenizer.json"},
+						},
+					}},
+				},
+				Registry: &ollamav1alpha1.ModelRegistry{Hosts: []ollamav1alpha1.RegistryHost{{Host: "registry.ollama.ai", Insecure: true}}},
+			},
+		}
+		pod := &corev1.Pod{}
+		g.Expect(r.modelToPod(model, pod)).To(Succeed())
+		// The created image is not pulled by the agent.
+		g.Expect(pod.Spec.InitContainers).To(HaveLen(1))
+		g.Expect(pod.Spec.InitContainers[0].Args).To(Equal([]string{
+			"fetch",
+			"--dir=/var/lib/ollama-weights/0",
+			"--modelfile=FROM /var/lib/ollama-weights/0\n",
+			"model.safetensors=" + sum + "=https://example.com/custom/model.safetensors",
+			"config.json=" + sum + "=https://example.com/custom/config.json",
+			"tokenizer.json=" + sum + "=https://example.com/custom/",
+		}))
+	})
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+)
+
+const (
+	storageVolumeName = "ollama-models"
+	// modelsPath is the directory the ollama server stores the models in.
+	modelsPath = "/root/.ollama/models"
+	// nodeCachePath is the directory of the node cache on the nodes, which is managed by the node cache agent.
+	nodeCachePath = "/var/lib/ollama-operator/models"
+)
+
+// defaultStorageSize is the size of the storage volume if spec.template.spec.storage.size is not set.
+var defaultStorageSize = resource.MustParse("50Gi")
+
+// storageVolume returns the volume the models are stored in.
+// The ephemeral volume is claimed by each serving pod and deleted with it, while the node cache and
+// the volume of the ModelCache outlive the pods.
+func storageVolume(storage *ollamav1alpha1.ModelStorage) corev1.Volume {
+	if storage.CacheName != nil {
+		return corev1.Volume{
+			Name: storageVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: *storage.CacheName,
+					ReadOnly:  true,
+				},
+			},
+		}
+	}
+	if ptr.Deref(storage.NodeCache, false) {
+		return corev1.Volume{
+			Name: storageVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: nodeCachePath,
+					Type: ptr.To(corev1.HostPathDirectoryOrCreate),
+				},
+			},
+		}
+	}
+	size := defaultStorageSize
+	if storage.Size != nil {
+		size = *storage.Size
+	}
+	return corev1.Volume{
+		Name: storageVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Ephemeral: &corev1.EphemeralVolumeSource{
+				VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						StorageClassName: storage.StorageClassName,
+						Resources: corev1.VolumeResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func modelStorage(model *ollamav1alpha1.Model) *ollamav1alpha1.ModelStorage {
+	if model.Spec.Template == nil || model.Spec.Template.Spec == nil {
+		return nil
+	}
+	return model.Spec.Template.Spec.Storage
+}
+
+// usesNodeCache returns true if the Model stores the models in the node cache.
+func usesNodeCache(model *ollamav1alpha1.Model) bool {
+	storage := modelStorage(model)
+	return storage != nil && storage.CacheName == nil && ptr.Deref(storage.NodeCache, false)
+}
+
+// modelCacheName returns the name of the ModelCache the Model reads the models from, or an empty string.
+func modelCacheName(model *ollamav1alpha1.Model) string {
+	if storage := modelStorage(model); storage != nil {
+		return ptr.Deref(storage.CacheName, "")
+	}
+	return ""
+}
+
+// nodeCacheAffinity returns the preferences toward the nodes whose cache holds the images.
+// The more images a node holds, the more it is preferred.
+func nodeCacheAffinity(caches []ollamav1alpha1.NodeCache, images []string) []corev1.PreferredSchedulingTerm {
+	if len(images) == 0 {
+		return nil
+	}
+	nodes := map[int32][]string{}
+	for _, cache := range caches {
+		cached := 0
+		for _, image := range images {
+			name := ollama.NormalizeName(image)
+			if slices.ContainsFunc(cache.Status.Models, func(m ollamav1alpha1.NodeCacheModel) bool { return m.Name == name }) {
+				cached++
+			}
+		}
+		if cached == 0 {
+			continue
+		}
+		weight := max(int32(100*cached/len(images)), 1)
+		nodes[weight] = append(nodes[weight], cache.Name)
+	}
+	var terms []corev1.PreferredSchedulingTerm
+	for _, weight := range slices.Backward(slices.Sorted(maps.Keys(nodes))) {
+		names := nodes[weight]
+		slices.Sort(names)
+		terms = append(terms, corev1.PreferredSchedulingTerm{
+			Weight: weight,
+			Preference: corev1.NodeSelectorTerm{
+				MatchFields: []corev1.NodeSelectorRequirement{
+					{
+						Key:      metav1.ObjectNameField,
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   names,
+					},
+				},
+			},
+		})
+	}
+	return terms
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func newNodeCache(node string, models ...string) ollamav1alpha1.NodeCache {
+	cache := ollamav1alpha1.NodeCache{ObjectMeta: metav1.ObjectMeta{Name: node}}
+	for _, model := range models {
+		cache.Status.Models = append(cache.Status.Models, ollamav1alpha1.NodeCacheModel{Name: model})
+	}
+	return cache
+}
+
+func TestNodeCacheAffinity(t *testing.T) {
+	t.Run("Should prefer the nodes holding more images", func(t *testing.T) {
+		g := NewWithT(t)
+		caches := []ollamav1alpha1.NodeCache{
+			newNodeCache("node-a", "llama3:8b"),
+			newNodeCache("node-b", "llama3:8b", "mistral:latest"),
+			newNodeCache("node-c", "gemma:2b"),
+			newNodeCache("node-d", "mistral:latest"),
+		}
+		terms := nodeCacheAffinity(caches, []string{"registry.ollama.ai/library/llama3:8b", "mistral"})
+		g.Expect(terms).To(HaveLen(2))
+		g.Expect(terms[0].Weight).To(Equal(int32(100)))
+		g.Expect(terms[0].Preference.MatchFields[0].Key).To(Equal("metadata.name"))
+		g.Expect(terms[0].Preference.MatchFields[0].Values).To(Equal([]string{"node-b"}))
+		g.Expect(terms[1].Weight).To(Equal(int32(50)))
+		g.Expect(terms[1].Preference.MatchFields[0].Values).To(Equal([]string{"node-a", "node-d"}))
+	})
+
+	t.Run("Should not prefer any node if no node holds the images", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(nodeCacheAffinity([]ollamav1alpha1.NodeCache{newNodeCache("node-a", "gemma:2b")}, []string{"llama3"})).To(BeEmpty())
+	})
+}
+
+func TestDesiredPodWithNodeCache(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(ollamav1alpha1.AddToScheme(scheme)).To(Succeed())
+	model := &ollamav1alpha1.Model{
+		ObjectMeta: metav1.ObjectMeta{Name: "llama3", Namespace: "default", UID: "uid-llama3"},
+		Spec: ollamav1alpha1.ModelSpec{
+			Images: []string{"llama3"},
+			Template: &ollamav1alpha1.ModelTemplate{
+				Spec: &ollamav1alpha1.ModelTemplateSpec{
+					Storage: &ollamav1alpha1.ModelStorage{NodeCache: ptr.To(true)},
+				},
+			},
+		},
+	}
+
+	t.Run("Should mount the node cache", func(t *testing.T) {
+		g := NewWithT(t)
+		r := &ModelReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build(), Scheme: scheme}
+		pod, err := r.desiredPod(context.Background(), model)
+		g.Expect(err).NotTo(HaveOccurred())
+		volume := pod.Spec.Volumes[len(pod.Spec.Volumes)-1]
+		g.Expect(volume.Name).To(Equal(storageVolumeName))
+		g.Expect(volume.HostPath).NotTo(BeNil())
+		g.Expect(volume.HostPath.Path).To(Equal(nodeCachePath))
+		g.Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: storageVolumeName, MountPath: modelsPath}))
+		g.Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "OLLAMA_NOPRUNE", Value: "1"}))
+		g.Expect(pod.Spec.Affinity).To(BeNil())
+	})
+
+	t.Run("Should prefer the nodes holding the images without changing the revision", func(t *testing.T) {
+		g := NewWithT(t)
+		r := &ModelReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build(), Scheme: scheme}
+		uncached, err := r.desiredPod(context.Background(), model)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		cache := newNodeCache("node-a", "llama3:latest")
+		r.Client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&cache).Build()
+		cached, err := r.desiredPod(context.Background(), model)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(cached.Labels[ollamav1alpha1.PodTemplateHashLabel]).To(Equal(uncached.Labels[ollamav1alpha1.PodTemplateHashLabel]))
+		terms := cached.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+		g.Expect(terms).To(HaveLen(1))
+		g.Expect(terms[0].Preference.MatchFields[0].Values).To(Equal([]string{"node-a"}))
+	})
+}
+
+func TestStorageVolumeWithModelCache(t *testing.T) {
+	g := NewWithT(t)
+	r := &ModelReconciler{}
+	model := &ollamav1alpha1.Model{
+		Spec: ollamav1alpha1.ModelSpec{
+			Images: []string{"llama3"},
+			Template: &ollamav1alpha1.ModelTemplate{
+				Spec: &ollamav1alpha1.ModelTemplateSpec{
+					Storage: &ollamav1alpha1.ModelStorage{CacheName: ptr.To("shared"), NodeCache: ptr.To(true)},
+				},
+			},
+		},
+	}
+	pod := &corev1.Pod{}
+	g.Expect(r.modelToPod(model, pod)).To(Succeed())
+	volume := pod.Spec.Volumes[len(pod.Spec.Volumes)-1]
+	g.Expect(volume.PersistentVolumeClaim).To(Equal(&corev1.PersistentVolumeClaimVolumeSource{ClaimName: "shared", ReadOnly: true}))
+	g.Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: storageVolumeName, MountPath: modelsPath, ReadOnly: true}))
+	g.Expect(pod.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "OLLAMA_NOPRUNE", Value: "1"}))
+	g.Expect(usesNodeCache(model)).To(BeFalse())
+}
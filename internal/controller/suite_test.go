@@ -44,6 +44,28 @@ func TestMain(m *testing.M) {
 		if err != nil {
 			panic(err)
 		}
+		err = (&ModelQuotaReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr)
+		if err != nil {
+			panic(err)
+		}
+		err = (&ModelCacheReconciler{
+			Client:               mgr.GetClient(),
+			Scheme:               mgr.GetScheme(),
+			OllamaContainerImage: "ollama/ollama:latest",
+		}).SetupWithManager(mgr)
+		if err != nil {
+			panic(err)
+		}
+		err = (&ModelBindingReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr)
+		if err != nil {
+			panic(err)
+		}
 	}
 
 	SetDefaultEventuallyPollingInterval(100 * time.Millisecond)
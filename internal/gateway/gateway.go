@@ -0,0 +1,292 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gateway implements the gateway, which serves the models of all Models on a single endpoint.
+// The inference requests of the OpenAI compatible API and the native Ollama API are routed by the model in their body.
+// The management APIs of the ollama server, e.g. pulling and deleting models, are not served.
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+	"github.com/sivchari/ollama-operator/internal/podutil"
+)
+
+// defaultMaxBodySize is the maximum size of a request body, which is large enough for the images sent to the multimodal models.
+const defaultMaxBodySize = 64 << 20
+
+var errNotFound = errors.New("model not found")
+
+// inferencePaths are the paths of the requests routed to the Models.
+var inferencePaths = []string{
+	"/api/chat",
+	"/api/generate",
+	"/api/embed",
+	"/api/embeddings",
+	"/v1/chat/completions",
+	"/v1/completions",
+	"/v1/embeddings",
+}
+
+// Gateway is a http.Handler which routes the requests to a ready pod of the Models serving the requested model.
+type Gateway struct {
+	client.Client
+	// APIReader reads the Secrets of the API keys, so they are neither cached nor listed by the gateway.
+	// If it is nil, Client is used.
+	APIReader client.Reader
+	// Port is the port the ollama server listens on.
+	Port int
+	// MaxBodySize is the maximum size of a request body.
+	MaxBodySize int64
+
+	next     atomic.Uint64
+	limiters limiters
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := ctrl.LoggerFrom(ctx).WithValues("path", r.URL.Path)
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/models":
+		g.serveOpenAIModels(w, r)
+		return
+	case r.Method == http.MethodGet && r.URL.Path == "/api/tags":
+		g.serveTags(w, r)
+		return
+	case r.Method != http.MethodPost || !slices.Contains(inferencePaths, r.URL.Path):
+		// The management APIs of the ollama server, e.g. /api/pull and /api/delete, are not served by the gateway.
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, g.maxBodySize()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	var req struct {
+		Model string `json:"model"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil || req.Model == "" {
+		http.Error(w, "the request body must have the model", http.StatusBadRequest)
+		return
+	}
+
+	target, model, err := g.route(ctx, req.Model)
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			http.Error(w, fmt.Sprintf("model %q not found", req.Model), http.StatusNotFound)
+			return
+		}
+		log.Error(err, "unable to route the request", "model", req.Model)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	admission, retryAfter, err := g.admit(ctx, r, model)
+	if err != nil {
+		log.Error(err, "unable to check the rate limits", "model", req.Model)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", retryAfterSeconds(retryAfter))
+		http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+		},
+		// The responses of the ollama server are streamed.
+		FlushInterval: -1,
+	}
+	usage := &usageRecorder{ResponseWriter: w}
+	proxy.ServeHTTP(usage, r)
+	admission.charge(usage.Tokens())
+}
+
+// RoutingTable maps the normalized model names to the Models serving them.
+type RoutingTable map[string][]*ollamav1alpha1.Model
+
+// RoutingTable builds the routing table from the images of the Models. The paused Models are not routed.
+func (g *Gateway) RoutingTable(ctx context.Context) (RoutingTable, error) {
+	models := &ollamav1alpha1.ModelList{}
+	if err := g.List(ctx, models); err != nil {
+		return nil, err
+	}
+	table := RoutingTable{}
+	for i := range models.Items {
+		model := &models.Items[i]
+		if !model.DeletionTimestamp.IsZero() || (model.Spec.Paused != nil && *model.Spec.Paused) {
+			continue
+		}
+		for _, image := range model.Spec.Images {
+			name := ollama.NormalizeName(image)
+			table[name] = append(table[name], model)
+		}
+	}
+	return table, nil
+}
+
+// route returns the URL of a ready pod of the Models serving name, and the Model of the pod.
+// If no pod is ready, the Service of a Model is returned, which wakes up the Model if it is idle.
+func (g *Gateway) route(ctx context.Context, name string) (*url.URL, *ollamav1alpha1.Model, error) {
+	table, err := g.RoutingTable(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	models := table[ollama.NormalizeName(name)]
+	if len(models) == 0 {
+		return nil, nil, errNotFound
+	}
+	type backend struct {
+		pod   *corev1.Pod
+		model *ollamav1alpha1.Model
+	}
+	var ready []backend
+	for _, model := range models {
+		pods := &corev1.PodList{}
+		if err := g.List(ctx, pods, client.InNamespace(model.Namespace), client.MatchingLabels{ollamav1alpha1.ModelNameLabel: model.Name}); err != nil {
+			return nil, nil, err
+		}
+		for i := range pods.Items {
+			if podutil.IsReady(&pods.Items[i]) {
+				ready = append(ready, backend{pod: &pods.Items[i], model: model})
+			}
+		}
+	}
+	n := g.next.Add(1)
+	if len(ready) == 0 {
+		model := models[n%uint64(len(models))]
+		return &url.URL{
+			Scheme: "http",
+			Host:   net.JoinHostPort(fmt.Sprintf("%s.%s.svc", model.Name, model.Namespace), strconv.Itoa(g.port())),
+		}, model, nil
+	}
+	b := ready[n%uint64(len(ready))]
+	return &url.URL{
+		Scheme: "http",
+		Host:   net.JoinHostPort(b.pod.Status.PodIP, strconv.Itoa(g.port())),
+	}, b.model, nil
+}
+
+// openAIModel is a model in the response of GET /v1/models.
+type openAIModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+func (g *Gateway) serveOpenAIModels(w http.ResponseWriter, r *http.Request) {
+	table, err := g.RoutingTable(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	data := make([]openAIModel, 0, len(table))
+	for _, name := range table.names() {
+		data = append(data, openAIModel{
+			ID:      name,
+			Object:  "model",
+			Created: oldest(table[name]).Unix(),
+			OwnedBy: "library",
+		})
+	}
+	writeJSON(w, map[string]any{"object": "list", "data": data})
+}
+
+// tagModel is a model in the response of GET /api/tags.
+type tagModel struct {
+	Name       string    `json:"name"`
+	Model      string    `json:"model"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+func (g *Gateway) serveTags(w http.ResponseWriter, r *http.Request) {
+	table, err := g.RoutingTable(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	models := make([]tagModel, 0, len(table))
+	for _, name := range table.names() {
+		models = append(models, tagModel{Name: name, Model: name, ModifiedAt: oldest(table[name])})
+	}
+	writeJSON(w, map[string]any{"models": models})
+}
+
+func (t RoutingTable) names() []string {
+	names := make([]string, 0, len(t))
+	for name := range t {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func oldest(models []*ollamav1alpha1.Model) time.Time {
+	var t time.Time
+	for _, model := range models {
+		if t.IsZero() || model.CreationTimestamp.Time.Before(t) {
+			t = model.CreationTimestamp.Time
+		}
+	}
+	return t
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (g *Gateway) port() int {
+	if g.Port == 0 {
+		return ollama.DefaultPort
+	}
+	return g.Port
+}
+
+func (g *Gateway) maxBodySize() int64 {
+	if g.MaxBodySize == 0 {
+		return defaultMaxBodySize
+	}
+	return g.MaxBodySize
+}
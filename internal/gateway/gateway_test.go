@@ -0,0 +1,162 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := ollamav1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func newModel(namespace, name string, images ...string) *ollamav1alpha1.Model {
+	return &ollamav1alpha1.Model{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       ollamav1alpha1.ModelSpec{Images: images},
+	}
+}
+
+func newReadyPod(model *ollamav1alpha1.Model, ip string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: model.Namespace,
+			Name:      model.Name + "-abcde",
+			Labels:    map[string]string{ollamav1alpha1.ModelNameLabel: model.Name},
+		},
+		Status: corev1.PodStatus{
+			PodIP:      ip,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func TestGateway(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = io.WriteString(w, "served "+r.URL.Path+" "+string(body))
+	}))
+	t.Cleanup(backend.Close)
+	backendURL, err := url.Parse(backend.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, portStr, err := net.SplitHostPort(backendURL.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	llama := newModel("team-a", "llama", "llama3", "registry.ollama.ai/library/mistral:7b")
+	paused := newModel("team-b", "phi", "phi3")
+	paused.Spec.Paused = ptr.To(true)
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(llama, paused, newReadyPod(llama, host)).Build()
+	gw := &Gateway{Client: c, Port: port}
+
+	t.Run("Should route the OpenAI compatible request by the model", func(t *testing.T) {
+		g := NewWithT(t)
+		body := `{"model":"mistral:7b","messages":[]}`
+		req := httptest.NewRequest(http.MethodPost, "http://gateway/v1/chat/completions", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		gw.ServeHTTP(rec, req)
+		g.Expect(rec.Code).To(Equal(http.StatusOK))
+		g.Expect(rec.Body.String()).To(Equal("served /v1/chat/completions " + body))
+	})
+
+	t.Run("Should route the native request by the normalized model", func(t *testing.T) {
+		g := NewWithT(t)
+		body := `{"model":"llama3:latest","prompt":"hi"}`
+		req := httptest.NewRequest(http.MethodPost, "http://gateway/api/generate", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		gw.ServeHTTP(rec, req)
+		g.Expect(rec.Code).To(Equal(http.StatusOK))
+		g.Expect(rec.Body.String()).To(Equal("served /api/generate " + body))
+	})
+
+	t.Run("Should reject the request for an unknown or paused model", func(t *testing.T) {
+		g := NewWithT(t)
+		for _, model := range []string{"gemma", "phi3"} {
+			req := httptest.NewRequest(http.MethodPost, "http://gateway/api/chat", strings.NewReader(`{"model":"`+model+`"}`))
+			rec := httptest.NewRecorder()
+			gw.ServeHTTP(rec, req)
+			g.Expect(rec.Code).To(Equal(http.StatusNotFound))
+		}
+	})
+
+	t.Run("Should not route the management requests", func(t *testing.T) {
+		g := NewWithT(t)
+		for _, path := range []string{"/api/pull", "/api/create", "/api/push", "/api/delete", "/api/copy", "/api/show", "/api/blobs/sha256:0"} {
+			req := httptest.NewRequest(http.MethodPost, "http://gateway"+path, strings.NewReader(`{"model":"llama3"}`))
+			rec := httptest.NewRecorder()
+			gw.ServeHTTP(rec, req)
+			g.Expect(rec.Code).To(Equal(http.StatusNotFound), path)
+			g.Expect(rec.Body.String()).NotTo(ContainSubstring("served"), path)
+		}
+	})
+
+	t.Run("Should reject the request without the model", func(t *testing.T) {
+		g := NewWithT(t)
+		req := httptest.NewRequest(http.MethodPost, "http://gateway/api/chat", strings.NewReader(`{}`))
+		rec := httptest.NewRecorder()
+		gw.ServeHTTP(rec, req)
+		g.Expect(rec.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	t.Run("Should list the routed models", func(t *testing.T) {
+		g := NewWithT(t)
+		req := httptest.NewRequest(http.MethodGet, "http://gateway/v1/models", nil)
+		rec := httptest.NewRecorder()
+		gw.ServeHTTP(rec, req)
+		g.Expect(rec.Code).To(Equal(http.StatusOK))
+		var res struct {
+			Data []openAIModel `json:"data"`
+		}
+		g.Expect(json.NewDecoder(rec.Body).Decode(&res)).To(Succeed())
+		g.Expect(res.Data).To(HaveLen(2))
+		g.Expect(res.Data[0].ID).To(Equal("llama3:latest"))
+		g.Expect(res.Data[1].ID).To(Equal("mistral:7b"))
+	})
+}
@@ -0,0 +1,321 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"maps"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+// PodIPField is the field index of the pods by their IP, which is used to resolve the namespace of the clients.
+const PodIPField = "status.podIP"
+
+// unknownKey is the value of the key label of the metrics for the clients which are not pods.
+const unknownKey = "unknown"
+
+var (
+	rateLimitRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ollama_gateway_rate_limit_requests_total",
+		Help: "Number of the requests counted by the ModelRateLimits.",
+	}, []string{"namespace", "ratelimit", "key", "result"})
+	rateLimitTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ollama_gateway_rate_limit_tokens_total",
+		Help: "Number of the tokens counted by the ModelRateLimits.",
+	}, []string{"namespace", "ratelimit", "key"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(rateLimitRequests, rateLimitTokens)
+}
+
+// IndexPodIP is the indexer of PodIPField.
+func IndexPodIP(obj client.Object) []string {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Status.PodIP == "" {
+		return nil
+	}
+	return []string{pod.Status.PodIP}
+}
+
+// limiterIdleTimeout is the duration after which the buckets of an unused key are dropped.
+const limiterIdleTimeout = 10 * time.Minute
+
+// limiters holds the buckets of the ModelRateLimits per the counted key.
+type limiters struct {
+	mu         sync.Mutex
+	buckets    map[limiterKey]*limiter
+	lastPruned time.Time
+}
+
+type limiterKey struct {
+	uid        types.UID
+	generation int64
+	key        string
+}
+
+type limiter struct {
+	requests *bucket
+	tokens   *bucket
+	lastUsed time.Time
+}
+
+// admission is the result of the rate limits which a request is admitted by.
+// The tokens used by the request are charged to it once it is served.
+type admission struct {
+	charges []charge
+}
+
+type charge struct {
+	limit *ollamav1alpha1.ModelRateLimit
+	// label is the value of the key label of the metrics.
+	label string
+	*limiter
+}
+
+// admit checks the ModelRateLimits of model for r. If r exceeds a limit, it returns the duration to retry after.
+// The request is taken from the buckets only once all the limits admit it, so a rejected request is not charged.
+func (g *Gateway) admit(ctx context.Context, r *http.Request, model *ollamav1alpha1.Model) (*admission, time.Duration, error) {
+	limits := &ollamav1alpha1.ModelRateLimitList{}
+	if err := g.List(ctx, limits, client.InNamespace(model.Namespace)); err != nil {
+		return nil, 0, err
+	}
+	now := time.Now()
+	a := &admission{}
+	for i := range limits.Items {
+		limit := &limits.Items[i]
+		if limit.Spec.ModelSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(limit.Spec.ModelSelector)
+			if err != nil || !selector.Matches(labels.Set(model.Labels)) {
+				continue
+			}
+		}
+		key, label, err := g.rateLimitKey(ctx, r, model, limit.Spec.Key)
+		if err != nil {
+			return nil, 0, err
+		}
+		a.charges = append(a.charges, charge{limit: limit, label: label, limiter: g.limiter(limit, key, now)})
+	}
+	var retryAfter time.Duration
+	for _, c := range a.charges {
+		if wait := c.wait(now); wait > 0 {
+			rateLimitRequests.WithLabelValues(c.limit.Namespace, c.limit.Name, c.label, "limited").Inc()
+			retryAfter = max(retryAfter, wait)
+		}
+	}
+	if retryAfter > 0 {
+		return nil, retryAfter, nil
+	}
+	for _, c := range a.charges {
+		rateLimitRequests.WithLabelValues(c.limit.Namespace, c.limit.Name, c.label, "admitted").Inc()
+		if c.requests != nil {
+			c.requests.charge(now, 1)
+		}
+	}
+	return a, 0, nil
+}
+
+// charge records the tokens used by the admitted request.
+func (a *admission) charge(tokens int64) {
+	if a == nil || tokens == 0 {
+		return
+	}
+	now := time.Now()
+	for _, c := range a.charges {
+		rateLimitTokens.WithLabelValues(c.limit.Namespace, c.limit.Name, c.label).Add(float64(tokens))
+		if c.tokens != nil {
+			c.tokens.charge(now, float64(tokens))
+		}
+	}
+}
+
+func (g *Gateway) limiter(limit *ollamav1alpha1.ModelRateLimit, key string, now time.Time) *limiter {
+	g.limiters.mu.Lock()
+	defer g.limiters.mu.Unlock()
+	if g.limiters.buckets == nil {
+		g.limiters.buckets = map[limiterKey]*limiter{}
+	}
+	if now.Sub(g.limiters.lastPruned) > limiterIdleTimeout {
+		for k, l := range g.limiters.buckets {
+			if now.Sub(l.lastUsed) > limiterIdleTimeout {
+				delete(g.limiters.buckets, k)
+			}
+		}
+		g.limiters.lastPruned = now
+	}
+	k := limiterKey{uid: limit.UID, generation: limit.Generation, key: key}
+	if l, ok := g.limiters.buckets[k]; ok {
+		l.lastUsed = now
+		return l
+	}
+	l := &limiter{lastUsed: now}
+	if rps := limit.Spec.RequestsPerSecond; rps != nil {
+		burst := *rps
+		if limit.Spec.Burst != nil {
+			burst = *limit.Spec.Burst
+		}
+		l.requests = newBucket(float64(burst), float64(*rps), now)
+	}
+	if tpm := limit.Spec.TokensPerMinute; tpm != nil {
+		l.tokens = newBucket(float64(*tpm), float64(*tpm)/60, now)
+	}
+	g.limiters.buckets[k] = l
+	return l
+}
+
+// wait returns the duration to retry after if a bucket is exhausted. The token bucket has to hold at least one token,
+// as the tokens of the request are only known once it is served.
+func (l *limiter) wait(now time.Time) time.Duration {
+	var wait time.Duration
+	if l.tokens != nil {
+		wait = max(wait, l.tokens.wait(now, 1))
+	}
+	if l.requests != nil {
+		wait = max(wait, l.requests.wait(now, 1))
+	}
+	return wait
+}
+
+// rateLimitKey returns the key r is counted with, and the value of the key label of the metrics.
+// The API keys are counted only if they are verified against spec.auth.apiKeys of model, since any other
+// bearer token is chosen by the client. The other requests are counted per the namespace of the client pod,
+// or per the client address if it is not a pod. The addresses are not exposed in the metrics.
+func (g *Gateway) rateLimitKey(ctx context.Context, r *http.Request, model *ollamav1alpha1.Model, key ollamav1alpha1.RateLimitKey) (string, string, error) {
+	if key != ollamav1alpha1.RateLimitKeyNamespace {
+		name, err := g.verifyAPIKey(ctx, r, model)
+		if err != nil {
+			return "", "", err
+		}
+		if name != "" {
+			return "apikey/" + name, name, nil
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return unknownKey, unknownKey, nil
+	}
+	pods := &corev1.PodList{}
+	if err := g.List(ctx, pods, client.MatchingFields{PodIPField: host}); err != nil {
+		return "", "", err
+	}
+	for _, pod := range pods.Items {
+		if !pod.Spec.HostNetwork {
+			return "namespace/" + pod.Namespace, pod.Namespace, nil
+		}
+	}
+	return "address/" + host, unknownKey, nil
+}
+
+// verifyAPIKey returns the API key of spec.auth.apiKeys of model which r is authenticated with, in the form of
+// <Secret name>/<key of the Secret>. It returns an empty string if r has no such API key.
+func (g *Gateway) verifyAPIKey(ctx context.Context, r *http.Request, model *ollamav1alpha1.Model) (string, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" || model.Spec.Auth == nil {
+		return "", nil
+	}
+	sum := sha256.Sum256([]byte(token))
+	for _, apiKeys := range model.Spec.Auth.APIKeys {
+		secret := &corev1.Secret{}
+		if err := g.secretReader().Get(ctx, client.ObjectKey{Namespace: model.Namespace, Name: apiKeys.Name}, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+		for _, k := range slices.Sorted(maps.Keys(secret.Data)) {
+			key := strings.TrimSpace(string(secret.Data[k]))
+			if key == "" {
+				continue
+			}
+			if want := sha256.Sum256([]byte(key)); subtle.ConstantTimeCompare(sum[:], want[:]) == 1 {
+				return secret.Name + "/" + k, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func (g *Gateway) secretReader() client.Reader {
+	if g.APIReader != nil {
+		return g.APIReader
+	}
+	return g.Client
+}
+
+// bucket is a token bucket which is refilled at rate per second up to capacity.
+type bucket struct {
+	mu       sync.Mutex
+	capacity float64
+	rate     float64
+	tokens   float64
+	last     time.Time
+}
+
+func newBucket(capacity, rate float64, now time.Time) *bucket {
+	return &bucket{capacity: capacity, rate: rate, tokens: capacity, last: now}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(b.capacity, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// wait returns the duration until the bucket has n tokens.
+func (b *bucket) wait(now time.Time, n float64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now)
+	if b.tokens >= n {
+		return 0
+	}
+	return time.Duration((n - b.tokens) / b.rate * float64(time.Second))
+}
+
+// charge takes n tokens from the bucket. The bucket can go into debt, which delays the following requests.
+func (b *bucket) charge(now time.Time, n float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now)
+	b.tokens -= n
+}
+
+// retryAfterSeconds formats d for the Retry-After header.
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func newRateLimit(namespace, name string, spec ollamav1alpha1.ModelRateLimitSpec) *ollamav1alpha1.ModelRateLimit {
+	return &ollamav1alpha1.ModelRateLimit{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, UID: types.UID("uid-" + name)},
+		Spec:       spec,
+	}
+}
+
+func TestGatewayRateLimit(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"response":"hi","done":true,"prompt_eval_count":10,"eval_count":50}`)
+	}))
+	t.Cleanup(backend.Close)
+	backendURL, err := url.Parse(backend.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, portStr, err := net.SplitHostPort(backendURL.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serve := func(gw *Gateway, remoteAddr, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "http://gateway/api/generate", strings.NewReader(`{"model":"llama3"}`))
+		req.RemoteAddr = remoteAddr
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rec := httptest.NewRecorder()
+		gw.ServeHTTP(rec, req)
+		return rec
+	}
+
+	apiKeys := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "keys"},
+		Data:       map[string][]byte{"a": []byte("key-a\n"), "b": []byte("key-b")},
+	}
+	newAuthModel := func() *ollamav1alpha1.Model {
+		model := newModel("default", "llama", "llama3")
+		model.Spec.Auth = &ollamav1alpha1.ModelAuth{APIKeys: []ollamav1alpha1.ModelAPIKeySecret{{Name: "keys"}}}
+		return model
+	}
+
+	t.Run("Should limit the requests per second of an API key", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newAuthModel()
+		limit := newRateLimit("default", "rps", ollamav1alpha1.ModelRateLimitSpec{
+			Key:               ollamav1alpha1.RateLimitKeyAPIKey,
+			RequestsPerSecond: ptr.To[int32](1),
+		})
+		builder := fake.NewClientBuilder().WithScheme(newScheme(t)).
+			WithIndex(&corev1.Pod{}, PodIPField, IndexPodIP).
+			WithObjects(model, limit, apiKeys.DeepCopy(), newReadyPod(model, host))
+		apiReader := builder.Build()
+		// The Secrets are not cached by the gateway.
+		c := builder.WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if _, ok := obj.(*corev1.Secret); ok {
+					return apierrors.NewForbidden(corev1.Resource("secrets"), key.Name, errors.New("secrets are not cached"))
+				}
+				return c.Get(ctx, key, obj, opts...)
+			},
+		}).Build()
+		gw := &Gateway{Client: c, APIReader: apiReader, Port: port}
+
+		g.Expect(serve(gw, "10.0.0.1:1234", "key-a").Code).To(Equal(http.StatusOK))
+		rec := serve(gw, "10.0.0.1:1234", "key-a")
+		g.Expect(rec.Code).To(Equal(http.StatusTooManyRequests))
+		g.Expect(rec.Header().Get("Retry-After")).To(Equal("1"))
+		g.Expect(serve(gw, "10.0.0.1:1234", "key-b").Code).To(Equal(http.StatusOK))
+		// The API key is identified by its Secret in the metrics.
+		g.Expect(testutil.ToFloat64(rateLimitRequests.WithLabelValues("default", "rps", "keys/a", "limited"))).To(BeNumerically(">=", 1))
+	})
+
+	t.Run("Should count the unverified bearer tokens per the client", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newAuthModel()
+		limit := newRateLimit("default", "unverified", ollamav1alpha1.ModelRateLimitSpec{
+			Key:               ollamav1alpha1.RateLimitKeyAPIKey,
+			RequestsPerSecond: ptr.To[int32](1),
+		})
+		c := fake.NewClientBuilder().WithScheme(newScheme(t)).
+			WithIndex(&corev1.Pod{}, PodIPField, IndexPodIP).
+			WithObjects(model, limit, apiKeys.DeepCopy(), newReadyPod(model, host)).
+			Build()
+		gw := &Gateway{Client: c, Port: port}
+
+		g.Expect(serve(gw, "10.0.0.9:1234", "random-1").Code).To(Equal(http.StatusOK))
+		g.Expect(serve(gw, "10.0.0.9:1234", "random-2").Code).To(Equal(http.StatusTooManyRequests))
+		g.Expect(serve(gw, "10.0.0.9:1234", "").Code).To(Equal(http.StatusTooManyRequests))
+		g.Expect(serve(gw, "10.0.0.10:1234", "random-3").Code).To(Equal(http.StatusOK))
+		// The client addresses are not exposed in the metrics.
+		g.Expect(testutil.ToFloat64(rateLimitRequests.WithLabelValues("default", "unverified", unknownKey, "limited"))).To(Equal(2.0))
+	})
+
+	t.Run("Should not charge the limits which admit a request rejected by another limit", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel("default", "llama", "llama3")
+		loose := newRateLimit("default", "a-loose", ollamav1alpha1.ModelRateLimitSpec{
+			Key:               ollamav1alpha1.RateLimitKeyNamespace,
+			RequestsPerSecond: ptr.To[int32](2),
+		})
+		strict := newRateLimit("default", "b-strict", ollamav1alpha1.ModelRateLimitSpec{
+			Key:               ollamav1alpha1.RateLimitKeyNamespace,
+			RequestsPerSecond: ptr.To[int32](1),
+		})
+		c := fake.NewClientBuilder().WithScheme(newScheme(t)).
+			WithIndex(&corev1.Pod{}, PodIPField, IndexPodIP).
+			WithObjects(model, loose, strict, newReadyPod(model, host)).
+			Build()
+		gw := &Gateway{Client: c, Port: port}
+
+		g.Expect(serve(gw, "10.0.0.1:1234", "").Code).To(Equal(http.StatusOK))
+		g.Expect(serve(gw, "10.0.0.1:1234", "").Code).To(Equal(http.StatusTooManyRequests))
+		// The loose limit still holds the request which the strict limit rejected.
+		g.Expect(c.Delete(context.Background(), strict)).To(Succeed())
+		g.Expect(serve(gw, "10.0.0.1:1234", "").Code).To(Equal(http.StatusOK))
+	})
+
+	t.Run("Should limit the tokens per minute of a client namespace", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel("default", "llama", "llama3")
+		limit := newRateLimit("default", "tpm", ollamav1alpha1.ModelRateLimitSpec{
+			Key:             ollamav1alpha1.RateLimitKeyNamespace,
+			TokensPerMinute: ptr.To[int64](60),
+		})
+		clientA := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "client"},
+			Status:     corev1.PodStatus{PodIP: "10.0.0.1"},
+		}
+		clientB := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team-b", Name: "client"},
+			Status:     corev1.PodStatus{PodIP: "10.0.0.2"},
+		}
+		c := fake.NewClientBuilder().WithScheme(newScheme(t)).
+			WithIndex(&corev1.Pod{}, PodIPField, IndexPodIP).
+			WithObjects(model, limit, newReadyPod(model, host), clientA, clientB).
+			Build()
+		gw := &Gateway{Client: c, Port: port}
+
+		g.Expect(serve(gw, "10.0.0.1:1234", "").Code).To(Equal(http.StatusOK))
+		rec := serve(gw, "10.0.0.1:1234", "")
+		g.Expect(rec.Code).To(Equal(http.StatusTooManyRequests))
+		g.Expect(rec.Header().Get("Retry-After")).NotTo(BeEmpty())
+		g.Expect(serve(gw, "10.0.0.2:1234", "").Code).To(Equal(http.StatusOK))
+	})
+
+	t.Run("Should not limit the Models which are not selected", func(t *testing.T) {
+		g := NewWithT(t)
+		model := newModel("default", "llama", "llama3")
+		limit := newRateLimit("default", "rps", ollamav1alpha1.ModelRateLimitSpec{
+			ModelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "shared"}},
+			RequestsPerSecond: ptr.To[int32](1),
+		})
+		c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(model, limit, newReadyPod(model, host)).Build()
+		gw := &Gateway{Client: c, Port: port}
+
+		for range 3 {
+			g.Expect(serve(gw, "10.0.0.1:1234", "").Code).To(Equal(http.StatusOK))
+		}
+	})
+}
+
+func TestUsageRecorder(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		body string
+		want int64
+	}{
+		{
+			name: "Should count the tokens of the native streamed response",
+			body: "{\"response\":\"a\",\"done\":false}\n{\"response\":\"\",\"done\":true,\"prompt_eval_count\":3,\"eval_count\":4}\n",
+			want: 7,
+		},
+		{
+			name: "Should count the tokens of the OpenAI compatible streamed response",
+			body: "data: {\"choices\":[]}\n\ndata: {\"choices\":[],\"usage\":{\"prompt_tokens\":2,\"completion_tokens\":5,\"total_tokens\":7}}\n\ndata: [DONE]\n\n",
+			want: 7,
+		},
+		{
+			name: "Should count the tokens of the response without a trailing new line",
+			body: `{"object":"chat.completion","usage":{"total_tokens":12}}`,
+			want: 12,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			rec := &usageRecorder{ResponseWriter: httptest.NewRecorder()}
+			for _, chunk := range strings.SplitAfter(tt.body, "\n") {
+				_, err := rec.Write([]byte(chunk))
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			g.Expect(rec.Tokens()).To(Equal(tt.want))
+		})
+	}
+}
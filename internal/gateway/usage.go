@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// maxUsageLineSize is the maximum size of a response line which is inspected for the usage.
+const maxUsageLineSize = 1 << 20
+
+// usageRecorder is a http.ResponseWriter which counts the tokens reported in the responses of the ollama server.
+// The responses are either a JSON object, JSON lines of the native API, or server-sent events of the OpenAI compatible API.
+type usageRecorder struct {
+	http.ResponseWriter
+
+	line     []byte
+	overflow bool
+	tokens   int64
+}
+
+// usage is the usage reported by the ollama server. The native API reports the counts,
+// and the OpenAI compatible API reports the usage object.
+type usage struct {
+	PromptEvalCount int64 `json:"prompt_eval_count"`
+	EvalCount       int64 `json:"eval_count"`
+	Usage           *struct {
+		TotalTokens int64 `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+func (u *usageRecorder) Write(p []byte) (int, error) {
+	n, err := u.ResponseWriter.Write(p)
+	rest := p[:n]
+	for len(rest) > 0 {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			u.buffer(rest)
+			break
+		}
+		u.buffer(rest[:i])
+		u.count()
+		rest = rest[i+1:]
+	}
+	return n, err
+}
+
+func (u *usageRecorder) buffer(p []byte) {
+	if u.overflow || len(u.line)+len(p) > maxUsageLineSize {
+		u.overflow = true
+		return
+	}
+	u.line = append(u.line, p...)
+}
+
+func (u *usageRecorder) count() {
+	defer func() {
+		u.line = u.line[:0]
+		u.overflow = false
+	}()
+	if u.overflow {
+		return
+	}
+	line := bytes.TrimSpace(u.line)
+	line = bytes.TrimPrefix(line, []byte("data:"))
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '{' {
+		return
+	}
+	var res usage
+	if err := json.Unmarshal(line, &res); err != nil {
+		return
+	}
+	if res.Usage != nil {
+		u.tokens += res.Usage.TotalTokens
+		return
+	}
+	u.tokens += res.PromptEvalCount + res.EvalCount
+}
+
+// Tokens returns the tokens reported in the whole response.
+func (u *usageRecorder) Tokens() int64 {
+	if len(u.line) > 0 {
+		u.count()
+	}
+	return u.tokens
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to flush the streamed responses.
+func (u *usageRecorder) Unwrap() http.ResponseWriter {
+	return u.ResponseWriter
+}
+
+func (u *usageRecorder) Flush() {
+	if f, ok := u.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodecache
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+)
+
+// Agent reports the content of the cache of a node in the NodeCache of the node, and removes the models no Model uses.
+type Agent struct {
+	client.Client
+	// Dir is the directory of the cache.
+	Dir string
+	// NodeName is the name of the node the agent runs on, which is also the name of the NodeCache.
+	NodeName string
+	// GC enables the removal of the models which no Model uses and the blobs which no model references.
+	GC bool
+	// GCGracePeriod is the minimum age of the files removed by the garbage collection.
+	GCGracePeriod time.Duration
+	// Now returns the current time. If it is nil, time.Now is used.
+	Now func() time.Time
+}
+
+// Sync scans the cache, removes the unreferenced files if GC is enabled and updates the status of the NodeCache.
+func (a *Agent) Sync(ctx context.Context) error {
+	now := time.Now()
+	if a.Now != nil {
+		now = a.Now()
+	}
+	inv, err := Scan(a.Dir)
+	if err != nil {
+		return err
+	}
+	var reclaimed int64
+	if a.GC {
+		inUse, err := a.inUse(ctx)
+		if err != nil {
+			return err
+		}
+		reclaimed, err = GC(inv, func(name string) bool { return inUse[name] }, a.GCGracePeriod, now)
+		if err != nil {
+			return err
+		}
+		if reclaimed > 0 {
+			log.FromContext(ctx).Info("removed the unreferenced blobs", "size", resource.NewQuantity(reclaimed, resource.BinarySI).String())
+		}
+	}
+
+	cache, err := a.nodeCache(ctx)
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(cache.DeepCopy())
+	cache.Status = ollamav1alpha1.NodeCacheStatus{
+		Blobs:         int32(len(inv.Blobs)),
+		Size:          *resource.NewQuantity(inv.Size(), resource.BinarySI),
+		ReclaimedSize: *resource.NewQuantity(reclaimed, resource.BinarySI),
+		LastScanTime:  &metav1.Time{Time: now},
+	}
+	for _, model := range inv.Models {
+		cache.Status.Models = append(cache.Status.Models, ollamav1alpha1.NodeCacheModel{
+			Name: model.Name,
+			Size: *resource.NewQuantity(inv.ModelSize(model), resource.BinarySI),
+		})
+	}
+	slices.SortFunc(cache.Status.Models, func(a, b ollamav1alpha1.NodeCacheModel) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return a.Status().Patch(ctx, cache, patch)
+}
+
+// inUse returns the normalized names of the images of all Models.
+func (a *Agent) inUse(ctx context.Context) (map[string]bool, error) {
+	models := &ollamav1alpha1.ModelList{}
+	if err := a.List(ctx, models); err != nil {
+		return nil, err
+	}
+	inUse := map[string]bool{}
+	for _, model := range models.Items {
+		for _, image := range model.Spec.Images {
+			inUse[ollama.NormalizeName(image)] = true
+		}
+	}
+	return inUse, nil
+}
+
+// nodeCache returns the NodeCache of the node, which is created if it does not exist.
+// The NodeCache is owned by the Node, so it is deleted with the Node.
+func (a *Agent) nodeCache(ctx context.Context) (*ollamav1alpha1.NodeCache, error) {
+	cache := &ollamav1alpha1.NodeCache{}
+	err := a.Get(ctx, types.NamespacedName{Name: a.NodeName}, cache)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return cache, err
+	}
+	node := &corev1.Node{}
+	if err := a.Get(ctx, types.NamespacedName{Name: a.NodeName}, node); err != nil {
+		return nil, err
+	}
+	cache = &ollamav1alpha1.NodeCache{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: a.NodeName,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "v1",
+					Kind:       "Node",
+					Name:       node.Name,
+					UID:        node.UID,
+				},
+			},
+		},
+	}
+	if err := a.Create(ctx, cache); err != nil {
+		return nil, err
+	}
+	return cache, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodecache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func TestAgentSync(t *testing.T) {
+	t.Run("Should report the cached models and remove the unused ones", func(t *testing.T) {
+		g := NewWithT(t)
+		scheme := runtime.NewScheme()
+		g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		g.Expect(ollamav1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		now := time.Now()
+		dir := t.TempDir()
+		writeModel(t, dir, "registry.ollama.ai/library/llama3/8b", map[string]int{"sha256:a": 10}, now.Add(-2*time.Hour))
+		writeModel(t, dir, "registry.ollama.ai/library/mistral/latest", map[string]int{"sha256:b": 20}, now.Add(-2*time.Hour))
+
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", UID: "uid-node-1"}}
+		model := &ollamav1alpha1.Model{
+			ObjectMeta: metav1.ObjectMeta{Name: "llama3", Namespace: "default"},
+			Spec:       ollamav1alpha1.ModelSpec{Images: []string{"registry.ollama.ai/library/llama3:8b"}},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(node, model).
+			WithStatusSubresource(&ollamav1alpha1.NodeCache{}).Build()
+		agent := &Agent{
+			Client:        c,
+			Dir:           dir,
+			NodeName:      "node-1",
+			GC:            true,
+			GCGracePeriod: time.Hour,
+			Now:           func() time.Time { return now },
+		}
+		g.Expect(agent.Sync(context.Background())).To(Succeed())
+
+		cache := &ollamav1alpha1.NodeCache{}
+		g.Expect(c.Get(context.Background(), types.NamespacedName{Name: "node-1"}, cache)).To(Succeed())
+		g.Expect(cache.OwnerReferences).To(HaveLen(1))
+		g.Expect(cache.OwnerReferences[0].UID).To(Equal(node.UID))
+		g.Expect(cache.Status.Models).To(HaveLen(1))
+		g.Expect(cache.Status.Models[0].Name).To(Equal("llama3:8b"))
+		g.Expect(cache.Status.Models[0].Size.Value()).To(Equal(int64(10)))
+		g.Expect(cache.Status.Blobs).To(Equal(int32(1)))
+		g.Expect(cache.Status.Size.Value()).To(Equal(int64(10)))
+		g.Expect(cache.Status.ReclaimedSize.Value()).To(Equal(int64(20)))
+		g.Expect(cache.Status.LastScanTime).NotTo(BeNil())
+
+		// The next sync updates the existing NodeCache.
+		agent.GC = false
+		g.Expect(agent.Sync(context.Background())).To(Succeed())
+		g.Expect(c.Get(context.Background(), types.NamespacedName{Name: "node-1"}, cache)).To(Succeed())
+		g.Expect(cache.Status.Models).To(HaveLen(1))
+		g.Expect(cache.Status.ReclaimedSize.IsZero()).To(BeTrue())
+	})
+}
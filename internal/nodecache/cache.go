@@ -0,0 +1,217 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nodecache manages the cache of the models shared by the pods on a node.
+// The cache has the layout of the models directory of the ollama server, so the pods mount it as is.
+package nodecache
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sivchari/ollama-operator/internal/ollama"
+)
+
+// Model is a model whose manifest is in the cache.
+type Model struct {
+	// Name is the normalized name of the model.
+	Name string
+	// Digests are the digests of the config and the layers of the model.
+	Digests []string
+
+	path    string
+	modTime time.Time
+}
+
+// Blob is a file in the blobs directory of the cache.
+type Blob struct {
+	Digest string
+	Size   int64
+
+	path    string
+	modTime time.Time
+}
+
+// Inventory is the content of the cache.
+type Inventory struct {
+	Models []Model
+	// Blobs are the blobs by the digest.
+	Blobs map[string]Blob
+}
+
+// Size returns the total size of the blobs.
+func (inv *Inventory) Size() int64 {
+	var size int64
+	for _, blob := range inv.Blobs {
+		size += blob.Size
+	}
+	return size
+}
+
+// ModelSize returns the total size of the blobs of model held in the cache.
+func (inv *Inventory) ModelSize(model Model) int64 {
+	var size int64
+	for _, digest := range model.Digests {
+		size += inv.Blobs[digest].Size
+	}
+	return size
+}
+
+// manifest is the part of the manifest of a model which refers to the blobs.
+type manifest struct {
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Layers []struct {
+		Digest string `json:"digest"`
+	} `json:"layers"`
+}
+
+// Scan returns the content of the cache in dir.
+// The manifests which cannot be parsed, e.g. because they are being written, are ignored.
+func Scan(dir string) (*Inventory, error) {
+	inv := &Inventory{Blobs: map[string]Blob{}}
+	manifests := filepath.Join(dir, "manifests")
+	err := filepath.WalkDir(manifests, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		model, ok, err := readManifest(manifests, path)
+		if err != nil || !ok {
+			return err
+		}
+		inv.Models = append(inv.Models, model)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "blobs"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		// The blobs are named after the digest, e.g. "sha256-<hex>" for "sha256:<hex>".
+		// The partial downloads have a suffix, so they are not referenced by any manifest.
+		digest := strings.Replace(entry.Name(), "-", ":", 1)
+		inv.Blobs[digest] = Blob{
+			Digest:  digest,
+			Size:    info.Size(),
+			path:    filepath.Join(dir, "blobs", entry.Name()),
+			modTime: info.ModTime(),
+		}
+	}
+	return inv, nil
+}
+
+// readManifest reads the manifest at path, which is <registry>/<namespace>/<model>/<tag> under the manifests directory.
+func readManifest(manifests, path string) (Model, bool, error) {
+	rel, err := filepath.Rel(manifests, path)
+	if err != nil {
+		return Model{}, false, err
+	}
+	repository, tag := filepath.Split(filepath.ToSlash(rel))
+	if repository == "" {
+		return Model{}, false, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return Model{}, false, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Model{}, false, err
+	}
+	var m manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return Model{}, false, nil
+	}
+	model := Model{
+		Name:    ollama.NormalizeName(strings.TrimSuffix(repository, "/") + ":" + tag),
+		path:    path,
+		modTime: info.ModTime(),
+	}
+	if m.Config.Digest != "" {
+		model.Digests = append(model.Digests, m.Config.Digest)
+	}
+	for _, layer := range m.Layers {
+		model.Digests = append(model.Digests, layer.Digest)
+	}
+	return model, true, nil
+}
+
+// GC removes the models which keep returns false for and the blobs which are not referenced by the remaining models,
+// and returns the total size of the removed blobs. The removed files are dropped from inv.
+// The files modified within grace are kept, since they may be written by a pull in progress.
+func GC(inv *Inventory, keep func(name string) bool, grace time.Duration, now time.Time) (int64, error) {
+	referenced := map[string]bool{}
+	models := inv.Models[:0]
+	for _, model := range inv.Models {
+		if !keep(model.Name) && now.Sub(model.modTime) >= grace {
+			if err := remove(model.path); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		for _, digest := range model.Digests {
+			referenced[digest] = true
+		}
+		models = append(models, model)
+	}
+	inv.Models = models
+
+	var reclaimed int64
+	for digest, blob := range inv.Blobs {
+		if referenced[digest] || now.Sub(blob.modTime) < grace {
+			continue
+		}
+		if err := remove(blob.path); err != nil {
+			return reclaimed, err
+		}
+		delete(inv.Blobs, digest)
+		reclaimed += blob.Size
+	}
+	return reclaimed, nil
+}
+
+func remove(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodecache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+// writeModel writes the manifest and the blobs of a model in dir, and sets their modification time to modTime.
+func writeModel(t *testing.T, dir, path string, blobs map[string]int, modTime time.Time) {
+	t.Helper()
+	g := NewWithT(t)
+	var m manifest
+	for digest, size := range blobs {
+		if m.Config.Digest == "" {
+			m.Config.Digest = digest
+		} else {
+			m.Layers = append(m.Layers, struct {
+				Digest string `json:"digest"`
+			}{digest})
+		}
+		writeFile(t, filepath.Join(dir, "blobs", "sha256-"+digest[len("sha256:"):]), make([]byte, size), modTime)
+	}
+	b, err := json.Marshal(m)
+	g.Expect(err).NotTo(HaveOccurred())
+	writeFile(t, filepath.Join(dir, "manifests", path), b, modTime)
+}
+
+func writeFile(t *testing.T, path string, b []byte, modTime time.Time) {
+	t.Helper()
+	g := NewWithT(t)
+	g.Expect(os.MkdirAll(filepath.Dir(path), 0o755)).To(Succeed())
+	g.Expect(os.WriteFile(path, b, 0o644)).To(Succeed())
+	g.Expect(os.Chtimes(path, modTime, modTime)).To(Succeed())
+}
+
+func TestScan(t *testing.T) {
+	t.Run("Should return an empty inventory if the cache does not exist", func(t *testing.T) {
+		g := NewWithT(t)
+		inv, err := Scan(filepath.Join(t.TempDir(), "missing"))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(inv.Models).To(BeEmpty())
+		g.Expect(inv.Blobs).To(BeEmpty())
+	})
+
+	t.Run("Should return the models and the blobs", func(t *testing.T) {
+		g := NewWithT(t)
+		dir := t.TempDir()
+		now := time.Now()
+		writeModel(t, dir, "registry.ollama.ai/library/llama3/8b", map[string]int{"sha256:a": 10, "sha256:b": 20}, now)
+		writeModel(t, dir, "hf.co/bartowski/gemma/Q4_K_M", map[string]int{"sha256:b": 20, "sha256:c": 5}, now)
+		writeFile(t, filepath.Join(dir, "manifests", "registry.ollama.ai", "library", "broken", "latest"), []byte("{"), now)
+		writeFile(t, filepath.Join(dir, "blobs", "sha256-d-partial"), make([]byte, 7), now)
+
+		inv, err := Scan(dir)
+		g.Expect(err).NotTo(HaveOccurred())
+		names := map[string]int64{}
+		for _, model := range inv.Models {
+			names[model.Name] = inv.ModelSize(model)
+		}
+		g.Expect(names).To(Equal(map[string]int64{"llama3:8b": 30, "hf.co/bartowski/gemma:Q4_K_M": 25}))
+		g.Expect(inv.Blobs).To(HaveLen(4))
+		g.Expect(inv.Blobs).To(HaveKey("sha256:d-partial"))
+		g.Expect(inv.Size()).To(Equal(int64(42)))
+	})
+}
+
+func TestGC(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-2 * time.Hour)
+
+	t.Run("Should remove the unused models and the unreferenced blobs", func(t *testing.T) {
+		g := NewWithT(t)
+		dir := t.TempDir()
+		writeModel(t, dir, "registry.ollama.ai/library/llama3/8b", map[string]int{"sha256:a": 10, "sha256:b": 20}, old)
+		writeModel(t, dir, "registry.ollama.ai/library/mistral/latest", map[string]int{"sha256:b": 20, "sha256:c": 5}, old)
+		writeFile(t, filepath.Join(dir, "blobs", "sha256-d-partial"), make([]byte, 7), old)
+
+		inv, err := Scan(dir)
+		g.Expect(err).NotTo(HaveOccurred())
+		reclaimed, err := GC(inv, func(name string) bool { return name == "llama3:8b" }, time.Hour, now)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(reclaimed).To(Equal(int64(12)))
+		g.Expect(inv.Models).To(HaveLen(1))
+		g.Expect(inv.Models[0].Name).To(Equal("llama3:8b"))
+		g.Expect(inv.Blobs).To(HaveLen(2))
+
+		inv, err = Scan(dir)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(inv.Models).To(HaveLen(1))
+		g.Expect(inv.Blobs).To(HaveLen(2))
+		g.Expect(inv.Blobs).To(HaveKey("sha256:a"))
+		g.Expect(inv.Blobs).To(HaveKey("sha256:b"))
+	})
+
+	t.Run("Should keep the files written within the grace period", func(t *testing.T) {
+		g := NewWithT(t)
+		dir := t.TempDir()
+		writeModel(t, dir, "registry.ollama.ai/library/mistral/latest", map[string]int{"sha256:c": 5}, now)
+		writeFile(t, filepath.Join(dir, "blobs", "sha256-d-partial"), make([]byte, 7), now)
+
+		inv, err := Scan(dir)
+		g.Expect(err).NotTo(HaveOccurred())
+		reclaimed, err := GC(inv, func(string) bool { return false }, time.Hour, now)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(reclaimed).To(BeZero())
+		g.Expect(inv.Models).To(HaveLen(1))
+		g.Expect(inv.Blobs).To(HaveLen(2))
+	})
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ollama provides a minimal client for the Ollama API.
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultPort is the port the ollama server listens on.
+const DefaultPort = 11434
+
+// Client is a client for the Ollama API.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient returns a Client for the ollama server served at baseURL.
+// If httpClient is nil, a client with a short timeout is used.
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 5 * time.Second}
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// VersionResponse is the response of GET /api/version.
+type VersionResponse struct {
+	Version string `json:"version"`
+}
+
+// Version returns the version of the ollama server.
+func (c *Client) Version(ctx context.Context) (string, error) {
+	var res VersionResponse
+	if err := c.get(ctx, "/api/version", &res); err != nil {
+		return "", err
+	}
+	return res.Version, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %s", path, res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// ProcessResponse is the response of GET /api/ps.
+type ProcessResponse struct {
+	Models []ProcessModelResponse `json:"models"`
+}
+
+// ProcessModelResponse is a model which is loaded in memory.
+type ProcessModelResponse struct {
+	Name      string    `json:"name"`
+	Model     string    `json:"model"`
+	Size      int64     `json:"size"`
+	Digest    string    `json:"digest"`
+	ExpiresAt time.Time `json:"expires_at"`
+	SizeVRAM  int64     `json:"size_vram"`
+}
+
+// ListRunning returns the models which are loaded in memory.
+func (c *Client) ListRunning(ctx context.Context) ([]ProcessModelResponse, error) {
+	var res ProcessResponse
+	if err := c.get(ctx, "/api/ps", &res); err != nil {
+		return nil, err
+	}
+	return res.Models, nil
+}
+
+// ListResponse is the response of GET /api/tags.
+type ListResponse struct {
+	Models []ListModelResponse `json:"models"`
+}
+
+// ListModelResponse is a model which is pulled to the ollama server.
+type ListModelResponse struct {
+	Name       string    `json:"name"`
+	Model      string    `json:"model"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Size       int64     `json:"size"`
+	Digest     string    `json:"digest"`
+}
+
+// List returns the models which are pulled to the ollama server.
+func (c *Client) List(ctx context.Context) ([]ListModelResponse, error) {
+	var res ListResponse
+	if err := c.get(ctx, "/api/tags", &res); err != nil {
+		return nil, err
+	}
+	return res.Models, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ollama
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestClientVersion(t *testing.T) {
+	t.Run("Should return the server version", func(t *testing.T) {
+		g := NewWithT(t)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/version" {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(`{"version":"0.6.2"}`))
+		}))
+		t.Cleanup(srv.Close)
+
+		version, err := NewClient(srv.URL, nil).Version(context.Background())
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(version).To(Equal("0.6.2"))
+	})
+
+	t.Run("Should fail when the server returns an error", func(t *testing.T) {
+		g := NewWithT(t)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(srv.Close)
+
+		_, err := NewClient(srv.URL, nil).Version(context.Background())
+		g.Expect(err).To(HaveOccurred())
+	})
+}
+
+func TestClientList(t *testing.T) {
+	t.Run("Should return the pulled models", func(t *testing.T) {
+		g := NewWithT(t)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/tags" {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(`{"models":[{"name":"llama3:latest","model":"llama3:latest","size":4661224676,"digest":"365c0bd3c000"}]}`))
+		}))
+		t.Cleanup(srv.Close)
+
+		models, err := NewClient(srv.URL, nil).List(context.Background())
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(models).To(HaveLen(1))
+		g.Expect(models[0].Name).To(Equal("llama3:latest"))
+		g.Expect(models[0].Size).To(Equal(int64(4661224676)))
+	})
+}
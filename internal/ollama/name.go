@@ -0,0 +1,127 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ollama
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultRegistry is the registry the ollama server pulls the models from by default.
+	DefaultRegistry  = "registry.ollama.ai"
+	defaultNamespace = "library"
+	defaultTag       = "latest"
+)
+
+var (
+	// namePart is the form of each part of a model name, which is used as a path element of its manifest.
+	namePart = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	// hostPart is the form of the registry host of a model name, which may have a port.
+	hostPart = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*(:[0-9]+)?$`)
+)
+
+// NormalizeName returns the canonical form of the model name, so the names which refer to the same model are equal.
+// The default registry and namespace are trimmed and the default tag is added, e.g. "llama3" is "llama3:latest"
+// and "registry.ollama.ai/library/llama3:8b" is "llama3:8b".
+func NormalizeName(name string) string {
+	name = strings.TrimPrefix(name, DefaultRegistry+"/")
+	name = strings.TrimPrefix(name, defaultNamespace+"/")
+	if !strings.Contains(name[strings.LastIndex(name, "/")+1:], ":") {
+		name += ":" + defaultTag
+	}
+	return name
+}
+
+// Name is a model name split into the parts which locate the model in a registry.
+type Name struct {
+	Host      string
+	Namespace string
+	Model     string
+	Tag       string
+}
+
+// ParseName splits name into its parts. The missing parts are the defaults, e.g. "llama3" is
+// "registry.ollama.ai/library/llama3:latest". It returns an error if a part is invalid.
+func ParseName(name string) (Name, error) {
+	n := Name{Host: DefaultRegistry, Namespace: defaultNamespace, Tag: defaultTag}
+	s := name
+	if i := strings.LastIndex(s, ":"); i > strings.LastIndex(s, "/") {
+		n.Tag = s[i+1:]
+		s = s[:i]
+	}
+	parts := strings.Split(s, "/")
+	switch len(parts) {
+	case 1:
+		n.Model = parts[0]
+	case 2:
+		n.Namespace, n.Model = parts[0], parts[1]
+	default:
+		n.Host = parts[0]
+		n.Namespace = strings.Join(parts[1:len(parts)-1], "/")
+		n.Model = parts[len(parts)-1]
+	}
+	if err := n.Validate(); err != nil {
+		return Name{}, fmt.Errorf("invalid model name %q: %w", name, err)
+	}
+	return n, nil
+}
+
+// Validate returns an error if a part of n is empty, contains a character other than the letters, digits,
+// '.', '_' and '-', starts with a character other than a letter or a digit, or contains "..".
+// The namespace may consist of the parts separated by '/', and the host may have a port.
+func (n Name) Validate() error {
+	if !validPart(hostPart, n.Host) {
+		return fmt.Errorf("invalid host %q", n.Host)
+	}
+	for part := range strings.SplitSeq(n.Namespace, "/") {
+		if !validPart(namePart, part) {
+			return fmt.Errorf("invalid namespace %q", n.Namespace)
+		}
+	}
+	if !validPart(namePart, n.Model) {
+		return fmt.Errorf("invalid model %q", n.Model)
+	}
+	if !validPart(namePart, n.Tag) {
+		return fmt.Errorf("invalid tag %q", n.Tag)
+	}
+	return nil
+}
+
+func validPart(form *regexp.Regexp, part string) bool {
+	return form.MatchString(part) && !strings.Contains(part, "..")
+}
+
+// Repository returns the repository of the model in the registry, e.g. "library/llama3".
+func (n Name) Repository() string {
+	return n.Namespace + "/" + n.Model
+}
+
+// ManifestPath returns the path of the manifest of the model in the models directory of the ollama server,
+// relative to its manifests directory. It returns an error if n is invalid, so the path never escapes the directory.
+func (n Name) ManifestPath() (string, error) {
+	if err := n.Validate(); err != nil {
+		return "", err
+	}
+	return path.Join(n.Host, n.Namespace, n.Model, n.Tag), nil
+}
+
+func (n Name) String() string {
+	return n.Host + "/" + n.Repository() + ":" + n.Tag
+}
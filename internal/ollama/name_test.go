@@ -0,0 +1,101 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ollama
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestNormalizeName(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want string
+	}{
+		{name: "llama3", want: "llama3:latest"},
+		{name: "llama3:8b", want: "llama3:8b"},
+		{name: "library/llama3", want: "llama3:latest"},
+		{name: "registry.ollama.ai/library/llama3:8b", want: "llama3:8b"},
+		{name: "example.com:5000/team/llama3", want: "example.com:5000/team/llama3:latest"},
+	} {
+		t.Run("Should normalize "+tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(NormalizeName(tt.name)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestParseName(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want Name
+	}{
+		{name: "llama3", want: Name{Host: "registry.ollama.ai", Namespace: "library", Model: "llama3", Tag: "latest"}},
+		{name: "llama3:8b", want: Name{Host: "registry.ollama.ai", Namespace: "library", Model: "llama3", Tag: "8b"}},
+		{name: "team/llama3", want: Name{Host: "registry.ollama.ai", Namespace: "team", Model: "llama3", Tag: "latest"}},
+		{name: "hf.co/bartowski/gemma:Q4_K_M", want: Name{Host: "hf.co", Namespace: "bartowski", Model: "gemma", Tag: "Q4_K_M"}},
+		{name: "example.com:5000/team/llama3", want: Name{Host: "example.com:5000", Namespace: "team", Model: "llama3", Tag: "latest"}},
+	} {
+		t.Run("Should parse "+tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			got, err := ParseName(tt.name)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+			g.Expect(NormalizeName(got.String())).To(Equal(NormalizeName(tt.name)))
+		})
+	}
+}
+
+func TestParseNameRejectsInvalidNames(t *testing.T) {
+	for _, name := range []string{
+		"",
+		"../../../etc/x",
+		"llama3:../../x",
+		"team/../llama3",
+		"example.com/../../llama3",
+		"../registry.ollama.ai/library/llama3",
+		"team/.hidden",
+		"team/llama..3",
+		"team//llama3",
+		"llama3:",
+		"llama3;rm -rf /",
+		"$(id)/llama3",
+		"example.com:port/team/llama3",
+	} {
+		t.Run("Should reject "+name, func(t *testing.T) {
+			g := NewWithT(t)
+			_, err := ParseName(name)
+			g.Expect(err).To(HaveOccurred())
+		})
+	}
+}
+
+func TestManifestPath(t *testing.T) {
+	t.Run("Should return the path of the manifest", func(t *testing.T) {
+		g := NewWithT(t)
+		got, err := Name{Host: "example.com:5000", Namespace: "team/sub", Model: "llama3", Tag: "8b"}.ManifestPath()
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(got).To(Equal("example.com:5000/team/sub/llama3/8b"))
+	})
+
+	t.Run("Should reject the name escaping the manifests directory", func(t *testing.T) {
+		g := NewWithT(t)
+		_, err := Name{Host: DefaultRegistry, Namespace: "..", Model: "..", Tag: "x"}.ManifestPath()
+		g.Expect(err).To(HaveOccurred())
+	})
+}
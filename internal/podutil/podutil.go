@@ -0,0 +1,36 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package podutil implements the helpers of the pods shared by the controllers, the activator and the gateway.
+package podutil
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// IsReady reports whether pod is ready to serve the requests, that is it has an IP, is not being deleted
+// and its Ready condition is true.
+func IsReady(pod *corev1.Pod) bool {
+	if pod.Status.PodIP == "" || !pod.DeletionTimestamp.IsZero() {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
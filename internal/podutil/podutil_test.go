@@ -0,0 +1,61 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podutil
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestIsReady(t *testing.T) {
+	ready := func() *corev1.Pod {
+		return &corev1.Pod{
+			Status: corev1.PodStatus{
+				PodIP:      "10.0.0.1",
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+	for _, tt := range []struct {
+		name   string
+		mutate func(pod *corev1.Pod)
+		want   bool
+	}{
+		{name: "Should report the ready pod", mutate: func(*corev1.Pod) {}, want: true},
+		{name: "Should not report the pod without an IP", mutate: func(pod *corev1.Pod) { pod.Status.PodIP = "" }},
+		{name: "Should not report the pod being deleted", mutate: func(pod *corev1.Pod) {
+			pod.DeletionTimestamp = ptr.To(metav1.Now())
+		}},
+		{name: "Should not report the pod which is not ready", mutate: func(pod *corev1.Pod) {
+			pod.Status.Conditions[0].Status = corev1.ConditionFalse
+		}},
+		{name: "Should not report the pod without the Ready condition", mutate: func(pod *corev1.Pod) {
+			pod.Status.Conditions = nil
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			pod := ready()
+			tt.mutate(pod)
+			g.Expect(IsReady(pod)).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// publicPaths are the endpoints which are served without authentication.
+// They are used by the operator to check the runtime, the activity and the pulled models of the ollama server.
+var publicPaths = []string{"/", "/api/version", "/api/ps", "/api/tags"}
+
+// adminPathPrefixes are the management endpoints which are served only for the admin callers.
+var adminPathPrefixes = []string{"/api/pull", "/api/push", "/api/create", "/api/copy", "/api/delete", "/api/blobs/"}
+
+var errUnauthenticated = errors.New("unauthenticated")
+
+// Authenticator authenticates the requests with the API keys or the JWTs sent as the bearer token.
+type Authenticator struct {
+	// keys maps the SHA-256 of the API keys to whether they are admin.
+	keys atomic.Pointer[map[[sha256.Size]byte]bool]
+
+	verifier   *oidc.IDTokenVerifier
+	audiences  []string
+	adminClaim string
+	adminValue string
+}
+
+// JWTConfig is the configuration to authenticate the JWTs.
+type JWTConfig struct {
+	// Issuer is the URL of the OpenID Connect issuer.
+	Issuer string
+	// Audiences are the accepted audiences. At least one is required.
+	Audiences []string
+	// AdminClaim and AdminValue are the claim and its value which grant the management endpoints.
+	AdminClaim string
+	AdminValue string
+}
+
+// NewAuthenticator returns an Authenticator. If jwt is not nil, the signing keys are discovered from the issuer,
+// and the JWTs are accepted only for the audiences of jwt.
+func NewAuthenticator(ctx context.Context, jwt *JWTConfig) (*Authenticator, error) {
+	a := &Authenticator{}
+	a.keys.Store(&map[[sha256.Size]byte]bool{})
+	if jwt == nil {
+		return a, nil
+	}
+	if len(jwt.Audiences) == 0 {
+		return nil, errors.New("the audiences of the JWTs are required")
+	}
+	provider, err := oidc.NewProvider(ctx, jwt.Issuer)
+	if err != nil {
+		return nil, err
+	}
+	a.verifier = provider.Verifier(&oidc.Config{SkipClientIDCheck: true})
+	a.audiences = jwt.Audiences
+	a.adminClaim = jwt.AdminClaim
+	a.adminValue = jwt.AdminValue
+	return a, nil
+}
+
+// LoadAPIKeys replaces the API keys with the files in the directories, which are the mounted Secrets.
+// Each file in a sub directory of keysDir is an API key, and so is the one of adminKeysDir which is also admin.
+func (a *Authenticator) LoadAPIKeys(keysDir, adminKeysDir string) error {
+	keys := map[[sha256.Size]byte]bool{}
+	for _, dir := range []struct {
+		path  string
+		admin bool
+	}{{keysDir, false}, {adminKeysDir, true}} {
+		if dir.path == "" {
+			continue
+		}
+		// The files of a mounted Secret are the symlinks at the top of its directory.
+		files, err := filepath.Glob(filepath.Join(dir.path, "*", "*"))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if strings.HasPrefix(filepath.Base(file), "..") {
+				continue
+			}
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			key := strings.TrimSpace(string(b))
+			if key == "" {
+				continue
+			}
+			sum := sha256.Sum256([]byte(key))
+			keys[sum] = keys[sum] || dir.admin
+		}
+	}
+	a.keys.Store(&keys)
+	return nil
+}
+
+// Wrap returns a http.Handler which serves the authenticated requests with next.
+func (a *Authenticator) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(publicPaths, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		admin, err := a.authenticate(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if !admin && isAdminPath(r.URL.Path) {
+			http.Error(w, "the endpoint is only allowed for the admin callers", http.StatusForbidden)
+			return
+		}
+		// The credential is not forwarded to the ollama server.
+		r.Header.Del("Authorization")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate returns whether the caller of r is admin.
+func (a *Authenticator) authenticate(r *http.Request) (bool, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return false, errUnauthenticated
+	}
+	if admin, ok := (*a.keys.Load())[sha256.Sum256([]byte(token))]; ok {
+		return admin, nil
+	}
+	if a.verifier == nil {
+		return false, errUnauthenticated
+	}
+	idToken, err := a.verifier.Verify(r.Context(), token)
+	if err != nil {
+		return false, errUnauthenticated
+	}
+	if !slices.ContainsFunc(idToken.Audience, func(aud string) bool { return slices.Contains(a.audiences, aud) }) {
+		return false, errUnauthenticated
+	}
+	if a.adminClaim == "" {
+		return false, nil
+	}
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return false, errUnauthenticated
+	}
+	return hasClaim(claims[a.adminClaim], a.adminValue), nil
+}
+
+func hasClaim(claim any, value string) bool {
+	switch c := claim.(type) {
+	case string:
+		return c == value
+	case []any:
+		return slices.Contains(c, any(value))
+	}
+	return false
+}
+
+func isAdminPath(path string) bool {
+	return slices.ContainsFunc(adminPathPrefixes, func(prefix string) bool { return strings.HasPrefix(path, prefix) })
+}
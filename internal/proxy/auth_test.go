@@ -0,0 +1,198 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func writeSecret(t *testing.T, dir string, data map[string]string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range data {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAuthenticator(t *testing.T) {
+	root := t.TempDir()
+	writeSecret(t, filepath.Join(root, "api-keys", "team-a"), map[string]string{"key": "user-key\n"})
+	writeSecret(t, filepath.Join(root, "admin-keys", "ops"), map[string]string{"key": "admin-key"})
+
+	a, err := NewAuthenticator(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.LoadAPIKeys(filepath.Join(root, "api-keys"), filepath.Join(root, "admin-keys")); err != nil {
+		t.Fatal(err)
+	}
+	handler := a.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}))
+	serve := func(method, path, key string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("Should serve the requests with a valid API key", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(serve(http.MethodPost, "/api/chat", "user-key")).To(Equal(http.StatusOK))
+		g.Expect(serve(http.MethodPost, "/v1/chat/completions", "admin-key")).To(Equal(http.StatusOK))
+	})
+
+	t.Run("Should reject the requests without a valid API key", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(serve(http.MethodPost, "/api/chat", "")).To(Equal(http.StatusUnauthorized))
+		g.Expect(serve(http.MethodPost, "/api/chat", "unknown")).To(Equal(http.StatusUnauthorized))
+	})
+
+	t.Run("Should allow the management endpoints only for the admin keys", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(serve(http.MethodPost, "/api/pull", "user-key")).To(Equal(http.StatusForbidden))
+		g.Expect(serve(http.MethodDelete, "/api/delete", "user-key")).To(Equal(http.StatusForbidden))
+		g.Expect(serve(http.MethodPost, "/api/pull", "admin-key")).To(Equal(http.StatusOK))
+	})
+
+	t.Run("Should serve the endpoints used by the operator without authentication", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(serve(http.MethodGet, "/api/version", "")).To(Equal(http.StatusOK))
+		g.Expect(serve(http.MethodGet, "/api/ps", "")).To(Equal(http.StatusOK))
+		g.Expect(serve(http.MethodGet, "/api/tags", "")).To(Equal(http.StatusOK))
+	})
+}
+
+// newIssuer starts an OpenID Connect issuer and returns its URL and a function signing the JWTs with claims.
+func newIssuer(t *testing.T) (string, func(claims map[string]any) string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                srv.URL,
+			"jwks_uri":                              srv.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": "key",
+			"n":   encode(key.N.Bytes()),
+			"e":   encode(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	sign := func(claims map[string]any) string {
+		claims["iss"] = srv.URL
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": "key"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload, err := json.Marshal(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed := encode(header) + "." + encode(payload)
+		sum := sha256.Sum256([]byte(signed))
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed + "." + encode(sig)
+	}
+	return srv.URL, sign
+}
+
+func TestAuthenticatorJWT(t *testing.T) {
+	ctx := context.Background()
+	issuer, sign := newIssuer(t)
+	a, err := NewAuthenticator(ctx, &JWTConfig{Issuer: issuer, Audiences: []string{"ollama"}, AdminClaim: "groups", AdminValue: "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := a.Wrap(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	serve := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("Should serve the requests with a JWT for the audiences", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(serve("/api/chat", sign(map[string]any{"aud": "ollama"}))).To(Equal(http.StatusOK))
+		g.Expect(serve("/api/chat", sign(map[string]any{"aud": []string{"other", "ollama"}}))).To(Equal(http.StatusOK))
+		g.Expect(serve("/api/pull", sign(map[string]any{"aud": "ollama"}))).To(Equal(http.StatusForbidden))
+		g.Expect(serve("/api/pull", sign(map[string]any{"aud": "ollama", "groups": []string{"admin"}}))).To(Equal(http.StatusOK))
+	})
+
+	t.Run("Should reject the JWTs for the other audiences", func(t *testing.T) {
+		g := NewWithT(t)
+		g.Expect(serve("/api/chat", sign(map[string]any{"aud": "other"}))).To(Equal(http.StatusUnauthorized))
+		g.Expect(serve("/api/chat", sign(map[string]any{}))).To(Equal(http.StatusUnauthorized))
+		g.Expect(serve("/api/pull", sign(map[string]any{"aud": "other", "groups": []string{"admin"}}))).To(Equal(http.StatusUnauthorized))
+	})
+
+	t.Run("Should require the audiences", func(t *testing.T) {
+		g := NewWithT(t)
+		_, err := NewAuthenticator(ctx, &JWTConfig{Issuer: issuer})
+		g.Expect(err).To(HaveOccurred())
+	})
+}
+
+func TestHasClaim(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(hasClaim("admin", "admin")).To(BeTrue())
+	g.Expect(hasClaim([]any{"users", "admin"}, "admin")).To(BeTrue())
+	g.Expect(hasClaim([]any{"users"}, "admin")).To(BeFalse())
+	g.Expect(hasClaim(nil, "admin")).To(BeFalse())
+}
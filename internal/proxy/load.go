@@ -0,0 +1,69 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+)
+
+// MetricsPort is the port the proxy serves the metrics on.
+const MetricsPort = 9090
+
+// Load is the inference load reported by a proxy.
+type Load struct {
+	InFlightRequests int32
+	QueuedRequests   int32
+}
+
+var loadClient = &http.Client{Timeout: 5 * time.Second}
+
+// ReadLoad scrapes the metrics of the proxy served at metricsURL and returns the load.
+func ReadLoad(ctx context.Context, metricsURL string) (Load, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metricsURL, nil)
+	if err != nil {
+		return Load{}, err
+	}
+	res, err := loadClient.Do(req)
+	if err != nil {
+		return Load{}, err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return Load{}, fmt.Errorf("GET %s: unexpected status %s", metricsURL, res.Status)
+	}
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(res.Body)
+	if err != nil {
+		return Load{}, err
+	}
+	gauge := func(name string) int32 {
+		family, ok := families[name]
+		if !ok || len(family.GetMetric()) == 0 {
+			return 0
+		}
+		return int32(family.GetMetric()[0].GetGauge().GetValue())
+	}
+	return Load{
+		InFlightRequests: gauge(InFlightRequestsMetric),
+		QueuedRequests:   gauge(QueuedRequestsMetric),
+	}, nil
+}
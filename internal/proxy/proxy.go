@@ -0,0 +1,122 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package proxy implements the proxy which runs in front of the ollama server of a Model pod.
+// It measures the inference load served by the pod and publishes it as metrics.
+package proxy
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// InFlightRequestsMetric is the name of the gauge of the requests processed by the ollama server.
+	InFlightRequestsMetric = "ollama_proxy_in_flight_requests"
+	// QueuedRequestsMetric is the name of the gauge of the requests waiting in front of the ollama server.
+	QueuedRequestsMetric = "ollama_proxy_queued_requests"
+	// RequestsTotalMetric is the name of the counter of the served requests.
+	RequestsTotalMetric = "ollama_proxy_requests_total"
+)
+
+// Proxy is a http.Handler which forwards the requests to the ollama server.
+type Proxy struct {
+	reverse *httputil.ReverseProxy
+	// slots limits the number of the requests processed at once. It is nil if unlimited.
+	slots chan struct{}
+
+	inFlight prometheus.Gauge
+	queued   prometheus.Gauge
+	requests *prometheus.CounterVec
+}
+
+// New returns a Proxy which forwards the requests to upstream.
+// If concurrency is positive, the requests exceeding it are queued until a request completes.
+// The metrics are registered to registerer.
+func New(upstream *url.URL, concurrency int, registerer prometheus.Registerer) (*Proxy, error) {
+	reverse := httputil.NewSingleHostReverseProxy(upstream)
+	// The responses of the ollama server are streamed.
+	reverse.FlushInterval = -1
+	p := &Proxy{
+		reverse: reverse,
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: InFlightRequestsMetric,
+			Help: "Number of the requests processed by the ollama server.",
+		}),
+		queued: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: QueuedRequestsMetric,
+			Help: "Number of the requests waiting in front of the ollama server.",
+		}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: RequestsTotalMetric,
+			Help: "Number of the requests served by the ollama server.",
+		}, []string{"code"}),
+	}
+	if concurrency > 0 {
+		p.slots = make(chan struct{}, concurrency)
+	}
+	for _, c := range []prometheus.Collector{p.inFlight, p.queued, p.requests} {
+		if err := registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p.slots != nil {
+		p.queued.Inc()
+		select {
+		case p.slots <- struct{}{}:
+			p.queued.Dec()
+			defer func() { <-p.slots }()
+		case <-r.Context().Done():
+			p.queued.Dec()
+			return
+		}
+	}
+	p.inFlight.Inc()
+	defer p.inFlight.Dec()
+
+	rw := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+	p.reverse.ServeHTTP(rw, r)
+	p.requests.WithLabelValues(strconv.Itoa(rw.code)).Inc()
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to flush the streamed responses.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func TestProxy(t *testing.T) {
+	t.Run("Should queue the requests exceeding the concurrency and report the load", func(t *testing.T) {
+		g := NewWithT(t)
+		release := make(chan struct{})
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			<-release
+			_, _ = w.Write([]byte("ok"))
+		}))
+		t.Cleanup(upstream.Close)
+		upstreamURL, err := url.Parse(upstream.URL)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		registry := prometheus.NewRegistry()
+		p, err := New(upstreamURL, 1, registry)
+		g.Expect(err).NotTo(HaveOccurred())
+		srv := httptest.NewServer(p)
+		t.Cleanup(srv.Close)
+		metrics := httptest.NewServer(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+		t.Cleanup(metrics.Close)
+
+		done := make(chan struct{}, 3)
+		for range 3 {
+			go func() {
+				res, err := http.Get(srv.URL)
+				if err == nil {
+					_ = res.Body.Close()
+				}
+				done <- struct{}{}
+			}()
+		}
+		g.Eventually(func(g Gomega) {
+			load, err := ReadLoad(context.Background(), metrics.URL)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(load).To(Equal(Load{InFlightRequests: 1, QueuedRequests: 2}))
+		}).WithTimeout(5 * time.Second).Should(Succeed())
+
+		close(release)
+		for range 3 {
+			<-done
+		}
+		load, err := ReadLoad(context.Background(), metrics.URL)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(load).To(Equal(Load{}))
+	})
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/sivchari/ollama-operator/internal/ollama"
+	"github.com/sivchari/ollama-operator/internal/registry"
+)
+
+// Store is the object storage the models are backed up to, in the layout of the models directory.
+// The errors of the objects which do not exist match fs.ErrNotExist.
+type Store interface {
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Head(ctx context.Context, key string) (int64, error)
+	Put(ctx context.Context, key string, body io.Reader, size int64) error
+}
+
+// restore restores the model from the backup. It reports false if the model is not backed up.
+func (p *Puller) restore(ctx context.Context, name ollama.Name) (bool, error) {
+	key, err := manifestKey(name)
+	if err != nil {
+		return false, err
+	}
+	body, err := p.Backup.Get(ctx, p.backupKey(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	raw, err := io.ReadAll(body)
+	_ = body.Close()
+	if err != nil {
+		return false, err
+	}
+	var manifest registry.Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return false, fmt.Errorf("invalid manifest of %s in the backup: %w", name, err)
+	}
+	if err := validateManifest(&manifest); err != nil {
+		return false, fmt.Errorf("invalid manifest of %s in the backup: %w", name, err)
+	}
+	for _, layer := range manifest.Blobs() {
+		if err := p.restoreBlob(ctx, layer); err != nil {
+			return false, fmt.Errorf("failed to restore %s: %w", name, err)
+		}
+	}
+	return true, p.writeManifest(name, raw)
+}
+
+func (p *Puller) restoreBlob(ctx context.Context, layer registry.Layer) error {
+	dst, err := p.blobPath(layer.Digest)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(dst); err == nil && info.Size() == layer.Size {
+		return nil
+	}
+	body, err := p.Backup.Get(ctx, p.backupKey(blobKey(layer.Digest)))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = body.Close() }()
+	return WriteBlob(dst, layer.Digest, body)
+}
+
+// export backs up the model, skipping the blobs which are already backed up. The manifest is backed up the last,
+// so a model in the backup is always complete.
+func (p *Puller) export(ctx context.Context, name ollama.Name) error {
+	key, err := manifestKey(name)
+	if err != nil {
+		return err
+	}
+	manifestPath, err := p.manifestPath(name)
+	if err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return err
+	}
+	var manifest registry.Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return err
+	}
+	for _, layer := range manifest.Blobs() {
+		if err := p.exportBlob(ctx, layer); err != nil {
+			return err
+		}
+	}
+	return p.Backup.Put(ctx, p.backupKey(key), strings.NewReader(string(raw)), int64(len(raw)))
+}
+
+func (p *Puller) exportBlob(ctx context.Context, layer registry.Layer) error {
+	src, err := p.blobPath(layer.Digest)
+	if err != nil {
+		return err
+	}
+	key := p.backupKey(blobKey(layer.Digest))
+	if size, err := p.Backup.Head(ctx, key); err == nil && size == layer.Size {
+		return nil
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	return p.Backup.Put(ctx, key, f, layer.Size)
+}
+
+func (p *Puller) backupKey(key string) string {
+	return path.Join(p.BackupPrefix, key)
+}
+
+func manifestKey(name ollama.Name) (string, error) {
+	manifestPath, err := name.ManifestPath()
+	if err != nil {
+		return "", err
+	}
+	return path.Join("manifests", manifestPath), nil
+}
+
+func blobKey(digest string) string {
+	return path.Join("blobs", strings.Replace(digest, ":", "-", 1))
+}
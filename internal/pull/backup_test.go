@@ -0,0 +1,210 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/sivchari/ollama-operator/internal/registry"
+)
+
+// memStore is an object storage which keeps the objects in memory.
+type memStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	puts    int
+	err     error
+}
+
+func newMemStore() *memStore {
+	return &memStore{objects: map[string][]byte{}}
+}
+
+func (s *memStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	b, ok := s.objects[key]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (s *memStore) Head(_ context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	b, ok := s.objects[key]
+	if !ok {
+		return 0, fs.ErrNotExist
+	}
+	return int64(len(b)), nil
+}
+
+func (s *memStore) Put(_ context.Context, key string, body io.Reader, _ int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	s.objects[key] = b
+	s.puts++
+	return nil
+}
+
+func TestBackup(t *testing.T) {
+	manifestKey := "backups/manifests/registry.ollama.ai/library/llama3/8b"
+
+	t.Run("Should back up the pulled model and restore it without the registry", func(t *testing.T) {
+		g := NewWithT(t)
+		reg := newFakeRegistry(t, "config", "weights")
+		store := newMemStore()
+		p := &Puller{Registry: reg.client(), Dir: t.TempDir(), Backup: store, BackupPrefix: "backups"}
+		g.Expect(p.Pull(context.Background(), "llama3:8b")).To(Succeed())
+		g.Expect(store.objects).To(HaveKeyWithValue(manifestKey, reg.manifest))
+		g.Expect(store.objects).To(HaveKeyWithValue("backups/blobs/"+filepath.Base(mustBlobPath(t, "", digestOf("weights"))), []byte("weights")))
+
+		// The lost models directory is restored from the backup.
+		requests := reg.requests.Load()
+		dir := t.TempDir()
+		p = &Puller{Registry: reg.client(), Dir: dir, Backup: store, BackupPrefix: "backups"}
+		puts := store.puts
+		g.Expect(p.Pull(context.Background(), "llama3:8b")).To(Succeed())
+		g.Expect(reg.requests.Load()).To(Equal(requests))
+		g.Expect(store.puts).To(Equal(puts))
+		g.Expect(os.ReadFile(filepath.Join(dir, "manifests", "registry.ollama.ai", "library", "llama3", "8b"))).To(Equal(reg.manifest))
+		g.Expect(os.ReadFile(mustBlobPath(t, dir, digestOf("weights")))).To(Equal([]byte("weights")))
+	})
+
+	t.Run("Should fall back to the registry if the backup is corrupted", func(t *testing.T) {
+		g := NewWithT(t)
+		reg := newFakeRegistry(t, "config", "weights")
+		store := newMemStore()
+		p := &Puller{Registry: reg.client(), Dir: t.TempDir(), Backup: store}
+		g.Expect(p.Pull(context.Background(), "llama3:8b")).To(Succeed())
+		store.objects["blobs/"+filepath.Base(mustBlobPath(t, "", digestOf("weights")))] = []byte("tampered")
+
+		dir := t.TempDir()
+		p = &Puller{Registry: reg.client(), Dir: dir, Backup: store}
+		g.Expect(p.Pull(context.Background(), "llama3:8b")).To(Succeed())
+		g.Expect(os.ReadFile(mustBlobPath(t, dir, digestOf("weights")))).To(Equal([]byte("weights")))
+		// The tampered blob is backed up again.
+		g.Expect(store.objects).To(HaveKeyWithValue("blobs/"+filepath.Base(mustBlobPath(t, "", digestOf("weights"))), []byte("weights")))
+	})
+
+	t.Run("Should not restore anything from the malformed manifests in the backup", func(t *testing.T) {
+		for _, tt := range []struct {
+			name     string
+			manifest registry.Manifest
+		}{
+			{
+				name: "unsupported media type",
+				manifest: registry.Manifest{
+					SchemaVersion: 2,
+					MediaType:     "text/html",
+					Config:        registry.Layer{Digest: digestOf("other"), Size: 5},
+				},
+			},
+			{
+				name: "invalid digest",
+				manifest: registry.Manifest{
+					SchemaVersion: 2,
+					MediaType:     "application/vnd.docker.distribution.manifest.v2+json",
+					Config:        registry.Layer{Digest: digestOf("other"), Size: 5},
+					Layers:        []registry.Layer{{Digest: "sha256:../../../../etc/x", Size: 1}},
+				},
+			},
+		} {
+			t.Run("Should reject the "+tt.name, func(t *testing.T) {
+				g := NewWithT(t)
+				reg := newFakeRegistry(t, "config", "weights")
+				store := newMemStore()
+				raw, err := json.Marshal(tt.manifest)
+				g.Expect(err).NotTo(HaveOccurred())
+				store.objects["manifests/registry.ollama.ai/library/llama3/8b"] = raw
+				store.objects["blobs/"+filepath.Base(mustBlobPath(t, "", digestOf("other")))] = []byte("other")
+
+				dir := t.TempDir()
+				p := &Puller{Registry: reg.client(), Dir: dir, Backup: store}
+				results, err := p.PullAll(context.Background(), []string{"llama3:8b"}, Policy{MaxAttempts: 1})
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(results[0].BackupError).To(ContainSubstring("invalid manifest"))
+				g.Expect(mustBlobPath(t, dir, digestOf("other"))).NotTo(BeAnExistingFile())
+				g.Expect(os.ReadFile(filepath.Join(dir, "manifests", "registry.ollama.ai", "library", "llama3", "8b"))).To(Equal(reg.manifest))
+			})
+		}
+	})
+
+	t.Run("Should pull the model even if the backup is unavailable", func(t *testing.T) {
+		g := NewWithT(t)
+		reg := newFakeRegistry(t, "config", "weights")
+		store := newMemStore()
+		store.err = errors.New("unavailable")
+		dir := t.TempDir()
+		p := &Puller{Registry: reg.client(), Dir: dir, Backup: store}
+		g.Expect(p.Pull(context.Background(), "llama3:8b")).To(Succeed())
+		g.Expect(filepath.Join(dir, "manifests", "registry.ollama.ai", "library", "llama3", "8b")).To(BeAnExistingFile())
+
+		// The failures of the backup are reported in the results.
+		results, err := p.PullAll(context.Background(), []string{"llama3:8b"}, Policy{MaxAttempts: 1})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(results).To(HaveLen(1))
+		g.Expect(results[0].Error).To(BeEmpty())
+		g.Expect(results[0].BackupError).To(ContainSubstring("failed to back up registry.ollama.ai/library/llama3:8b: unavailable"))
+	})
+
+	t.Run("Should back up the present model", func(t *testing.T) {
+		g := NewWithT(t)
+		reg := newFakeRegistry(t, "config", "weights")
+		dir := t.TempDir()
+		g.Expect((&Puller{Registry: reg.client(), Dir: dir}).Pull(context.Background(), "llama3:8b")).To(Succeed())
+
+		store := newMemStore()
+		p := &Puller{Registry: reg.client(), Dir: dir, Backup: store}
+		g.Expect(p.Pull(context.Background(), "llama3:8b")).To(Succeed())
+		g.Expect(store.objects).To(HaveLen(3))
+	})
+}
+
+func mustBlobPath(t *testing.T, dir, digest string) string {
+	t.Helper()
+	path, err := BlobPath(dir, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/sivchari/ollama-operator/internal/ollama"
+	"github.com/sivchari/ollama-operator/internal/registry"
+)
+
+const (
+	ociIndexMediaType = "application/vnd.oci.image.index.v1+json"
+	// ociRefNameAnnotation is the annotation of the reference name of a manifest in the index of an OCI image layout.
+	ociRefNameAnnotation = "org.opencontainers.image.ref.name"
+)
+
+// Layout is an OCI image layout a model is imported from.
+type Layout struct {
+	// Path is the directory of the layout or the path of its tarball.
+	Path string
+	// Reference selects the manifest by the org.opencontainers.image.ref.name annotation.
+	// It may be empty if the layout has a single manifest.
+	Reference string
+}
+
+type ociIndex struct {
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// importLayout imports the model from the OCI image layout. The manifest and the blobs are verified against their digests.
+func (p *Puller) importLayout(name ollama.Name, layout Layout) error {
+	files, err := openLayout(layout.Path)
+	if err != nil {
+		return err
+	}
+	index, err := readAll(files, "index.json")
+	if err != nil {
+		return err
+	}
+	var idx ociIndex
+	if err := json.Unmarshal(index, &idx); err != nil {
+		return fmt.Errorf("invalid index of %s: %w", layout.Path, err)
+	}
+	desc, err := selectManifest(idx, layout.Reference)
+	if err != nil {
+		return fmt.Errorf("%s: %w", layout.Path, err)
+	}
+	raw, err := readBlob(files, desc.Digest)
+	if err != nil {
+		return err
+	}
+	var manifest registry.Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return fmt.Errorf("invalid manifest %s in %s: %w", desc.Digest, layout.Path, err)
+	}
+	for _, layer := range manifest.Blobs() {
+		if err := p.importBlob(files, layer); err != nil {
+			return fmt.Errorf("failed to import %s from %s: %w", name, layout.Path, err)
+		}
+	}
+	return p.writeManifest(name, raw)
+}
+
+func (p *Puller) importBlob(files layoutFiles, layer registry.Layer) error {
+	dst, err := p.blobPath(layer.Digest)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(dst); err == nil && info.Size() == layer.Size {
+		return nil
+	}
+	src, err := files.open(ociBlobPath(layer.Digest))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+	return WriteBlob(dst, layer.Digest, src)
+}
+
+func selectManifest(idx ociIndex, reference string) (ociDescriptor, error) {
+	var matched []ociDescriptor
+	for _, desc := range idx.Manifests {
+		if desc.MediaType == ociIndexMediaType {
+			continue
+		}
+		if reference == "" || desc.Annotations[ociRefNameAnnotation] == reference {
+			matched = append(matched, desc)
+		}
+	}
+	switch {
+	case len(matched) == 1:
+		return matched[0], nil
+	case len(matched) == 0 && reference != "":
+		return ociDescriptor{}, fmt.Errorf("no manifest with reference %q", reference)
+	case len(matched) == 0:
+		return ociDescriptor{}, errors.New("no manifest")
+	default:
+		return ociDescriptor{}, errors.New("more than one manifest, the reference is required")
+	}
+}
+
+// readBlob reads the small blob with digest, e.g. a manifest, and verifies it.
+func readBlob(files layoutFiles, digest string) ([]byte, error) {
+	if !digestPattern.MatchString(digest) {
+		return nil, fmt.Errorf("invalid digest %q", digest)
+	}
+	b, err := readAll(files, ociBlobPath(digest))
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+	if got := "sha256:" + hex.EncodeToString(sum[:]); got != digest {
+		return nil, fmt.Errorf("digest mismatch of blob %s: got %s", digest, got)
+	}
+	return b, nil
+}
+
+func ociBlobPath(digest string) string {
+	return path.Join("blobs", strings.Replace(digest, ":", "/", 1))
+}
+
+// layoutFiles opens the files of an OCI image layout by the path relative to its root.
+type layoutFiles interface {
+	open(name string) (io.ReadCloser, error)
+}
+
+func openLayout(path string) (layoutFiles, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return dirLayout(path), nil
+	}
+	return tarLayout(path), nil
+}
+
+func readAll(files layoutFiles, name string) ([]byte, error) {
+	f, err := files.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return io.ReadAll(f)
+}
+
+type dirLayout string
+
+func (d dirLayout) open(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(string(d), filepath.FromSlash(name)))
+}
+
+// tarLayout is a tarball of an OCI image layout. A file is looked up by reading the tarball from the start,
+// which is cheap enough for the few blobs of a model.
+type tarLayout string
+
+func (t tarLayout) open(name string) (io.ReadCloser, error) {
+	f, err := os.Open(string(t))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			_ = f.Close()
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("open %s in %s: %w", name, t, fs.ErrNotExist)
+			}
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Clean(strings.TrimPrefix(hdr.Name, "./")) == name {
+			return struct {
+				io.Reader
+				io.Closer
+			}{tr, f}, nil
+		}
+	}
+}
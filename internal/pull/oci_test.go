@@ -0,0 +1,150 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/sivchari/ollama-operator/internal/registry"
+)
+
+// writeLayout writes an OCI image layout to dir whose manifests are tagged with the references, each holding blobs.
+// It returns the raw manifests by the reference.
+func writeLayout(t *testing.T, dir string, blobs []string, references ...string) map[string][]byte {
+	t.Helper()
+	writeBlob := func(b string) {
+		path := filepath.Join(dir, ociBlobPath(digestOf(b)))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(b), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	manifests := map[string][]byte{}
+	var index ociIndex
+	for _, ref := range references {
+		manifest := registry.Manifest{SchemaVersion: 2, Config: registry.Layer{Digest: digestOf(ref), Size: int64(len(ref))}}
+		writeBlob(ref)
+		for _, blob := range blobs {
+			manifest.Layers = append(manifest.Layers, registry.Layer{Digest: digestOf(blob), Size: int64(len(blob))})
+			writeBlob(blob)
+		}
+		raw, _ := json.Marshal(manifest)
+		writeBlob(string(raw))
+		manifests[ref] = raw
+		index.Manifests = append(index.Manifests, ociDescriptor{
+			MediaType:   "application/vnd.oci.image.manifest.v1+json",
+			Digest:      digestOf(string(raw)),
+			Size:        int64(len(raw)),
+			Annotations: map[string]string{ociRefNameAnnotation: ref},
+		})
+	}
+	raw, _ := json.Marshal(index)
+	if err := os.WriteFile(filepath.Join(dir, "index.json"), raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return manifests
+}
+
+// writeTar archives the files in dir to a tarball.
+func writeTar(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "layout.tar")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	tw := tar.NewWriter(f)
+	if err := tw.AddFS(os.DirFS(dir)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestImportLayout(t *testing.T) {
+	manifestPath := func(dir string) string {
+		return filepath.Join(dir, "manifests", "registry.ollama.ai", "library", "llama3", "8b")
+	}
+
+	t.Run("Should import the model from the directory and the tarball of the layout", func(t *testing.T) {
+		layout := t.TempDir()
+		manifests := writeLayout(t, layout, []string{"weights"}, "8b", "70b")
+		for name, path := range map[string]string{"directory": layout, "tarball": writeTar(t, layout)} {
+			t.Run(name, func(t *testing.T) {
+				g := NewWithT(t)
+				dir := t.TempDir()
+				p := &Puller{Dir: dir, Layouts: map[string]Layout{"llama3:8b": {Path: path, Reference: "8b"}}}
+				g.Expect(p.Pull(context.Background(), "llama3:8b")).To(Succeed())
+				g.Expect(os.ReadFile(manifestPath(dir))).To(Equal(manifests["8b"]))
+				for _, blob := range []string{"8b", "weights"} {
+					path, _ := BlobPath(dir, digestOf(blob))
+					g.Expect(os.ReadFile(path)).To(Equal([]byte(blob)))
+				}
+				path, _ := BlobPath(dir, digestOf("70b"))
+				g.Expect(path).NotTo(BeAnExistingFile())
+			})
+		}
+	})
+
+	t.Run("Should require the reference of the layout with more than one manifest", func(t *testing.T) {
+		g := NewWithT(t)
+		layout := t.TempDir()
+		writeLayout(t, layout, []string{"weights"}, "8b", "70b")
+		p := &Puller{Dir: t.TempDir(), Layouts: map[string]Layout{"llama3:8b": {Path: layout}}}
+		g.Expect(p.Pull(context.Background(), "llama3:8b")).To(MatchError(ContainSubstring("the reference is required")))
+
+		p.Layouts["llama3:8b"] = Layout{Path: layout, Reference: "1b"}
+		g.Expect(p.Pull(context.Background(), "llama3:8b")).To(MatchError(ContainSubstring(`no manifest with reference "1b"`)))
+	})
+
+	t.Run("Should not import the model whose blob does not match the digest", func(t *testing.T) {
+		g := NewWithT(t)
+		layout := t.TempDir()
+		writeLayout(t, layout, []string{"weights"}, "8b")
+		g.Expect(os.WriteFile(filepath.Join(layout, ociBlobPath(digestOf("weights"))), []byte("tampered"), 0o644)).To(Succeed())
+		dir := t.TempDir()
+		p := &Puller{Dir: dir, Layouts: map[string]Layout{"llama3:8b": {Path: layout}}}
+		err := p.Pull(context.Background(), "llama3:8b")
+		g.Expect(err).To(MatchError(ContainSubstring("digest mismatch")))
+		g.Expect(manifestPath(dir)).NotTo(BeAnExistingFile())
+	})
+
+	t.Run("Should not import the manifest which does not match the digest", func(t *testing.T) {
+		g := NewWithT(t)
+		layout := t.TempDir()
+		manifests := writeLayout(t, layout, []string{"weights"}, "8b")
+		tampered := strings.Replace(string(manifests["8b"]), `"schemaVersion":2`, `"schemaVersion":3`, 1)
+		g.Expect(os.WriteFile(filepath.Join(layout, ociBlobPath(digestOf(string(manifests["8b"])))), []byte(tampered), 0o644)).To(Succeed())
+		dir := t.TempDir()
+		p := &Puller{Dir: dir, Layouts: map[string]Layout{"llama3:8b": {Path: layout}}}
+		g.Expect(p.Pull(context.Background(), "llama3:8b")).To(MatchError(ContainSubstring("digest mismatch")))
+		g.Expect(manifestPath(dir)).NotTo(BeAnExistingFile())
+	})
+}
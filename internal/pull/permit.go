@@ -0,0 +1,67 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+// WaitPermit waits until the pull scheduler of the operator permits the pulls of the pod, whose labels are projected
+// into labelsFile by the downward API.
+func WaitPermit(ctx context.Context, labelsFile string, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		b, err := os.ReadFile(labelsFile)
+		if err != nil {
+			return err
+		}
+		if podLabel(b, ollamav1alpha1.PullStateLabel) == ollamav1alpha1.PullStatePermitted {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// podLabel returns the value of the label key in the labels projected by the downward API,
+// which are the lines of key="value" with the quoted values.
+func podLabel(labels []byte, key string) string {
+	scanner := bufio.NewScanner(bytes.NewReader(labels))
+	for scanner.Scan() {
+		k, v, ok := strings.Cut(scanner.Text(), "=")
+		if !ok || k != key {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(v); err == nil {
+			return unquoted
+		}
+		return v
+	}
+	return ""
+}
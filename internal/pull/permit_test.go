@@ -0,0 +1,50 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestWaitPermit(t *testing.T) {
+	t.Run("Should wait until the pulls are permitted", func(t *testing.T) {
+		g := NewWithT(t)
+		file := filepath.Join(t.TempDir(), "labels")
+		g.Expect(os.WriteFile(file, []byte("app=\"ollama\"\nollama.sivchari.io/pull-state=\"Queued\"\n"), 0o644)).To(Succeed())
+		done := make(chan error, 1)
+		go func() { done <- WaitPermit(context.Background(), file, 10*time.Millisecond) }()
+		g.Consistently(done, 50*time.Millisecond).ShouldNot(Receive())
+
+		g.Expect(os.WriteFile(file, []byte("app=\"ollama\"\nollama.sivchari.io/pull-state=\"Permitted\"\n"), 0o644)).To(Succeed())
+		g.Eventually(done).Should(Receive(BeNil()))
+	})
+
+	t.Run("Should stop waiting when the context is done", func(t *testing.T) {
+		g := NewWithT(t)
+		file := filepath.Join(t.TempDir(), "labels")
+		g.Expect(os.WriteFile(file, []byte("ollama.sivchari.io/pull-state=\"Queued\"\n"), 0o644)).To(Succeed())
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		g.Expect(WaitPermit(ctx, file, 5*time.Millisecond)).To(MatchError(context.DeadlineExceeded))
+	})
+}
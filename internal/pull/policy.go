@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// maxResultsSize is the maximum size of the results, which is the limit of the termination message of a container.
+const maxResultsSize = 4096
+
+// Policy is the policy of the retries of the pulls.
+type Policy struct {
+	// MaxAttempts is the number of the attempts to pull an image. It is at least 1.
+	MaxAttempts int
+	// Backoff is the wait before the second attempt, which is doubled after each failed attempt up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Timeout is the timeout of each attempt. 0 means no timeout.
+	Timeout time.Duration
+	// ContinueOnError pulls the remaining images after an image has failed all its attempts.
+	ContinueOnError bool
+}
+
+// Result is the result of the pull of an image.
+type Result struct {
+	Image    string `json:"image"`
+	Attempts int    `json:"attempts"`
+	// Error is the error of the last attempt. It is empty if the image is pulled.
+	Error string `json:"error,omitempty"`
+	// BackupError is the error of the backup of the image in the last attempt, which does not fail the pull.
+	BackupError string `json:"backupError,omitempty"`
+}
+
+// PullAll pulls images following the policy, and returns the results of the pulled images. It returns an error
+// if any image has failed all its attempts. Unless the policy continues on the errors, the images after it are
+// not pulled.
+func (p *Puller) PullAll(ctx context.Context, images []string, policy Policy) ([]Result, error) {
+	results := make([]Result, 0, len(images))
+	var errs []error
+	for _, image := range images {
+		result, err := p.pullWithRetries(ctx, image, policy)
+		results = append(results, result)
+		if err == nil {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("failed to pull %s after %d attempts: %w", image, result.Attempts, err))
+		if !policy.ContinueOnError || ctx.Err() != nil {
+			break
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+func (p *Puller) pullWithRetries(ctx context.Context, image string, policy Policy) (Result, error) {
+	result := Result{Image: image}
+	backoff := policy.Backoff
+	for {
+		result.Attempts++
+		result.BackupError = ""
+		err := p.pullOnce(ctx, image, policy.Timeout, func(err error) { result.BackupError = err.Error() })
+		if err == nil {
+			result.Error = ""
+			return result, nil
+		}
+		result.Error = err.Error()
+		if result.Attempts >= policy.MaxAttempts || ctx.Err() != nil {
+			return result, err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, max(policy.MaxBackoff, policy.Backoff))
+	}
+}
+
+func (p *Puller) pullOnce(ctx context.Context, image string, timeout time.Duration, reportBackup func(error)) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	err := p.pullAndBackup(ctx, image, reportBackup)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s: %w", timeout, err)
+	}
+	return err
+}
+
+// WriteResults writes the results as JSON to path, e.g. the termination message of the container.
+// The errors are truncated to keep the results within maxResultsSize.
+func WriteResults(path string, results []Result) error {
+	b, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	for limit := 1024; len(b) > maxResultsSize && limit > 0; limit /= 2 {
+		truncated := make([]Result, len(results))
+		for i, result := range results {
+			if len(result.Error) > limit {
+				result.Error = result.Error[:limit]
+			}
+			if len(result.BackupError) > limit {
+				result.BackupError = result.BackupError[:limit]
+			}
+			truncated[i] = result
+		}
+		if b, err = json.Marshal(truncated); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, b, 0o644)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestPullAll(t *testing.T) {
+	policy := Policy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+	t.Run("Should retry the failed pulls", func(t *testing.T) {
+		g := NewWithT(t)
+		reg := newFakeRegistry(t, "config", "weights")
+		reg.failures.Store(2)
+		p := &Puller{Registry: reg.client(), Dir: t.TempDir()}
+		results, err := p.PullAll(context.Background(), []string{"llama3:8b"}, policy)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(results).To(Equal([]Result{{Image: "llama3:8b", Attempts: 3}}))
+	})
+
+	t.Run("Should stop at the image which has failed all its attempts", func(t *testing.T) {
+		g := NewWithT(t)
+		reg := newFakeRegistry(t, "config", "weights")
+		p := &Puller{Registry: reg.client(), Dir: t.TempDir()}
+		results, err := p.PullAll(context.Background(), []string{"unknown", "llama3:8b"}, policy)
+		g.Expect(err).To(MatchError(ContainSubstring("failed to pull unknown after 3 attempts")))
+		g.Expect(results).To(HaveLen(1))
+		g.Expect(results[0].Attempts).To(Equal(3))
+		g.Expect(results[0].Error).To(ContainSubstring("404"))
+	})
+
+	t.Run("Should continue on the errors", func(t *testing.T) {
+		g := NewWithT(t)
+		reg := newFakeRegistry(t, "config", "weights")
+		p := &Puller{Registry: reg.client(), Dir: t.TempDir()}
+		continuing := policy
+		continuing.ContinueOnError = true
+		results, err := p.PullAll(context.Background(), []string{"unknown", "llama3:8b"}, continuing)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(results).To(HaveLen(2))
+		g.Expect(results[1]).To(Equal(Result{Image: "llama3:8b", Attempts: 1}))
+	})
+
+	t.Run("Should time out the hung pull", func(t *testing.T) {
+		g := NewWithT(t)
+		reg := newFakeRegistry(t, "config", "weights")
+		reg.delay = time.Minute
+		p := &Puller{Registry: reg.client(), Dir: t.TempDir()}
+		timeout := Policy{MaxAttempts: 2, Backoff: time.Millisecond, Timeout: 20 * time.Millisecond}
+		results, err := p.PullAll(context.Background(), []string{"llama3:8b"}, timeout)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(results[0].Attempts).To(Equal(2))
+		g.Expect(results[0].Error).To(HavePrefix("timed out after 20ms"))
+	})
+}
+
+func TestWriteResults(t *testing.T) {
+	t.Run("Should truncate the errors to fit the termination message", func(t *testing.T) {
+		g := NewWithT(t)
+		path := filepath.Join(t.TempDir(), "termination-log")
+		var results []Result
+		for _, image := range []string{"llama3", "phi3", "gemma", "mistral", "qwen"} {
+			results = append(results, Result{Image: image, Attempts: 3, Error: strings.Repeat("x", 2000)})
+		}
+		g.Expect(WriteResults(path, results)).To(Succeed())
+		b, err := os.ReadFile(path)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(len(b)).To(BeNumerically("<=", maxResultsSize))
+		var written []Result
+		g.Expect(json.Unmarshal(b, &written)).To(Succeed())
+		g.Expect(written).To(HaveLen(5))
+		g.Expect(written[4].Image).To(Equal("qwen"))
+		g.Expect(written[4].Error).NotTo(BeEmpty())
+	})
+}
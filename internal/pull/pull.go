@@ -0,0 +1,270 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pull pulls the models into the models directory of the ollama server.
+// The blobs and the manifests are written in the layout of the ollama server, so it serves the pulled models as is.
+package pull
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/sivchari/ollama-operator/internal/ollama"
+	"github.com/sivchari/ollama-operator/internal/registry"
+)
+
+var digestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// manifestMediaTypes are the media types of the manifests of the models, which are pulled from the registries
+// or imported from the OCI image layouts.
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// Puller pulls the models from the registries.
+type Puller struct {
+	Registry *registry.Client
+	// Dir is the models directory of the ollama server.
+	Dir string
+	// Layouts are the OCI image layouts the models are imported from instead of the registries, by the image.
+	Layouts map[string]Layout
+	// Backup is the object storage the models are restored from before they are pulled, and backed up to
+	// after they are pulled. It may be nil.
+	Backup Store
+	// BackupPrefix is the prefix of the keys of the models in Backup.
+	BackupPrefix string
+	// Peers are the pods of the same Model the blobs are copied from before they are pulled from the registries.
+	// It may be nil.
+	Peers *Peers
+}
+
+// Pull pulls the model named image, or imports it from its layout. The model is not pulled again if its manifest
+// and all its blobs are present.
+// The blobs are verified against their digests before they are stored, and the manifest is stored the last,
+// so the ollama server never sees a partially pulled model.
+// If the backup is configured, the model is restored from it first, and is backed up unless it is restored.
+// The failures of the backup are logged and reported in the results of PullAll, and do not fail the pull.
+func (p *Puller) Pull(ctx context.Context, image string) error {
+	return p.pullAndBackup(ctx, image, func(error) {})
+}
+
+// pullAndBackup pulls the model named image as Pull does, and reports the failures of its backup to reportBackup.
+func (p *Puller) pullAndBackup(ctx context.Context, image string, reportBackup func(error)) error {
+	name, err := ollama.ParseName(image)
+	if err != nil {
+		return err
+	}
+	var backupErrs []error
+	defer func() {
+		if len(backupErrs) > 0 {
+			reportBackup(errors.Join(backupErrs...))
+		}
+	}()
+	if !p.present(name) {
+		if p.Backup != nil {
+			restored, err := p.restore(ctx, name)
+			if err != nil {
+				log.FromContext(ctx).Error(err, "unable to restore the model from the backup", "model", name.String())
+				backupErrs = append(backupErrs, fmt.Errorf("failed to restore %s from the backup: %w", name, err))
+			}
+			if restored {
+				return nil
+			}
+		}
+		if err := p.pull(ctx, image, name); err != nil {
+			return err
+		}
+	}
+	if p.Backup != nil {
+		if err := p.export(ctx, name); err != nil {
+			log.FromContext(ctx).Error(err, "unable to back up the model", "model", name.String())
+			backupErrs = append(backupErrs, fmt.Errorf("failed to back up %s: %w", name, err))
+		}
+	}
+	return nil
+}
+
+func (p *Puller) pull(ctx context.Context, image string, name ollama.Name) error {
+	if layout, ok := p.Layouts[image]; ok {
+		return p.importLayout(name, layout)
+	}
+	manifest, raw, err := p.Registry.Manifest(ctx, name)
+	if err != nil {
+		return err
+	}
+	if err := validateManifest(manifest); err != nil {
+		return fmt.Errorf("invalid manifest of %s: %w", image, err)
+	}
+	for _, layer := range manifest.Blobs() {
+		if err := p.pullBlob(ctx, name, layer); err != nil {
+			return fmt.Errorf("failed to pull %s: %w", image, err)
+		}
+	}
+	return p.writeManifest(name, raw)
+}
+
+// present reports whether the manifest of the model and all the blobs it references are present.
+func (p *Puller) present(name ollama.Name) bool {
+	manifestPath, err := p.manifestPath(name)
+	if err != nil {
+		return false
+	}
+	raw, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return false
+	}
+	var manifest registry.Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return false
+	}
+	for _, layer := range manifest.Blobs() {
+		path, err := p.blobPath(layer.Digest)
+		if err != nil {
+			return false
+		}
+		if info, err := os.Stat(path); err != nil || info.Size() != layer.Size {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Puller) pullBlob(ctx context.Context, name ollama.Name, layer registry.Layer) error {
+	path, err := p.blobPath(layer.Digest)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() == layer.Size {
+		return nil
+	}
+	if p.Peers != nil && p.Peers.copyBlob(ctx, path, layer) {
+		return nil
+	}
+	body, err := p.Registry.Blob(ctx, name, layer.Digest)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = body.Close() }()
+	return WriteBlob(path, layer.Digest, body)
+}
+
+// WriteBlob writes the blob read from r to path once its content matches digest.
+// The blob is written to a partial file first, so a blob at path is always complete.
+func WriteBlob(path, digest string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	partial := path + "-partial"
+	f, err := os.Create(partial)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(partial) }()
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hasher), r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if got := "sha256:" + hex.EncodeToString(hasher.Sum(nil)); got != digest {
+		return fmt.Errorf("digest mismatch of blob %s: got %s", digest, got)
+	}
+	return os.Rename(partial, path)
+}
+
+func (p *Puller) blobPath(digest string) (string, error) {
+	return BlobPath(p.Dir, digest)
+}
+
+func (p *Puller) manifestPath(name ollama.Name) (string, error) {
+	manifestPath, err := name.ManifestPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(p.Dir, "manifests", filepath.FromSlash(manifestPath)), nil
+}
+
+// writeManifest stores the manifest of the model, which makes it visible to the ollama server.
+func (p *Puller) writeManifest(name ollama.Name, raw []byte) error {
+	manifestPath, err := p.manifestPath(name)
+	if err != nil {
+		return err
+	}
+	return writeFile(manifestPath, raw)
+}
+
+// validateManifest returns an error unless manifest has a media type of the manifests of the models and its blobs
+// have valid digests and sizes, so nothing is written for a malformed manifest.
+func validateManifest(manifest *registry.Manifest) error {
+	if !slices.Contains(manifestMediaTypes, manifest.MediaType) {
+		return fmt.Errorf("unsupported media type %q", manifest.MediaType)
+	}
+	for _, layer := range manifest.Blobs() {
+		if !digestPattern.MatchString(layer.Digest) {
+			return fmt.Errorf("invalid digest %q", layer.Digest)
+		}
+		if layer.Size < 0 {
+			return fmt.Errorf("invalid size %d of blob %s", layer.Size, layer.Digest)
+		}
+	}
+	return nil
+}
+
+// BlobPath returns the path of the blob with digest in the models directory dir, e.g. "blobs/sha256-<hex>".
+func BlobPath(dir, digest string) (string, error) {
+	if !digestPattern.MatchString(digest) {
+		return "", fmt.Errorf("invalid digest %q", digest)
+	}
+	return filepath.Join(dir, "blobs", strings.Replace(digest, ":", "-", 1)), nil
+}
+
+// writeFile writes b to path through a temporary file, so path is never partially written.
+func writeFile(path string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/sivchari/ollama-operator/internal/ollama"
+	"github.com/sivchari/ollama-operator/internal/registry"
+)
+
+func digestOf(b string) string {
+	sum := sha256.Sum256([]byte(b))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// fakeRegistry serves llama3:8b, whose blobs are the contents of blobs. The served blobs can be replaced by serve.
+// The first failures requests fail, and the manifest is served after delay.
+type fakeRegistry struct {
+	*httptest.Server
+	manifest []byte
+	serve    map[string]string
+	requests atomic.Int32
+	failures atomic.Int32
+	delay    time.Duration
+}
+
+func newFakeRegistry(t *testing.T, blobs ...string) *fakeRegistry {
+	t.Helper()
+	r := &fakeRegistry{serve: map[string]string{}}
+	manifest := registry.Manifest{SchemaVersion: 2, MediaType: "application/vnd.docker.distribution.manifest.v2+json"}
+	for i, blob := range blobs {
+		layer := registry.Layer{Digest: digestOf(blob), Size: int64(len(blob))}
+		if i == 0 {
+			manifest.Config = layer
+		} else {
+			manifest.Layers = append(manifest.Layers, layer)
+		}
+		r.serve[layer.Digest] = blob
+	}
+	r.manifest, _ = json.Marshal(manifest)
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.requests.Add(1)
+		if r.failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if req.URL.Path == "/v2/library/llama3/manifests/8b" {
+			select {
+			case <-time.After(r.delay):
+			case <-req.Context().Done():
+				return
+			}
+			_, _ = w.Write(r.manifest)
+			return
+		}
+		if blob, ok := r.serve[strings.TrimPrefix(req.URL.Path, "/v2/library/llama3/blobs/")]; ok {
+			_, _ = w.Write([]byte(blob))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *fakeRegistry) client() *registry.Client {
+	host := strings.TrimPrefix(r.URL, "http://")
+	return registry.NewClient(registry.Config{
+		Mirrors: map[string]string{ollama.DefaultRegistry: host},
+		Hosts:   map[string]registry.Host{host: {Insecure: true}},
+	})
+}
+
+func TestPull(t *testing.T) {
+	t.Run("Should write the blobs and the manifest in the layout of the ollama server", func(t *testing.T) {
+		g := NewWithT(t)
+		reg := newFakeRegistry(t, "config", "weights")
+		dir := t.TempDir()
+		p := &Puller{Registry: reg.client(), Dir: dir}
+		g.Expect(p.Pull(context.Background(), "llama3:8b")).To(Succeed())
+
+		manifest, err := os.ReadFile(filepath.Join(dir, "manifests", "registry.ollama.ai", "library", "llama3", "8b"))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(manifest).To(Equal(reg.manifest))
+		for _, blob := range []string{"config", "weights"} {
+			path, err := BlobPath(dir, digestOf(blob))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(os.ReadFile(path)).To(Equal([]byte(blob)))
+		}
+
+		// The present model is not pulled again.
+		requests := reg.requests.Load()
+		g.Expect(p.Pull(context.Background(), "registry.ollama.ai/library/llama3:8b")).To(Succeed())
+		g.Expect(reg.requests.Load()).To(Equal(requests))
+	})
+
+	t.Run("Should not store the blob which does not match the digest", func(t *testing.T) {
+		g := NewWithT(t)
+		reg := newFakeRegistry(t, "config", "weights")
+		reg.serve[digestOf("weights")] = "tampered"
+		dir := t.TempDir()
+		p := &Puller{Registry: reg.client(), Dir: dir}
+		err := p.Pull(context.Background(), "llama3:8b")
+		g.Expect(err).To(MatchError(ContainSubstring("digest mismatch")))
+		g.Expect(filepath.Join(dir, "manifests", "registry.ollama.ai", "library", "llama3", "8b")).NotTo(BeAnExistingFile())
+		path, _ := BlobPath(dir, digestOf("weights"))
+		g.Expect(path).NotTo(BeAnExistingFile())
+		g.Expect(path + "-partial").NotTo(BeAnExistingFile())
+	})
+
+	t.Run("Should not write outside the models directory for the hostile name", func(t *testing.T) {
+		g := NewWithT(t)
+		reg := newFakeRegistry(t, "config", "weights")
+		root := t.TempDir()
+		dir := filepath.Join(root, "models")
+		p := &Puller{Registry: reg.client(), Dir: dir}
+		g.Expect(p.Pull(context.Background(), "../../../x:y")).NotTo(Succeed())
+		g.Expect(p.Pull(context.Background(), "llama3:../../../../x")).NotTo(Succeed())
+		g.Expect(reg.requests.Load()).To(BeZero())
+		g.Expect(os.ReadDir(root)).To(BeEmpty())
+	})
+
+	t.Run("Should reject the invalid digest", func(t *testing.T) {
+		g := NewWithT(t)
+		_, err := BlobPath(t.TempDir(), "sha256:../../etc/passwd")
+		g.Expect(err).To(HaveOccurred())
+	})
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/sivchari/ollama-operator/internal/registry"
+)
+
+// SeedPort is the port the seeders serve the blobs of the pods on. It differs from the port the ollama server
+// listens on behind the proxy of the pod, which is 11435.
+const SeedPort = 11436
+
+// NewSeedHandler returns the handler serving the blobs of the models directory dir by their digests,
+// e.g. "GET /blobs/sha256:<hex>", to the pods of the same Model which copy them instead of pulling them.
+func NewSeedHandler(dir string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /blobs/{digest}", func(w http.ResponseWriter, req *http.Request) {
+		path, err := BlobPath(dir, req.PathValue("digest"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			http.NotFound(w, req)
+			return
+		}
+		defer func() { _ = f.Close() }()
+		info, err := f.Stat()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.ServeContent(w, req, "", info.ModTime(), f)
+	})
+	return mux
+}
+
+// Peers are the seeders of the ready pods of the same Model, which are resolved from the DNS name of their
+// headless Service once and tried in order before the registry.
+type Peers struct {
+	// Host is the DNS name of the headless Service of the ready pods.
+	Host string
+	// Port is the port of the seeders. If it is 0, SeedPort is used.
+	Port   int
+	Client *http.Client
+	// Resolver resolves Host to the addresses of the pods. If it is nil, the default resolver is used.
+	Resolver func(ctx context.Context, host string) ([]string, error)
+
+	once  sync.Once
+	addrs []string
+}
+
+// copyBlob copies the blob of layer to path from a peer, and reports whether it is copied.
+// The failures are logged, so the blob is pulled from the registry instead.
+func (p *Peers) copyBlob(ctx context.Context, path string, layer registry.Layer) bool {
+	logger := log.FromContext(ctx)
+	for _, addr := range p.resolve(ctx) {
+		if err := p.copyBlobFrom(ctx, addr, path, layer); err != nil {
+			logger.V(1).Info("unable to copy the blob from the peer", "peer", addr, "digest", layer.Digest, "error", err.Error())
+			continue
+		}
+		logger.Info("copied the blob from the peer", "peer", addr, "digest", layer.Digest)
+		return true
+	}
+	return false
+}
+
+func (p *Peers) copyBlobFrom(ctx context.Context, addr, path string, layer registry.Layer) error {
+	port := p.Port
+	if port == 0 {
+		port = SeedPort
+	}
+	u := fmt.Sprintf("http://%s/blobs/%s", net.JoinHostPort(addr, strconv.Itoa(port)), layer.Digest)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	httpClient := p.Client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return &registry.StatusError{URL: u, StatusCode: res.StatusCode}
+	}
+	return WriteBlob(path, layer.Digest, res.Body)
+}
+
+func (p *Peers) resolve(ctx context.Context) []string {
+	p.once.Do(func() {
+		resolver := p.Resolver
+		if resolver == nil {
+			resolver = net.DefaultResolver.LookupHost
+		}
+		addrs, err := resolver(ctx, p.Host)
+		if err != nil {
+			// No ready pod is published while the first pod of the Model starts.
+			log.FromContext(ctx).V(1).Info("no peer is resolved", "host", p.Host, "error", err.Error())
+			return
+		}
+		p.addrs = addrs
+	})
+	return p.addrs
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+// newSeeder serves blobs by their digests as a peer, and returns the peers resolved to it.
+func newSeeder(t *testing.T, blobs map[string]string) *Peers {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "blobs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// The blobs are written as is, so a peer may serve a blob which does not match its digest.
+	for digest, blob := range blobs {
+		path, err := BlobPath(dir, digest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(blob), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := httptest.NewServer(NewSeedHandler(dir))
+	t.Cleanup(server.Close)
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return &Peers{
+		Host: "llama3-peers",
+		Port: p,
+		Resolver: func(context.Context, string) ([]string, error) {
+			return []string{host}, nil
+		},
+	}
+}
+
+func TestSeed(t *testing.T) {
+	t.Run("Should copy the blobs from the peer and pull the missing blobs from the registry", func(t *testing.T) {
+		g := NewWithT(t)
+		reg := newFakeRegistry(t, "config", "weights")
+		delete(reg.serve, digestOf("weights"))
+		peers := newSeeder(t, map[string]string{digestOf("weights"): "weights"})
+		dir := t.TempDir()
+		p := &Puller{Registry: reg.client(), Dir: dir, Peers: peers}
+		g.Expect(p.Pull(context.Background(), "llama3:8b")).To(Succeed())
+		for _, blob := range []string{"config", "weights"} {
+			path, err := BlobPath(dir, digestOf(blob))
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(os.ReadFile(path)).To(Equal([]byte(blob)))
+		}
+	})
+
+	t.Run("Should pull the blob from the registry if the peer serves a tampered blob", func(t *testing.T) {
+		g := NewWithT(t)
+		reg := newFakeRegistry(t, "config", "weights")
+		peers := newSeeder(t, map[string]string{digestOf("weights"): "tampered"})
+		dir := t.TempDir()
+		p := &Puller{Registry: reg.client(), Dir: dir, Peers: peers}
+		g.Expect(p.Pull(context.Background(), "llama3:8b")).To(Succeed())
+		path, _ := BlobPath(dir, digestOf("weights"))
+		g.Expect(os.ReadFile(path)).To(Equal([]byte("weights")))
+	})
+
+	t.Run("Should pull from the registry if no peer is resolved", func(t *testing.T) {
+		g := NewWithT(t)
+		reg := newFakeRegistry(t, "config", "weights")
+		peers := &Peers{Host: "llama3-peers", Resolver: func(context.Context, string) ([]string, error) {
+			return nil, errors.New("no such host")
+		}}
+		p := &Puller{Registry: reg.client(), Dir: t.TempDir(), Peers: peers}
+		g.Expect(p.Pull(context.Background(), "llama3:8b")).To(Succeed())
+	})
+
+	t.Run("Should reject the invalid digest", func(t *testing.T) {
+		g := NewWithT(t)
+		server := httptest.NewServer(NewSeedHandler(t.TempDir()))
+		t.Cleanup(server.Close)
+		res, err := http.Get(server.URL + "/blobs/sha256:..%2F..%2Fetc")
+		g.Expect(err).NotTo(HaveOccurred())
+		_ = res.Body.Close()
+		g.Expect(res.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+}
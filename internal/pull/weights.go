@@ -0,0 +1,88 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File is a file of the weights of a model, e.g. a GGUF file, fetched over HTTP(S).
+type File struct {
+	// Name is the name of the file in the directory it is fetched into.
+	Name string
+	URL  string
+	// SHA256 is the hex encoded SHA-256 checksum of the file.
+	SHA256 string
+}
+
+// Fetcher fetches the files of the weights of the models.
+type Fetcher struct {
+	Client *http.Client
+	// Token is sent as the bearer token if it is not empty.
+	Token string
+}
+
+// Fetch fetches files into dir. A file is stored once it matches its checksum, and is not fetched again if it is present.
+func (f *Fetcher) Fetch(ctx context.Context, dir string, files []File) error {
+	for _, file := range files {
+		if file.Name == "" || file.Name != filepath.Base(file.Name) || strings.HasPrefix(file.Name, ".") {
+			return fmt.Errorf("invalid file name %q", file.Name)
+		}
+		path := filepath.Join(dir, file.Name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := f.fetch(ctx, path, file); err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", file.URL, err)
+		}
+	}
+	return nil
+}
+
+func (f *Fetcher) fetch(ctx context.Context, path string, file File) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, file.URL, nil)
+	if err != nil {
+		return err
+	}
+	if f.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+f.Token)
+	}
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(resp.Status)
+	}
+	return WriteBlob(path, "sha256:"+strings.ToLower(file.SHA256), resp.Body)
+}
+
+// WriteModelfile writes the Modelfile the model is created from into dir.
+func WriteModelfile(dir, modelfile string) error {
+	return writeFile(filepath.Join(dir, "Modelfile"), []byte(modelfile))
+}
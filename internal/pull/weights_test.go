@@ -0,0 +1,80 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFetch(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		if req.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(strings.TrimPrefix(req.URL.Path, "/")))
+	}))
+	t.Cleanup(server.Close)
+	file := File{Name: "model.gguf", URL: server.URL + "/weights", SHA256: strings.TrimPrefix(digestOf("weights"), "sha256:")}
+
+	t.Run("Should fetch the files with the bearer token", func(t *testing.T) {
+		g := NewWithT(t)
+		dir := t.TempDir()
+		f := &Fetcher{Token: "secret"}
+		g.Expect(f.Fetch(context.Background(), dir, []File{file})).To(Succeed())
+		g.Expect(os.ReadFile(filepath.Join(dir, "model.gguf"))).To(Equal([]byte("weights")))
+
+		// The present file is not fetched again.
+		n := requests.Load()
+		g.Expect(f.Fetch(context.Background(), dir, []File{file})).To(Succeed())
+		g.Expect(requests.Load()).To(Equal(n))
+	})
+
+	t.Run("Should not store the file which does not match the checksum", func(t *testing.T) {
+		g := NewWithT(t)
+		dir := t.TempDir()
+		tampered := file
+		tampered.URL = server.URL + "/tampered"
+		err := (&Fetcher{Token: "secret"}).Fetch(context.Background(), dir, []File{tampered})
+		g.Expect(err).To(MatchError(ContainSubstring("digest mismatch")))
+		g.Expect(filepath.Join(dir, "model.gguf")).NotTo(BeAnExistingFile())
+	})
+
+	t.Run("Should fail without the bearer token", func(t *testing.T) {
+		g := NewWithT(t)
+		err := (&Fetcher{}).Fetch(context.Background(), t.TempDir(), []File{file})
+		g.Expect(err).To(MatchError(ContainSubstring("401 Unauthorized")))
+	})
+
+	t.Run("Should reject the file name outside the directory", func(t *testing.T) {
+		g := NewWithT(t)
+		escaping := file
+		escaping.Name = "../model.gguf"
+		g.Expect((&Fetcher{}).Fetch(context.Background(), t.TempDir(), []File{escaping})).To(MatchError(ContainSubstring("invalid file name")))
+	})
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package quota evaluates the ModelQuotas of the namespaces.
+package quota
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+)
+
+// Sizes holds the known sizes of the models by the normalized name.
+type Sizes map[string]resource.Quantity
+
+// KnownSizes returns the sizes of the models pulled by models.
+func KnownSizes(models []ollamav1alpha1.Model) Sizes {
+	sizes := Sizes{}
+	for _, model := range models {
+		for _, image := range model.Status.Images {
+			sizes[ollama.NormalizeName(image.Name)] = image.Size
+		}
+	}
+	return sizes
+}
+
+// Usage returns the usage of images. The images whose size is not known are counted as zero.
+func (s Sizes) Usage(images []string) ollamav1alpha1.ModelQuotaUsage {
+	usage := ollamav1alpha1.ModelQuotaUsage{Models: int32(len(images))}
+	for _, image := range images {
+		if size, ok := s[ollama.NormalizeName(image)]; ok {
+			usage.Size.Add(size)
+		}
+	}
+	return usage
+}
+
+// Exceeds returns the limits of hard exceeded by adding add to used, or an empty string.
+func Exceeds(hard ollamav1alpha1.ModelQuotaLimits, used, add ollamav1alpha1.ModelQuotaUsage) string {
+	var exceeded []string
+	if hard.Models != nil && used.Models+add.Models > *hard.Models {
+		exceeded = append(exceeded, fmt.Sprintf("models: requested %d, used %d, limited %d", add.Models, used.Models, *hard.Models))
+	}
+	if hard.Size != nil {
+		total := used.Size.DeepCopy()
+		total.Add(add.Size)
+		if total.Cmp(*hard.Size) > 0 {
+			exceeded = append(exceeded, fmt.Sprintf("size: requested %s, used %s, limited %s", add.Size.String(), used.Size.String(), hard.Size.String()))
+		}
+	}
+	return strings.Join(exceeded, ", ")
+}
+
+// Result is the result of evaluating the ModelQuotas of a namespace.
+type Result struct {
+	// Used is the usage of the Models within the quotas by the name of the ModelQuota.
+	Used map[string]ollamav1alpha1.ModelQuotaUsage
+	// Exceeded is the message of the quotas exceeded by the name of the Model.
+	Exceeded map[string]string
+}
+
+// Evaluate admits the Models in the order of creation as long as they are within all quotas.
+// The Models exceeding a quota are not counted, so they do not prevent the later Models within the quotas.
+func Evaluate(quotas []ollamav1alpha1.ModelQuota, models []ollamav1alpha1.Model, sizes Sizes) Result {
+	result := Result{
+		Used:     make(map[string]ollamav1alpha1.ModelQuotaUsage, len(quotas)),
+		Exceeded: map[string]string{},
+	}
+	for _, q := range quotas {
+		result.Used[q.Name] = ollamav1alpha1.ModelQuotaUsage{}
+	}
+	models = slices.Clone(models)
+	slices.SortStableFunc(models, func(a, b ollamav1alpha1.Model) int {
+		if c := a.CreationTimestamp.Compare(b.CreationTimestamp.Time); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	for _, model := range models {
+		if !model.DeletionTimestamp.IsZero() {
+			continue
+		}
+		add := sizes.Usage(model.Spec.Images)
+		var exceeded []string
+		for _, q := range quotas {
+			if msg := Exceeds(q.Spec.Hard, result.Used[q.Name], add); msg != "" {
+				exceeded = append(exceeded, fmt.Sprintf("exceeded ModelQuota %s: %s", q.Name, msg))
+			}
+		}
+		if len(exceeded) > 0 {
+			result.Exceeded[model.Name] = strings.Join(exceeded, "; ")
+			continue
+		}
+		for _, q := range quotas {
+			used := result.Used[q.Name]
+			used.Models += add.Models
+			used.Size.Add(add.Size)
+			result.Used[q.Name] = used
+		}
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func newModel(name string, created time.Time, images ...string) ollamav1alpha1.Model {
+	return ollamav1alpha1.Model{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+		Spec:       ollamav1alpha1.ModelSpec{Images: images},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Now()
+	sizes := Sizes{
+		"llama3:latest":  resource.MustParse("5Gi"),
+		"llama3:70b":     resource.MustParse("40Gi"),
+		"mistral:latest": resource.MustParse("4Gi"),
+	}
+
+	t.Run("Should admit the Models in the order of creation", func(t *testing.T) {
+		g := NewWithT(t)
+		quotas := []ollamav1alpha1.ModelQuota{{
+			ObjectMeta: metav1.ObjectMeta{Name: "models"},
+			Spec:       ollamav1alpha1.ModelQuotaSpec{Hard: ollamav1alpha1.ModelQuotaLimits{Models: ptr.To[int32](2)}},
+		}}
+		result := Evaluate(quotas, []ollamav1alpha1.Model{
+			newModel("third", now, "phi3"),
+			newModel("first", now.Add(-time.Hour), "llama3"),
+			newModel("second", now.Add(-time.Minute), "mistral", "phi3"),
+		}, sizes)
+		g.Expect(result.Exceeded).To(HaveKey("second"))
+		g.Expect(result.Exceeded).NotTo(HaveKey("first"))
+		g.Expect(result.Exceeded).NotTo(HaveKey("third"))
+		g.Expect(result.Used["models"].Models).To(Equal(int32(2)))
+	})
+
+	t.Run("Should limit the total size of the known models", func(t *testing.T) {
+		g := NewWithT(t)
+		quotas := []ollamav1alpha1.ModelQuota{{
+			ObjectMeta: metav1.ObjectMeta{Name: "size"},
+			Spec:       ollamav1alpha1.ModelQuotaSpec{Hard: ollamav1alpha1.ModelQuotaLimits{Size: ptr.To(resource.MustParse("10Gi"))}},
+		}}
+		result := Evaluate(quotas, []ollamav1alpha1.Model{
+			newModel("small", now.Add(-time.Hour), "llama3", "registry.ollama.ai/library/mistral:latest"),
+			newModel("large", now.Add(-time.Minute), "llama3:70b"),
+			newModel("unknown", now, "phi3"),
+		}, sizes)
+		g.Expect(result.Exceeded).To(HaveKeyWithValue("large", "exceeded ModelQuota size: size: requested 40Gi, used 9Gi, limited 10Gi"))
+		g.Expect(result.Exceeded).NotTo(HaveKey("unknown"))
+		used := result.Used["size"]
+		g.Expect(used.Models).To(Equal(int32(3)))
+		g.Expect(used.Size.String()).To(Equal("9Gi"))
+	})
+
+	t.Run("Should not count the Models being deleted", func(t *testing.T) {
+		g := NewWithT(t)
+		quotas := []ollamav1alpha1.ModelQuota{{
+			ObjectMeta: metav1.ObjectMeta{Name: "models"},
+			Spec:       ollamav1alpha1.ModelQuotaSpec{Hard: ollamav1alpha1.ModelQuotaLimits{Models: ptr.To[int32](1)}},
+		}}
+		deleting := newModel("deleting", now.Add(-time.Hour), "llama3")
+		deleting.DeletionTimestamp = ptr.To(metav1.NewTime(now))
+		result := Evaluate(quotas, []ollamav1alpha1.Model{deleting, newModel("new", now, "mistral")}, sizes)
+		g.Expect(result.Exceeded).To(BeEmpty())
+	})
+}
+
+func TestKnownSizes(t *testing.T) {
+	g := NewWithT(t)
+	model := newModel("llama", time.Now(), "llama3")
+	model.Status.Images = []ollamav1alpha1.ModelImageStatus{{Name: "llama3", Size: resource.MustParse("5Gi")}}
+	sizes := KnownSizes([]ollamav1alpha1.Model{model})
+	g.Expect(sizes).To(HaveKey("llama3:latest"))
+	usage := sizes.Usage([]string{"registry.ollama.ai/library/llama3", "phi3"})
+	g.Expect(usage.Models).To(Equal(int32(2)))
+	g.Expect(usage.Size.String()).To(Equal("5Gi"))
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry fetches the models from the registries which serve them over the OCI distribution API,
+// like the Ollama registry does.
+package registry
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sivchari/ollama-operator/internal/ollama"
+)
+
+// manifestMediaType is the media type of the manifests of the models.
+const manifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+
+// Config is the configuration of the registries.
+type Config struct {
+	// Mirrors maps the registry hosts of the models to the hosts they are pulled from instead.
+	Mirrors map[string]string
+	// Hosts are the configurations of the hosts the models are pulled from, after the mirrors are applied.
+	Hosts map[string]Host
+}
+
+// Host is the configuration of the access to a registry host.
+type Host struct {
+	// Insecure uses plain HTTP and skips the verification of the TLS certificate.
+	Insecure bool
+	// Username and Password are the basic credentials, which are also exchanged for a bearer token on a challenge.
+	Username string
+	Password string
+	// Token is a bearer token.
+	Token string
+}
+
+// Manifest is the manifest of a model.
+type Manifest struct {
+	SchemaVersion int     `json:"schemaVersion"`
+	MediaType     string  `json:"mediaType"`
+	Config        Layer   `json:"config"`
+	Layers        []Layer `json:"layers"`
+}
+
+// Layer is a blob referenced by a manifest.
+type Layer struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// Blobs returns the config and the layers of the manifest.
+func (m *Manifest) Blobs() []Layer {
+	return append([]Layer{m.Config}, m.Layers...)
+}
+
+// Client fetches the manifests and the blobs of the models.
+type Client struct {
+	config   Config
+	secure   *http.Client
+	insecure *http.Client
+
+	mu sync.Mutex
+	// tokens are the bearer tokens obtained on the challenges by the host.
+	tokens map[string]string
+}
+
+// NewClient returns a Client for the registries configured by config.
+func NewClient(config Config) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	insecureTransport := transport.Clone()
+	insecureTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // opted in by the registry configuration
+	return &Client{
+		config:   config,
+		secure:   &http.Client{Transport: transport},
+		insecure: &http.Client{Transport: insecureTransport},
+		tokens:   map[string]string{},
+	}
+}
+
+// Manifest fetches the manifest of the model. The raw manifest is returned as well, so it is stored as served.
+func (c *Client) Manifest(ctx context.Context, name ollama.Name) (*Manifest, []byte, error) {
+	res, err := c.get(ctx, name, "manifests/"+name.Tag, manifestMediaType)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = res.Body.Close() }()
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest of %s: %w", name, err)
+	}
+	return &manifest, raw, nil
+}
+
+// Blob opens the blob of the model with digest.
+func (c *Client) Blob(ctx context.Context, name ollama.Name, digest string) (io.ReadCloser, error) {
+	res, err := c.get(ctx, name, "blobs/"+digest, "")
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// Host returns the host the model is pulled from.
+func (c *Client) Host(name ollama.Name) string {
+	if mirror, ok := c.config.Mirrors[name.Host]; ok {
+		return mirror
+	}
+	return name.Host
+}
+
+func (c *Client) get(ctx context.Context, name ollama.Name, path, accept string) (*http.Response, error) {
+	host := c.Host(name)
+	config := c.config.Hosts[host]
+	scheme, httpClient := "https", c.secure
+	if config.Insecure {
+		scheme, httpClient = "http", c.insecure
+	}
+	u := fmt.Sprintf("%s://%s/v2/%s/%s", scheme, host, name.Repository(), path)
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		c.authenticate(req, host, config)
+		res, err := httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode == http.StatusOK {
+			return res, nil
+		}
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		if res.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			if err := c.authorize(ctx, httpClient, host, config, res.Header.Get("WWW-Authenticate")); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return nil, &StatusError{URL: u, StatusCode: res.StatusCode}
+	}
+}
+
+// StatusError is the error of an unexpected response of a registry.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("GET %s: %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// IsNotFound reports whether err is a not found response of a registry.
+func IsNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+func (c *Client) authenticate(req *http.Request, host string, config Host) {
+	c.mu.Lock()
+	token := c.tokens[host]
+	c.mu.Unlock()
+	switch {
+	case token != "":
+		req.Header.Set("Authorization", "Bearer "+token)
+	case config.Token != "":
+		req.Header.Set("Authorization", "Bearer "+config.Token)
+	case config.Username != "":
+		req.SetBasicAuth(config.Username, config.Password)
+	}
+}
+
+// authorize obtains a bearer token from the realm of the challenge with the basic credentials of the host.
+func (c *Client) authorize(ctx context.Context, httpClient *http.Client, host string, config Host, challenge string) error {
+	scheme, params := parseChallenge(challenge)
+	if !strings.EqualFold(scheme, "Bearer") || params["realm"] == "" {
+		return fmt.Errorf("unauthorized by %s", host)
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil {
+		return err
+	}
+	q := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			q.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if config.Username != "" {
+		req.SetBasicAuth(config.Username, config.Password)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return &StatusError{URL: realm.String(), StatusCode: res.StatusCode}
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return err
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	if token.Token == "" {
+		return fmt.Errorf("no token is issued by %s", realm.Host)
+	}
+	c.mu.Lock()
+	c.tokens[host] = token.Token
+	c.mu.Unlock()
+	return nil
+}
+
+// parseChallenge parses the WWW-Authenticate header, e.g. `Bearer realm="https://auth.example.com/token",service="registry"`.
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimSpace(rest), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+			rest = strings.TrimPrefix(rest, ",")
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return scheme, params
+}
+
+// LoadCredentials reads the credentials of host from dir, in which the keys of the Secret are mounted,
+// that is the files username and password, or token.
+func LoadCredentials(dir string, host *Host) error {
+	for file, value := range map[string]*string{"username": &host.Username, "password": &host.Password, "token": &host.Token} {
+		b, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+		*value = strings.TrimSpace(string(b))
+	}
+	if host.Username == "" && host.Token == "" {
+		return fmt.Errorf("no username or token is found in %s", dir)
+	}
+	return nil
+}
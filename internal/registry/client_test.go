@@ -0,0 +1,126 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/sivchari/ollama-operator/internal/ollama"
+)
+
+// newTokenRegistry returns a registry which requires a bearer token issued for the basic credentials user:pass.
+func newTokenRegistry(t *testing.T) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("scope") != "repository:library/llama3:pull" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = io.WriteString(w, `{"token":"secret-token"}`)
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+srv.URL+`/token",service="registry",scope="repository:library/llama3:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/library/llama3/manifests/8b":
+			_, _ = io.WriteString(w, `{"schemaVersion":2,"config":{"digest":"sha256:c","size":1},"layers":[{"digest":"sha256:l","size":2}]}`)
+		case "/v2/library/llama3/blobs/sha256:l":
+			_, _ = io.WriteString(w, "ab")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient(t *testing.T) {
+	srv := newTokenRegistry(t)
+	host := strings.TrimPrefix(srv.URL, "http://")
+	name := ollama.Name{Host: ollama.DefaultRegistry, Namespace: "library", Model: "llama3", Tag: "8b"}
+
+	t.Run("Should pull from the mirror with the token issued for the credentials", func(t *testing.T) {
+		g := NewWithT(t)
+		c := NewClient(Config{
+			Mirrors: map[string]string{ollama.DefaultRegistry: host},
+			Hosts:   map[string]Host{host: {Insecure: true, Username: "user", Password: "pass"}},
+		})
+		g.Expect(c.Host(name)).To(Equal(host))
+		manifest, raw, err := c.Manifest(context.Background(), name)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(raw).To(ContainSubstring("schemaVersion"))
+		g.Expect(manifest.Blobs()).To(Equal([]Layer{{Digest: "sha256:c", Size: 1}, {Digest: "sha256:l", Size: 2}}))
+
+		body, err := c.Blob(context.Background(), name, "sha256:l")
+		g.Expect(err).NotTo(HaveOccurred())
+		b, err := io.ReadAll(body)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(body.Close()).To(Succeed())
+		g.Expect(string(b)).To(Equal("ab"))
+
+		_, _, err = c.Manifest(context.Background(), ollama.Name{Host: ollama.DefaultRegistry, Namespace: "library", Model: "llama3", Tag: "70b"})
+		g.Expect(IsNotFound(err)).To(BeTrue())
+	})
+
+	t.Run("Should fail without the credentials", func(t *testing.T) {
+		g := NewWithT(t)
+		c := NewClient(Config{
+			Mirrors: map[string]string{ollama.DefaultRegistry: host},
+			Hosts:   map[string]Host{host: {Insecure: true}},
+		})
+		_, _, err := c.Manifest(context.Background(), name)
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("Should send the static token", func(t *testing.T) {
+		g := NewWithT(t)
+		c := NewClient(Config{
+			Mirrors: map[string]string{ollama.DefaultRegistry: host},
+			Hosts:   map[string]Host{host: {Insecure: true, Token: "secret-token"}},
+		})
+		_, _, err := c.Manifest(context.Background(), name)
+		g.Expect(err).NotTo(HaveOccurred())
+	})
+}
+
+func TestParseChallenge(t *testing.T) {
+	g := NewWithT(t)
+	scheme, params := parseChallenge(`Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:a/b:pull,push"`)
+	g.Expect(scheme).To(Equal("Bearer"))
+	g.Expect(params).To(Equal(map[string]string{
+		"realm":   "https://auth.example.com/token",
+		"service": "registry.example.com",
+		"scope":   "repository:a/b:pull,push",
+	}))
+}
@@ -0,0 +1,345 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package s3 implements the client of the S3-compatible object storages the models are backed up to,
+// which signs the requests with the AWS Signature Version 4 and addresses the buckets in the path style.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRegion is the region the requests are signed for if no region is configured,
+	// which most of the S3-compatible storages accept.
+	DefaultRegion = "us-east-1"
+
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// emptyPayloadHash is the SHA-256 hash of the empty payload.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	// defaultPartSize is the size of the parts the objects are uploaded in. The larger objects than it are
+	// uploaded by the multipart upload, since S3 rejects a single PUT of more than 5 GiB.
+	defaultPartSize = 64 << 20
+	// maxParts is the maximum number of the parts of a multipart upload.
+	maxParts = 10000
+)
+
+// Config is the configuration of the bucket.
+type Config struct {
+	// Endpoint is the URL of the storage, e.g. "http://minio.minio:9000".
+	Endpoint string
+	Bucket   string
+	Region   string
+	// Insecure skips the verification of the TLS certificate of the storage.
+	Insecure        bool
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// Client accesses the objects in a bucket.
+type Client struct {
+	config   Config
+	endpoint *url.URL
+	http     *http.Client
+	now      func() time.Time
+	partSize int64
+}
+
+// NewClient returns the client of the bucket in config.
+func NewClient(config Config) (*Client, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("invalid endpoint %q, must be an HTTP(S) URL", config.Endpoint)
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("bucket is required")
+	}
+	if config.Region == "" {
+		config.Region = DefaultRegion
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.Insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // configured by the user
+	}
+	return &Client{
+		config:   config,
+		endpoint: endpoint,
+		http:     &http.Client{Transport: transport},
+		now:      time.Now,
+		partSize: defaultPartSize,
+	}, nil
+}
+
+// Get returns the content of the object at key. The error matches fs.ErrNotExist if the object does not exist.
+func (c *Client) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Head returns the size of the object at key. The error matches fs.ErrNotExist if the object does not exist.
+func (c *Client) Head(ctx context.Context, key string) (int64, error) {
+	resp, err := c.do(ctx, http.MethodHead, key, nil, nil, 0)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.ContentLength, nil
+}
+
+// Put stores the object of size read from body at key. The object larger than the part size is uploaded
+// in parts, and the upload is aborted if it fails, so the storage does not keep the uploaded parts.
+func (c *Client) Put(ctx context.Context, key string, body io.Reader, size int64) error {
+	if size > c.partSize {
+		return c.putMultipart(ctx, key, body, size)
+	}
+	resp, err := c.do(ctx, http.MethodPut, key, nil, body, size)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	return nil
+}
+
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+type completedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// completeMultipartUploadResult is the result of the completion of a multipart upload. S3 reports the failure
+// of the completion in the body of a successful response, whose root element is Error.
+type completeMultipartUploadResult struct {
+	XMLName xml.Name
+	Code    string
+	Message string
+}
+
+func (c *Client) putMultipart(ctx context.Context, key string, body io.Reader, size int64) (err error) {
+	resp, err := c.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, 0)
+	if err != nil {
+		return err
+	}
+	var initiated initiateMultipartUploadResult
+	err = xml.NewDecoder(resp.Body).Decode(&initiated)
+	_ = resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("invalid response to the multipart upload of %s: %w", key, err)
+	}
+	uploadID := url.Values{"uploadId": {initiated.UploadID}}
+	defer func() {
+		if err != nil {
+			// The upload is aborted even if ctx is canceled, since the parts are charged until then.
+			if resp, abortErr := c.do(context.WithoutCancel(ctx), http.MethodDelete, key, uploadID, nil, 0); abortErr == nil {
+				_ = resp.Body.Close()
+			}
+		}
+	}()
+
+	partSize := max(c.partSize, (size+maxParts-1)/maxParts)
+	var complete completeMultipartUpload
+	for number, offset := 1, int64(0); offset < size; number, offset = number+1, offset+partSize {
+		n := min(partSize, size-offset)
+		query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {initiated.UploadID}}
+		resp, err := c.do(ctx, http.MethodPut, key, query, io.LimitReader(body, n), n)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		complete.Parts = append(complete.Parts, completedPart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+	}
+
+	raw, err := xml.Marshal(complete)
+	if err != nil {
+		return err
+	}
+	resp, err = c.do(ctx, http.MethodPost, key, uploadID, bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		return err
+	}
+	var result completeMultipartUploadResult
+	err = xml.NewDecoder(resp.Body).Decode(&result)
+	_ = resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("invalid response to the multipart upload of %s: %w", key, err)
+	}
+	if result.XMLName.Local == "Error" {
+		return fmt.Errorf("failed to complete the multipart upload of %s: %s: %s", key, result.Code, result.Message)
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, key string, query url.Values, body io.Reader, size int64) (*http.Response, error) {
+	u := *c.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + c.config.Bucket + "/" + key
+	u.RawPath = strings.TrimSuffix(c.endpoint.EscapedPath(), "/") + "/" + uriEncode(c.config.Bucket) + "/" + uriEncode(key)
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		req.ContentLength = size
+		// The payload is streamed, which is allowed without its hash.
+		payloadHash = unsignedPayload
+	}
+	sign(req, payloadHash, c.config.AccessKeyID, c.config.SecretAccessKey, c.config.Region, c.now())
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		_ = resp.Body.Close()
+		return nil, &StatusError{Method: method, Key: key, StatusCode: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+// StatusError is the error of the request the storage did not succeed.
+type StatusError struct {
+	Method     string
+	Key        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Key, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is reports whether the object does not exist.
+func (e *StatusError) Is(target error) bool {
+	return target == fs.ErrNotExist && e.StatusCode == http.StatusNotFound
+}
+
+// sign signs req with the AWS Signature Version 4. The host and all the headers of req are signed.
+func sign(req *http.Request, payloadHash, accessKeyID, secretAccessKey, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+	key := []byte("AWS4" + secretAccessKey)
+	for _, s := range []string{date, region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, s)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, s string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
+
+// uriEncode encodes path as the canonical URI of the AWS Signature Version 4, which keeps only the unreserved
+// characters and the slashes.
+func uriEncode(path string) string {
+	var b strings.Builder
+	for _, c := range []byte(path) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', strings.IndexByte("-_.~/", c) >= 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// LoadCredentials reads the keys accessKeyID and secretAccessKey of the Secret mounted in dir into config.
+func LoadCredentials(dir string, config *Config) error {
+	for name, field := range map[string]*string{
+		"accessKeyID":     &config.AccessKeyID,
+		"secretAccessKey": &config.SecretAccessKey,
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		*field = strings.TrimSpace(string(b))
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+	"github.com/sivchari/ollama-operator/internal/catalog"
+	"github.com/sivchari/ollama-operator/internal/ollama"
+	"github.com/sivchari/ollama-operator/internal/quota"
+)
+
+// log is for logging in this package.
+var modellog = logf.Log.WithName("model-resource")
+
+// SetupModelWebhookWithManager registers the webhook for Model in the manager.
+func SetupModelWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&ollamav1alpha1.Model{}).
+		WithValidator(&ModelCustomValidator{Client: mgr.GetClient()}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-ollama-sivchari-io-v1alpha1-model,mutating=false,failurePolicy=fail,sideEffects=None,groups=ollama.sivchari.io,resources=models,verbs=create;update,versions=v1alpha1,name=vmodel-v1alpha1.kb.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups=ollama.sivchari.io,resources=modelcatalogs;modelquotas;models,verbs=get;list;watch
+
+// ModelCustomValidator validates the Models on admission.
+type ModelCustomValidator struct {
+	client.Client
+}
+
+var _ webhook.CustomValidator = &ModelCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Model.
+func (v *ModelCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	model, ok := obj.(*ollamav1alpha1.Model)
+	if !ok {
+		return nil, fmt.Errorf("expected a Model object but got %T", obj)
+	}
+	modellog.V(1).Info("Validation for Model upon creation", "name", model.GetName())
+
+	if err := v.validateImages(ctx, model.Spec.Images); err != nil {
+		return nil, err
+	}
+	return nil, v.validateQuota(ctx, model)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Model.
+// Only the updates adding images are validated, so the Models created before a ModelCatalog
+// can still be updated, e.g. scaled by the autoscaler.
+func (v *ModelCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldModel, ok := oldObj.(*ollamav1alpha1.Model)
+	if !ok {
+		return nil, fmt.Errorf("expected a Model object for the oldObj but got %T", oldObj)
+	}
+	model, ok := newObj.(*ollamav1alpha1.Model)
+	if !ok {
+		return nil, fmt.Errorf("expected a Model object for the newObj but got %T", newObj)
+	}
+	modellog.V(1).Info("Validation for Model upon update", "name", model.GetName())
+
+	added := slices.DeleteFunc(slices.Clone(model.Spec.Images), func(image string) bool {
+		return slices.Contains(oldModel.Spec.Images, image)
+	})
+	if len(added) == 0 {
+		return nil, nil
+	}
+	if err := v.validateImages(ctx, added); err != nil {
+		return nil, err
+	}
+	return nil, v.validateQuota(ctx, model)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Model.
+func (v *ModelCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateImages rejects the invalid images and the images which are not approved by the ModelCatalogs.
+func (v *ModelCustomValidator) validateImages(ctx context.Context, images []string) error {
+	if len(images) == 0 {
+		return nil
+	}
+	for _, image := range images {
+		if _, err := ollama.ParseName(image); err != nil {
+			return err
+		}
+	}
+	catalogs := &ollamav1alpha1.ModelCatalogList{}
+	if err := v.List(ctx, catalogs); err != nil {
+		return err
+	}
+	if blocked := catalog.Blocked(catalogs.Items, images); len(blocked) > 0 {
+		return fmt.Errorf("images are not approved by any ModelCatalog: %s", strings.Join(blocked, ", "))
+	}
+	return nil
+}
+
+// validateQuota rejects the Model which exceeds the ModelQuotas of its namespace
+// in addition to the other Models within the quotas.
+func (v *ModelCustomValidator) validateQuota(ctx context.Context, model *ollamav1alpha1.Model) error {
+	quotas := &ollamav1alpha1.ModelQuotaList{}
+	if err := v.List(ctx, quotas, client.InNamespace(model.Namespace)); err != nil {
+		return err
+	}
+	if len(quotas.Items) == 0 {
+		return nil
+	}
+	models := &ollamav1alpha1.ModelList{}
+	if err := v.List(ctx, models); err != nil {
+		return err
+	}
+	var others []ollamav1alpha1.Model
+	for _, m := range models.Items {
+		if m.Namespace == model.Namespace && m.Name != model.Name {
+			others = append(others, m)
+		}
+	}
+	sizes := quota.KnownSizes(models.Items)
+	result := quota.Evaluate(quotas.Items, others, sizes)
+	add := sizes.Usage(model.Spec.Images)
+	for _, q := range quotas.Items {
+		if msg := quota.Exceeds(q.Spec.Hard, result.Used[q.Name], add); msg != "" {
+			return fmt.Errorf("exceeded ModelQuota %s: %s", q.Name, msg)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	ollamav1alpha1 "github.com/sivchari/ollama-operator/api/v1alpha1"
+)
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := ollamav1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func newModel(images ...string) *ollamav1alpha1.Model {
+	return &ollamav1alpha1.Model{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llama"},
+		Spec:       ollamav1alpha1.ModelSpec{Images: images},
+	}
+}
+
+func TestModelCustomValidator(t *testing.T) {
+	ctx := context.Background()
+	modelCatalog := &ollamav1alpha1.ModelCatalog{
+		ObjectMeta: metav1.ObjectMeta{Name: "approved"},
+		Spec: ollamav1alpha1.ModelCatalogSpec{
+			Entries: []ollamav1alpha1.ModelCatalogEntry{{Name: "llama3", Tags: []string{"8b"}}},
+		},
+	}
+	newValidator := func(objs ...client.Object) *ModelCustomValidator {
+		return &ModelCustomValidator{Client: fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(objs...).Build()}
+	}
+
+	t.Run("Should admit any images without the ModelCatalogs", func(t *testing.T) {
+		g := NewWithT(t)
+		_, err := newValidator().ValidateCreate(ctx, newModel("phi3"))
+		g.Expect(err).NotTo(HaveOccurred())
+	})
+
+	t.Run("Should admit the approved images", func(t *testing.T) {
+		g := NewWithT(t)
+		_, err := newValidator(modelCatalog).ValidateCreate(ctx, newModel("llama3:8b"))
+		g.Expect(err).NotTo(HaveOccurred())
+	})
+
+	t.Run("Should reject the images which are not approved", func(t *testing.T) {
+		g := NewWithT(t)
+		_, err := newValidator(modelCatalog).ValidateCreate(ctx, newModel("llama3:8b", "llama3:70b", "phi3"))
+		g.Expect(err).To(MatchError("images are not approved by any ModelCatalog: llama3:70b, phi3"))
+	})
+
+	t.Run("Should reject the images whose names escape the models directory", func(t *testing.T) {
+		g := NewWithT(t)
+		v := newValidator()
+		for _, image := range []string{"../../../etc/x", "llama3:../../x", "team/..", "llama3;reboot"} {
+			_, err := v.ValidateCreate(ctx, newModel("llama3", image))
+			g.Expect(err).To(MatchError(ContainSubstring("invalid model name")), image)
+			_, err = v.ValidateUpdate(ctx, newModel("llama3"), newModel("llama3", image))
+			g.Expect(err).To(MatchError(ContainSubstring("invalid model name")), image)
+		}
+	})
+
+	t.Run("Should only validate the images added by the update", func(t *testing.T) {
+		g := NewWithT(t)
+		v := newValidator(modelCatalog)
+		_, err := v.ValidateUpdate(ctx, newModel("phi3"), newModel("phi3", "llama3:8b"))
+		g.Expect(err).NotTo(HaveOccurred())
+		_, err = v.ValidateUpdate(ctx, newModel("phi3"), newModel("phi3", "llama3:70b"))
+		g.Expect(err).To(MatchError("images are not approved by any ModelCatalog: llama3:70b"))
+	})
+	t.Run("Should reject the Model exceeding the ModelQuota", func(t *testing.T) {
+		g := NewWithT(t)
+		modelQuota := &ollamav1alpha1.ModelQuota{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "models"},
+			Spec: ollamav1alpha1.ModelQuotaSpec{
+				Hard: ollamav1alpha1.ModelQuotaLimits{Models: ptr.To[int32](2)},
+			},
+		}
+		existing := newModel("llama3")
+		existing.Name = "existing"
+		v := newValidator(modelQuota, existing)
+		_, err := v.ValidateCreate(ctx, newModel("mistral"))
+		g.Expect(err).NotTo(HaveOccurred())
+		_, err = v.ValidateCreate(ctx, newModel("mistral", "phi3"))
+		g.Expect(err).To(MatchError("exceeded ModelQuota models: models: requested 2, used 1, limited 2"))
+		updated := existing.DeepCopy()
+		updated.Spec.Images = append(updated.Spec.Images, "mistral")
+		_, err = v.ValidateUpdate(ctx, existing, updated)
+		g.Expect(err).NotTo(HaveOccurred())
+		updated.Spec.Images = append(updated.Spec.Images, "phi3")
+		_, err = v.ValidateUpdate(ctx, existing, updated)
+		g.Expect(err).To(MatchError("exceeded ModelQuota models: models: requested 3, used 0, limited 2"))
+	})
+}